@@ -1,8 +1,17 @@
 package main
 
-import "gophKeeper/client/internal/app"
+import (
+	"gophKeeper/client/internal/app"
+	"gophKeeper/client/internal/cli"
+	"os"
+)
 
 func main() {
+	// a command runs non-interactively, the TUI is started otherwise
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
 	a := &app.App{}
 
 	a.Init()
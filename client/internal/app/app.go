@@ -6,12 +6,12 @@ package app
 import (
 	"context"
 	"fmt"
-	"github.com/go-redis/redis/v8"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"gophKeeper/client/internal/client"
 	"gophKeeper/client/internal/conf"
+	"gophKeeper/client/internal/session"
 	"gophKeeper/client/internal/tui"
-	"log"
+	"gophKeeper/client/internal/vault"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -28,8 +28,8 @@ type App struct {
 	// TUI
 	TUI *tui.TUI
 
-	// cache
-	redisClient *redis.Client
+	// local vault
+	vault *vault.Vault
 
 	exitCode int
 }
@@ -51,23 +51,25 @@ func (a *App) Init() {
 		errCheck(err, "NewGophKeeperClient")
 	}
 
-	// redis
+	// local vault
 	{
-		a.redisClient = redis.NewClient(&redis.Options{
-			Addr:     conf.Conf.RedisAddress,
-			Password: conf.Conf.RedisPassword,
-			DB:       conf.Conf.RedisDB,
-		})
-
-		err = a.redisClient.Set(context.Background(), "key", "value", 0).Err()
-		if err != nil {
-			log.Fatalf("Could not set cache: %v", err)
+		vaultFile := conf.Conf.VaultFile
+		if vaultFile == "" {
+			vaultFile = vault.DefaultPath()
 		}
+
+		a.vault, err = vault.Open(vaultFile)
+		errCheck(err, "vault.Open")
 	}
 
 	// TUI
 	{
-		a.TUI = tui.NewTUI(a.grpcClient, a.redisClient)
+		sessionFile := conf.Conf.TUISessionFile
+		if sessionFile == "" {
+			sessionFile = session.DefaultPath("tui")
+		}
+
+		a.TUI = tui.NewTUI(a.grpcClient, a.vault, sessionFile, conf.Conf.IdleTimeout)
 	}
 }
 
@@ -109,11 +111,10 @@ func (a *App) Listen() {
 
 // Exit terminates the application with the specified exit code.
 func (a *App) Exit() {
-
-	if err := a.redisClient.Close(); err != nil {
-		slog.Error("Error closing Redis client")
+	if err := a.vault.Close(); err != nil {
+		slog.Error("Error closing the vault", slog.String("error", err.Error()))
 	} else {
-		slog.Info("Redis client closed")
+		slog.Info("Vault closed")
 	}
 
 	slog.Info("Exit")
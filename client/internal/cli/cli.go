@@ -0,0 +1,337 @@
+// Package cli implements the non-interactive command line mode of the GophKeeper client,
+// so secrets can be read and written by scripts:
+//
+//	gophkeeper login [--username NAME] [--code CODE]
+//	gophkeeper get [--type TYPE] [--field FIELD | --json] [--out FILE] ID
+//	gophkeeper create --type TYPE --from-file FILE|- [--meta META] [--name NAME] [--tags A,B] [--folder ID]
+//	gophkeeper list [--type TYPE] [--json]
+//	gophkeeper rm ID...
+//	gophkeeper logout
+//
+// The login is kept in the session file in the configuration directory of the user, its token pair
+// sealed with the master key, which is checked against the local vault on login. The password and
+// the master password are read from the GOPHKEEPER_PASSWORD and GOPHKEEPER_MASTER_PASSWORD environment
+// variables, or prompted for on the terminal. Results are written to stdout, messages to stderr,
+// and the exit code tells the kind of failure, see the Exit constants.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gophKeeper/client/internal/client"
+	"gophKeeper/client/internal/conf"
+	"gophKeeper/client/internal/session"
+	"gophKeeper/client/internal/vault"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Exit codes of the commands.
+const (
+	ExitOK = 0
+	// ExitError is returned for failures not covered by the other codes.
+	ExitError = 1
+	// ExitUsage is returned for unknown commands, flags and arguments.
+	ExitUsage = 2
+	// ExitAuth is returned when the client is not logged in or the credentials are rejected.
+	ExitAuth = 3
+	// ExitNotFound is returned when the requested data item does not exist.
+	ExitNotFound = 4
+	// ExitUnavailable is returned when the server can not be reached.
+	ExitUnavailable = 5
+)
+
+const (
+	// PasswordEnv names the environment variable holding the login password.
+	PasswordEnv = "GOPHKEEPER_PASSWORD"
+	// MasterPasswordEnv names the environment variable holding the master password.
+	MasterPasswordEnv = "GOPHKEEPER_MASTER_PASSWORD"
+
+	// requestTimeout limits the time of a single request to the server.
+	requestTimeout = 15 * time.Second
+	// transferTimeout limits the time of streaming a binary item to or from the server.
+	transferTimeout = 10 * time.Minute
+)
+
+// errNotFound is returned when the requested data item does not exist.
+var errNotFound = errors.New("not found")
+
+// usageError is the error of an invalid command line.
+type usageError struct {
+	message string
+}
+
+// Error returns the description of the invalid command line.
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// newUsageError returns the error of an invalid command line.
+func newUsageError(format string, args ...any) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// command is a subcommand of the command line client.
+type command struct {
+	usage string
+	run   func(c *CLI, args []string) error
+}
+
+// commands lists the subcommands by their names.
+var commands = map[string]command{
+	"login":  {usage: "login [--username NAME] [--code CODE]", run: (*CLI).login},
+	"logout": {usage: "logout", run: (*CLI).logout},
+	"get":    {usage: "get [--type TYPE] [--field FIELD | --json] [--out FILE] ID", run: (*CLI).get},
+	"create": {usage: "create --type TYPE --from-file FILE|- [--meta META] [--name NAME] [--tags A,B] [--folder ID]", run: (*CLI).create},
+	"list":   {usage: "list [--type TYPE] [--json]", run: (*CLI).list},
+	"rm":     {usage: "rm ID...", run: (*CLI).rm},
+}
+
+// CLI runs a command of the command line client.
+type CLI struct {
+	client      *client.GophKeeperClient
+	sessionPath string
+	session     *session.Session
+	// resumedToken is the access token the session was resumed with.
+	resumedToken string
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// lines reads the answers to the prompts from stdin.
+	lines *bufio.Reader
+}
+
+// IsCommand tells whether the argument names a command, the client runs the TUI otherwise.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok || name == "help"
+}
+
+// Run runs the command given by the arguments and returns the exit code.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" {
+		printUsage(stderr)
+		return ExitUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		printUsage(stderr)
+		return ExitUsage
+	}
+
+	grpcClient, err := client.NewGophKeeperClient(
+		conf.Conf.EnableTLS,
+		conf.Conf.ServerAddress,
+		conf.Conf.CAFile,
+		conf.Conf.ClientCertFile,
+		conf.Conf.ClientKeyFile,
+	)
+	if err != nil {
+		fmt.Fprintln(stderr, client.ErrorMessage(err))
+		return ExitError
+	}
+
+	c := &CLI{
+		client:      grpcClient,
+		sessionPath: conf.Conf.SessionFile,
+		stdin:       stdin,
+		stdout:      stdout,
+		stderr:      stderr,
+		lines:       bufio.NewReader(stdin),
+	}
+	if c.sessionPath == "" {
+		c.sessionPath = session.DefaultPath("cli")
+	}
+
+	err = c.run(cmd, args[1:])
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %s\n", args[0], client.ErrorMessage(err))
+		var usageErr *usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(stderr, "usage: gophkeeper %s\n", cmd.usage)
+		}
+	}
+
+	return exitCode(err)
+}
+
+// run runs the command, keeping the token pair of the resumed session afterwards,
+// as the command may have refreshed it.
+func (c *CLI) run(cmd command, args []string) error {
+	err := cmd.run(c, args)
+
+	if saveErr := c.saveTokens(); saveErr != nil {
+		fmt.Fprintf(c.stderr, "failed to store the session: %v\n", saveErr)
+	}
+
+	return err
+}
+
+// resume loads the session of the logged in account and derives the master key. The commands
+// working with the data items resume the session once their arguments are checked.
+func (c *CLI) resume() error {
+	s, err := session.Load(c.sessionPath)
+	if err != nil {
+		if errors.Is(err, session.ErrNoSession) {
+			return fmt.Errorf("%w, run gophkeeper login first", err)
+		}
+		return err
+	}
+	if s.ServerAddress != conf.Conf.ServerAddress {
+		return fmt.Errorf("%w to %s, run gophkeeper login first", session.ErrNoSession, conf.Conf.ServerAddress)
+	}
+
+	masterPassword, err := c.readSecret(MasterPasswordEnv, "Master password: ")
+	if err != nil {
+		return err
+	}
+	if err = c.client.SetMasterKey(masterPassword, s.KdfSalt); err != nil {
+		return err
+	}
+
+	tokens, err := s.Tokens(c.client.Cipher())
+	if err != nil {
+		return err
+	}
+
+	c.session = s
+	c.resumedToken = tokens.AccessToken
+	c.client.ResumeSession(s.Username, tokens)
+
+	return nil
+}
+
+// saveTokens stores the current token pair of the session if it was refreshed.
+func (c *CLI) saveTokens() error {
+	if c.session == nil {
+		return nil
+	}
+
+	tokens := c.client.Tokens()
+	if tokens.AccessToken == "" || tokens.AccessToken == c.resumedToken {
+		return nil
+	}
+
+	if err := c.session.SetTokens(tokens, c.client.Cipher()); err != nil {
+		return err
+	}
+
+	return session.Save(c.sessionPath, c.session)
+}
+
+// readSecret returns the value of the environment variable, prompting for it on the terminal
+// if it is not set. The value is read from a line of stdin when stdin is not a terminal.
+func (c *CLI) readSecret(envName, prompt string) (string, error) {
+	if value, ok := os.LookupEnv(envName); ok {
+		return value, nil
+	}
+
+	if file, ok := c.stdin.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		fmt.Fprint(c.stderr, prompt)
+		value, err := term.ReadPassword(int(file.Fd()))
+		fmt.Fprintln(c.stderr)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", strings.TrimSuffix(prompt, ": "), err)
+		}
+		return string(value), nil
+	}
+
+	return c.readLine(prompt)
+}
+
+// readLine prompts for a value and reads it from a line of stdin.
+func (c *CLI) readLine(prompt string) (string, error) {
+	fmt.Fprint(c.stderr, prompt)
+
+	line, err := c.lines.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("read %s: %w", strings.TrimSuffix(prompt, ": "), err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// requestContext returns the context of a request to the server carrying the access token.
+func (c *CLI) requestContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	return c.client.CreateContextWithMetadata(timeout)
+}
+
+// parseArgs parses the flags of the command, which may follow its positional arguments,
+// and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, newUsageError("%v", err)
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// newFlagSet returns the flag set of the command, reporting the parse errors to the caller only.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// exitCode returns the exit code telling the kind of the error.
+func exitCode(err error) int {
+	var usageErr *usageError
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.Is(err, session.ErrNoSession), errors.Is(err, session.ErrWrongKey), errors.Is(err, vault.ErrWrongKey),
+		errors.Is(err, client.ErrWrongMasterKey), errors.Is(err, client.ErrNoRefreshToken):
+		return ExitAuth
+	case errors.Is(err, errNotFound):
+		return ExitNotFound
+	}
+
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return ExitAuth
+	case codes.NotFound:
+		return ExitNotFound
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return ExitUnavailable
+	default:
+		return ExitError
+	}
+}
+
+// printUsage lists the commands.
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  gophkeeper %s\n", commands[name].usage)
+	}
+	fmt.Fprintf(w, "\nThe passwords are read from %s and %s, or prompted for.\n", PasswordEnv, MasterPasswordEnv)
+}
@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gophKeeper/client/internal/client"
+	"gophKeeper/client/internal/conf"
+	"gophKeeper/client/internal/session"
+	"gophKeeper/client/internal/vault"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	conf.Conf.EnableTLS = false
+	conf.Conf.SessionFile = filepath.Join(t.TempDir(), "session.json")
+
+	tests := []struct {
+		name       string
+		args       []string
+		want       int
+		wantStderr string
+	}{
+		{
+			name:       "no command",
+			args:       nil,
+			want:       ExitUsage,
+			wantStderr: "usage:",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"show"},
+			want:       ExitUsage,
+			wantStderr: `unknown command "show"`,
+		},
+		{
+			name:       "unknown flag",
+			args:       []string{"list", "--color"},
+			want:       ExitUsage,
+			wantStderr: "usage: gophkeeper list",
+		},
+		{
+			name:       "not logged in",
+			args:       []string{"get", "1", "--field", "password"},
+			want:       ExitAuth,
+			wantStderr: "not logged in, run gophkeeper login first",
+		},
+		{
+			name:       "rm without IDs",
+			args:       []string{"rm"},
+			want:       ExitUsage,
+			wantStderr: "usage: gophkeeper rm",
+		},
+		{
+			name:       "logout without a session",
+			args:       []string{"logout"},
+			want:       ExitAuth,
+			wantStderr: "not logged in",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := Run(tt.args, strings.NewReader(""), &stdout, &stderr); got != tt.want {
+				t.Errorf("Run() = %v, want %v, stderr %q", got, tt.want, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("Run() stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("Run() stdout = %q, want nothing", stdout.String())
+			}
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantArgs  []string
+		wantField string
+		wantJSON  bool
+		wantErr   bool
+	}{
+		{
+			name:      "flags after the argument",
+			args:      []string{"42", "--field", "password"},
+			wantArgs:  []string{"42"},
+			wantField: "password",
+		},
+		{
+			name:      "flags around the arguments",
+			args:      []string{"--json", "1", "2", "-field=url"},
+			wantArgs:  []string{"1", "2"},
+			wantField: "url",
+			wantJSON:  true,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"1", "--verbose"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newFlagSet("get")
+			field := fs.String("field", "", "")
+			asJSON := fs.Bool("json", false, "")
+
+			got, err := parseArgs(fs, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if code := exitCode(err); code != ExitUsage {
+					t.Errorf("exitCode() of the parse error = %v, want %v", code, ExitUsage)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.wantArgs) || *field != tt.wantField || *asJSON != tt.wantJSON {
+				t.Errorf("parseArgs() = %v, field %q, json %v, want %v, field %q, json %v",
+					got, *field, *asJSON, tt.wantArgs, tt.wantField, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestItemField(t *testing.T) {
+	item := &pb.DataItem{
+		Id:   "1",
+		Type: "login_password",
+		Payload: &pb.DataItem_Credentials{Credentials: &pb.Credentials{
+			Login:    "alice",
+			Password: "secret",
+		}},
+		Metadata: &pb.DataItemMetadata{Name: "mail"},
+	}
+
+	tests := []struct {
+		field   string
+		want    string
+		wantErr bool
+	}{
+		{field: "password", want: "secret"},
+		{field: "login", want: "alice"},
+		{field: "name", want: "mail"},
+		{field: "cvv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			got, err := itemField(item, tt.field)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("itemField() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("itemField() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetPayload(t *testing.T) {
+	item := &pb.DataItem{Type: "login_password"}
+	if err := setPayload(item, []byte(`{"login": "alice", "password": "secret", "totp_secret": "JBSWY3DP"}`)); err != nil {
+		t.Fatalf("setPayload() error = %v", err)
+	}
+	if got := item.GetCredentials(); got.GetLogin() != "alice" || got.GetPassword() != "secret" || got.GetTotpSecret() != "JBSWY3DP" {
+		t.Errorf("setPayload() credentials = %v", got)
+	}
+
+	item = &pb.DataItem{Type: "text"}
+	if err := setPayload(item, []byte("line 1\nline 2\n")); err != nil {
+		t.Fatalf("setPayload() error = %v", err)
+	}
+	if got := item.GetText().GetContent(); got != "line 1\nline 2\n" {
+		t.Errorf("setPayload() text = %q", got)
+	}
+
+	item = &pb.DataItem{Type: "bank_card"}
+	if err := setPayload(item, []byte("4111 1111 1111 1111")); exitCode(err) != ExitUsage {
+		t.Errorf("setPayload() of a malformed card error = %v, want a usage error", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: ExitOK},
+		{name: "usage", err: newUsageError("expected a single ID"), want: ExitUsage},
+		{name: "no session", err: fmt.Errorf("%w, run gophkeeper login first", session.ErrNoSession), want: ExitAuth},
+		{name: "wrong master password", err: vault.ErrWrongKey, want: ExitAuth},
+		{name: "master password not opening the key check", err: client.ErrWrongMasterKey, want: ExitAuth},
+		{name: "unauthenticated", err: status.Error(codes.Unauthenticated, "token expired"), want: ExitAuth},
+		{name: "not found", err: fmt.Errorf("data item 1: %w", errNotFound), want: ExitNotFound},
+		{name: "not found on server", err: status.Error(codes.NotFound, "no item"), want: ExitNotFound},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "connection refused"), want: ExitUnavailable},
+		{name: "other", err: errors.New("decrypt data of item 1"), want: ExitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,579 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gophKeeper/client/internal/conf"
+	"gophKeeper/client/internal/session"
+	"gophKeeper/client/internal/vault"
+	"gophKeeper/pkg/payload"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// listPageSize is the number of summaries requested per page by list.
+const listPageSize = 100
+
+// typeAliases maps the type names accepted by the commands to the types stored on the server.
+var typeAliases = map[string]string{
+	"credentials":           payload.CredentialsType,
+	payload.CredentialsType: payload.CredentialsType,
+	"card":                  payload.BankCardType,
+	payload.BankCardType:    payload.BankCardType,
+	payload.TextType:        payload.TextType,
+	payload.BinaryType:      payload.BinaryType,
+}
+
+// login logs in with SRP, completing the two-factor challenge if the account has one,
+// and stores the session for the following commands sealed with the master key.
+// The master password is checked against the local vault first, so a mistyped one
+// is not sealed into the session.
+func (c *CLI) login(args []string) error {
+	fs := newFlagSet("login")
+	username := fs.String("username", "", "username, prompted for if not set")
+	code := fs.String("code", "", "two-factor code, prompted for if the account needs one")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return newUsageError("unexpected argument %q", positional[0])
+	}
+
+	if *username == "" {
+		if *username, err = c.readLine("Username: "); err != nil {
+			return err
+		}
+	}
+	password, err := c.readSecret(PasswordEnv, "Password: ")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.requestContext(requestTimeout)
+	defer cancel()
+
+	resp, err := c.client.LoginSrp(ctx, *username, password)
+	if err != nil {
+		return err
+	}
+
+	if resp.TwoFactorChallenge != "" {
+		if *code == "" {
+			if *code, err = c.readLine("Two-factor code: "); err != nil {
+				return err
+			}
+		}
+
+		// the code may take a while to type, so the second step gets its own timeout
+		verifyCtx, verifyCancel := c.requestContext(requestTimeout)
+		defer verifyCancel()
+
+		resp, err = c.client.VerifyLoginTwoFactor(verifyCtx, &pb.VerifyLoginTwoFactorRequest{
+			ChallengeToken: resp.TwoFactorChallenge,
+			Code:           *code,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	masterPassword, err := c.readSecret(MasterPasswordEnv, "Master password: ")
+	if err != nil {
+		return err
+	}
+	if err = c.client.SetMasterKey(masterPassword, resp.KdfSalt); err != nil {
+		return err
+	}
+
+	checkCtx, checkCancel := c.requestContext(requestTimeout)
+	defer checkCancel()
+
+	if err = c.client.CheckMasterKey(checkCtx, resp.KeyCheck); err != nil {
+		return err
+	}
+	if err = c.checkMasterKey(*username); err != nil {
+		return err
+	}
+
+	s, err := session.New(conf.Conf.ServerAddress, *username, resp.KdfSalt, c.client.Tokens(), c.client.Cipher())
+	if err == nil {
+		err = session.Save(c.sessionPath, s)
+	}
+	if err != nil {
+		return fmt.Errorf("store the session: %w", err)
+	}
+
+	fmt.Fprintf(c.stderr, "Logged in as %s\n", *username)
+	return nil
+}
+
+// checkMasterKey unlocks the local vault of the user with the master key, which checks the key
+// against the key check record of the vault, the first unlock of the vault stores the record.
+func (c *CLI) checkMasterKey(username string) error {
+	vaultPath := conf.Conf.VaultFile
+	if vaultPath == "" {
+		vaultPath = vault.DefaultPath()
+	}
+
+	v, err := vault.Open(vaultPath)
+	if err != nil {
+		return fmt.Errorf("open the local vault to check the master password: %w", err)
+	}
+	defer v.Close()
+
+	return v.Unlock(username, c.client.Cipher())
+}
+
+// logout ends the session on the server and removes the session file. The file is removed
+// even if the server rejects the logout, so a stale session never lingers.
+func (c *CLI) logout(args []string) error {
+	if len(args) > 0 {
+		return newUsageError("unexpected argument %q", args[0])
+	}
+
+	if err := c.resume(); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.requestContext(requestTimeout)
+	defer cancel()
+
+	_, logoutErr := c.client.Logout(ctx)
+	c.session = nil
+
+	if err := session.Remove(c.sessionPath); err != nil {
+		return err
+	}
+	if logoutErr != nil {
+		return fmt.Errorf("the session is removed locally, but the server did not end it: %w", logoutErr)
+	}
+
+	fmt.Fprintln(c.stderr, "Logged out")
+	return nil
+}
+
+// get writes the data item, a single field of it or the content of a binary item to stdout.
+func (c *CLI) get(args []string) error {
+	fs := newFlagSet("get")
+	dataType := fs.String("type", "", "type of the item")
+	field := fs.String("field", "", "print the field only, for example password")
+	asJSON := fs.Bool("json", false, "print the item as JSON")
+	out := fs.String("out", "", "write the content of a binary item to the file instead of stdout")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return newUsageError("expected a single ID")
+	}
+	if *field != "" && *asJSON {
+		return newUsageError("--field and --json are mutually exclusive")
+	}
+	id := positional[0]
+
+	itemType, err := resolveType(*dataType, true)
+	if err != nil {
+		return err
+	}
+
+	if err = c.resume(); err != nil {
+		return err
+	}
+
+	if itemType == payload.BinaryType {
+		return c.download(id, *out)
+	}
+
+	ctx, cancel := c.requestContext(requestTimeout)
+	defer cancel()
+
+	resp, err := c.client.GetData(ctx, &pb.GetDataRequest{Id: id, Type: itemType})
+	if err != nil {
+		return err
+	}
+	if len(resp.Data) == 0 {
+		return fmt.Errorf("data item %s: %w", id, errNotFound)
+	}
+	item := resp.Data[0]
+
+	switch {
+	case item.Type == payload.BinaryType:
+		return c.download(id, *out)
+	case *field != "":
+		value, err := itemField(item, *field)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.stdout, value)
+		return err
+	case *asJSON:
+		// the payload is printed on its own, data holds its serialized copy
+		item.Data = nil
+		data, err := protojson.MarshalOptions{UseProtoNames: true, Multiline: true}.Marshal(item)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.stdout, string(data))
+		return err
+	default:
+		return writeItem(c.stdout, item)
+	}
+}
+
+// download writes the content of the binary item to the file, or to stdout if no file is given.
+func (c *CLI) download(id, path string) error {
+	ctx, cancel := c.requestContext(transferTimeout)
+	defer cancel()
+
+	if path == "" {
+		_, err := c.client.DownloadBinary(ctx, id, c.stdout)
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.client.DownloadBinary(ctx, id, file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return err
+	}
+
+	return file.Close()
+}
+
+// create creates a data item with the content read from the file or stdin and prints its ID.
+// The content of text and binary items is taken as is, credentials and bank cards are read
+// as JSON objects of their fields, for example {"login": "alice", "password": "secret"}.
+func (c *CLI) create(args []string) error {
+	fs := newFlagSet("create")
+	dataType := fs.String("type", "", "type of the item: credentials, bank_card, text or binary")
+	fromFile := fs.String("from-file", "", "file to read the content from, - for stdin")
+	meta := fs.String("meta", "", "encrypted free-form note")
+	name := fs.String("name", "", "name of the item")
+	tags := fs.String("tags", "", "comma separated tags")
+	folder := fs.String("folder", "", "ID of the folder")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return newUsageError("unexpected argument %q", positional[0])
+	}
+	if *fromFile == "" {
+		return newUsageError("--from-file is required")
+	}
+
+	itemType, err := resolveType(*dataType, false)
+	if err != nil {
+		return err
+	}
+
+	if err = c.resume(); err != nil {
+		return err
+	}
+
+	r, size, err := c.openInput(*fromFile)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	id := uuid.New().String()
+
+	if itemType == payload.BinaryType {
+		ctx, cancel := c.requestContext(transferTimeout)
+		defer cancel()
+
+		if _, err = c.client.UploadBinary(ctx, id, *meta, r, size); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(c.stdout, id)
+		return err
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	item := &pb.DataItem{
+		Id:   id,
+		Type: itemType,
+		Meta: *meta,
+		Metadata: &pb.DataItemMetadata{
+			Name:     *name,
+			FolderId: *folder,
+			Tags:     splitList(*tags),
+		},
+	}
+	if err = setPayload(item, content); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.requestContext(requestTimeout)
+	defer cancel()
+
+	if _, err = c.client.CreateData(ctx, &pb.CreateDataRequest{Data: item}); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.stdout, id)
+	return err
+}
+
+// openInput opens the file, or stdin for -, returning the size of the file, -1 for stdin.
+func (c *CLI) openInput(path string) (io.ReadCloser, int64, error) {
+	if path == "-" {
+		return io.NopCloser(c.lines), -1, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, 0, err
+	}
+
+	return file, info.Size(), nil
+}
+
+// list prints the summaries of the data items, one per line, or as a JSON array.
+func (c *CLI) list(args []string) error {
+	fs := newFlagSet("list")
+	dataType := fs.String("type", "", "list the items of the type only")
+	asJSON := fs.Bool("json", false, "print the items as a JSON array")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return newUsageError("unexpected argument %q", positional[0])
+	}
+
+	itemType, err := resolveType(*dataType, true)
+	if err != nil {
+		return err
+	}
+
+	if err = c.resume(); err != nil {
+		return err
+	}
+
+	var items []*pb.DataItemSummary
+	req := &pb.ListDataRequest{PageSize: listPageSize, Type: itemType}
+	for {
+		ctx, cancel := c.requestContext(requestTimeout)
+		resp, err := c.client.ListData(ctx, req)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		items = append(items, resp.Items...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+
+	if *asJSON {
+		return writeSummariesJSON(c.stdout, items)
+	}
+
+	return writeSummaries(c.stdout, items)
+}
+
+// rm moves the data items to the trash.
+func (c *CLI) rm(args []string) error {
+	if len(args) == 0 {
+		return newUsageError("expected at least one ID")
+	}
+
+	if err := c.resume(); err != nil {
+		return err
+	}
+
+	for _, id := range args {
+		ctx, cancel := c.requestContext(requestTimeout)
+		_, err := c.client.DeleteData(ctx, &pb.DeleteDataRequest{Id: id})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+
+		fmt.Fprintf(c.stderr, "Moved to trash: %s\n", id)
+	}
+
+	return nil
+}
+
+// resolveType returns the stored type named by the flag, empty is allowed for optional types only.
+func resolveType(name string, optional bool) (string, error) {
+	if name == "" {
+		if optional {
+			return "", nil
+		}
+		return "", newUsageError("--type is required")
+	}
+
+	itemType, ok := typeAliases[name]
+	if !ok {
+		return "", newUsageError("unknown type %q, expected one of credentials, bank_card, text, binary", name)
+	}
+
+	return itemType, nil
+}
+
+// setPayload sets the payload of the item of the type from the content read for create.
+func setPayload(item *pb.DataItem, content []byte) error {
+	switch item.Type {
+	case payload.CredentialsType:
+		credentials := &pb.Credentials{}
+		if err := protojson.Unmarshal(content, credentials); err != nil {
+			return newUsageError("credentials must be a JSON object of login, password, url and totp_secret: %v", err)
+		}
+		item.Payload = &pb.DataItem_Credentials{Credentials: credentials}
+	case payload.BankCardType:
+		card := &pb.BankCard{}
+		if err := protojson.Unmarshal(content, card); err != nil {
+			return newUsageError("bank card must be a JSON object of number, holder, expiry and cvv: %v", err)
+		}
+		item.Payload = &pb.DataItem_BankCard{BankCard: card}
+	case payload.TextType:
+		item.Payload = &pb.DataItem_Text{Text: &pb.Text{Content: string(content)}}
+	}
+
+	return nil
+}
+
+// itemField returns the value of the named field of the item.
+func itemField(item *pb.DataItem, field string) (string, error) {
+	fields := map[string]string{
+		"id":      item.Id,
+		"type":    item.Type,
+		"meta":    item.Meta,
+		"name":    item.GetMetadata().GetName(),
+		"version": strconv.Itoa(int(item.Version)),
+	}
+
+	switch p := item.Payload.(type) {
+	case *pb.DataItem_Credentials:
+		fields["login"] = p.Credentials.Login
+		fields["password"] = p.Credentials.Password
+		fields["url"] = p.Credentials.Url
+		fields["totp_secret"] = p.Credentials.TotpSecret
+	case *pb.DataItem_BankCard:
+		fields["number"] = p.BankCard.Number
+		fields["holder"] = p.BankCard.Holder
+		fields["expiry"] = p.BankCard.Expiry
+		fields["cvv"] = p.BankCard.Cvv
+	case *pb.DataItem_Text:
+		fields["content"] = p.Text.Content
+	}
+
+	value, ok := fields[field]
+	if !ok {
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", newUsageError("unknown field %q of %s item, expected one of %s", field, item.Type, strings.Join(names, ", "))
+	}
+
+	return value, nil
+}
+
+// writeItem prints the fields of the item, one per line.
+func writeItem(w io.Writer, item *pb.DataItem) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "id\t%s\n", item.Id)
+	fmt.Fprintf(tw, "type\t%s\n", item.Type)
+	fmt.Fprintf(tw, "version\t%d\n", item.Version)
+	if name := item.GetMetadata().GetName(); name != "" {
+		fmt.Fprintf(tw, "name\t%s\n", name)
+	}
+
+	switch p := item.Payload.(type) {
+	case *pb.DataItem_Credentials:
+		fmt.Fprintf(tw, "login\t%s\npassword\t%s\nurl\t%s\ntotp_secret\t%s\n",
+			p.Credentials.Login, p.Credentials.Password, p.Credentials.Url, p.Credentials.TotpSecret)
+	case *pb.DataItem_BankCard:
+		fmt.Fprintf(tw, "number\t%s\nholder\t%s\nexpiry\t%s\ncvv\t%s\n",
+			p.BankCard.Number, p.BankCard.Holder, p.BankCard.Expiry, p.BankCard.Cvv)
+	case *pb.DataItem_Text:
+		fmt.Fprintf(tw, "content\t%s\n", p.Text.Content)
+	}
+
+	fmt.Fprintf(tw, "meta\t%s\n", item.Meta)
+	fmt.Fprintf(tw, "updated_at\t%s\n", item.UpdatedAt.AsTime().Format(time.RFC3339))
+
+	return tw.Flush()
+}
+
+// writeSummaries prints the summaries as a table with a header.
+func writeSummaries(w io.Writer, items []*pb.DataItemSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tTYPE\tNAME\tMETA\tUPDATED")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", item.Id, item.Type, item.GetMetadata().GetName(),
+			item.Meta, item.UpdatedAt.AsTime().Format(time.RFC3339))
+	}
+
+	return tw.Flush()
+}
+
+// writeSummariesJSON prints the summaries as a JSON array.
+func writeSummariesJSON(w io.Writer, items []*pb.DataItemSummary) error {
+	result := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(item)
+		if err != nil {
+			return err
+		}
+		result = append(result, data)
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// splitList splits the comma separated list, dropping the empty entries.
+func splitList(value string) []string {
+	var result []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
@@ -3,25 +3,81 @@
 package client
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gophKeeper/client/internal/crypto"
+	"gophKeeper/pkg/password"
+	"gophKeeper/pkg/payload"
 	pb "gophKeeper/pkg/proto/gophkeeper"
+	"gophKeeper/pkg/srp"
+	"io"
 	"log/slog"
 	"os"
 	"sync"
 	"time"
 )
 
+const (
+	authorizationMetadataKey = "authorization"
+	// refreshLeeway is how long before its expiration the access token is refreshed.
+	refreshLeeway = 30 * time.Second
+
+	// uploadChunkSize is the size of the chunks sent by UploadBinary.
+	uploadChunkSize = 32 * 1024
+	// downloadHeaderSize is the number of bytes peeked by DownloadBinary to detect the ciphertext format.
+	downloadHeaderSize = 16
+
+	// keyCheckValue is the plaintext of the key check the vault key is verified with.
+	keyCheckValue = "gophkeeper key check"
+)
+
+// keyCheckAdditionalData binds the key check to its purpose, so no data item can pass for it.
+var keyCheckAdditionalData = []byte("key_check")
+
+var (
+	// ErrLocked is returned when data items are sent or received before the master key is set.
+	ErrLocked = errors.New("vault is locked: master key is not set")
+	// ErrNoRefreshToken is returned when the token pair can not be refreshed because the client is not logged in.
+	ErrNoRefreshToken = errors.New("refresh token is not set")
+	// ErrInvalidServerProof is returned when the server fails to prove the knowledge of the SRP verifier.
+	ErrInvalidServerProof = errors.New("server failed to prove the knowledge of the password verifier")
+	// ErrNotLoggedIn is returned when the password is re-verified before the client logged in.
+	ErrNotLoggedIn = errors.New("not logged in")
+	// ErrWrongMasterKey is returned when the master key does not open the data of the account.
+	ErrWrongMasterKey = errors.New("wrong master password")
+)
+
+// publicMethods lists the methods called without an access token.
+var publicMethods = map[string]bool{
+	pb.GophKeeperService_Register_FullMethodName:             true,
+	pb.GophKeeperService_Login_FullMethodName:                true,
+	pb.GophKeeperService_RegisterSrp_FullMethodName:          true,
+	pb.GophKeeperService_LoginSrpStart_FullMethodName:        true,
+	pb.GophKeeperService_LoginSrpFinish_FullMethodName:       true,
+	pb.GophKeeperService_VerifyLoginTwoFactor_FullMethodName: true,
+	pb.GophKeeperService_RefreshToken_FullMethodName:         true,
+	pb.GophKeeperService_GetPasswordPolicy_FullMethodName:    true,
+	pb.GophKeeperService_Ping_FullMethodName:                 true,
+}
+
 // GophKeeperClient represents the gRPC client for interacting with the GophKeeper service.
-// It handles both secure (TLS) and insecure connections and manages the Bearer token
-// for authenticated requests.
+// It handles both secure (TLS) and insecure connections, manages the Bearer token
+// for authenticated requests, refreshing it before it expires, and encrypts data items
+// before they leave the client.
 type GophKeeperClient struct {
 	client         pb.GophKeeperServiceClient
 	wg             sync.WaitGroup
@@ -30,6 +86,24 @@ type GophKeeperClient struct {
 	caFile         string
 	clientCertFile string
 	clientKeyFile  string
+	cipher         *crypto.Cipher
+
+	// srpUpgradePassword is the password of an account registered with a password,
+	// kept until its two-factor login completes to replace it with an SRP verifier.
+	srpUpgradePassword string
+	// passwordAccount reports whether the logged in account is still registered with a password,
+	// as its upgrade to SRP failed, so the password is re-verified by sending it.
+	passwordAccount bool
+	// username is the username of the logged in account, the password is re-verified with it.
+	username string
+	// onReconnect is called when a ping finds the server available again after it was not.
+	onReconnect func()
+	// onTokens is called with every token pair received from the server, under tokenMu.
+	onTokens func(tokens Tokens)
+
+	tokenMu        sync.Mutex
+	refreshToken   string
+	tokenExpiresAt time.Time
 
 	ServerAvailable bool
 	BearerToken     string
@@ -48,16 +122,22 @@ func NewGophKeeperClient(enableTLS bool, serverAddress, caFile, clientCertFile,
 		transportOption = grpc.WithTransportCredentials(tlsConfig)
 	}
 
-	conn, err := grpc.NewClient(serverAddress, transportOption)
+	c := &GophKeeperClient{
+		serverAddress: serverAddress,
+	}
+
+	conn, err := grpc.NewClient(serverAddress, transportOption,
+		grpc.WithChainUnaryInterceptor(c.refreshUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(c.refreshStreamInterceptor),
+	)
 	if err != nil {
 		slog.Error("NewGophKeeperClient error", slog.String("error", err.Error()))
 
 	}
 
-	return &GophKeeperClient{
-		client:        pb.NewGophKeeperServiceClient(conn),
-		serverAddress: serverAddress,
-	}, nil
+	c.client = pb.NewGophKeeperServiceClient(conn)
+
+	return c, nil
 }
 
 // loadTLSCredentials loads the necessary TLS credentials, including the CA certificate,
@@ -89,9 +169,14 @@ func loadTLSCredentials(caFile, clientCertFile, clientKeyFile string) (credentia
 	return credentials.NewTLS(config), nil
 }
 
+// CreateContextWithMetadata returns a context with the given timeout carrying the access token.
 func (c *GophKeeperClient) CreateContextWithMetadata(timeout time.Duration) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	md := metadata.Pairs("token", "Bearer "+c.BearerToken)
+
+	c.tokenMu.Lock()
+	md := metadata.Pairs(authorizationMetadataKey, "Bearer "+c.BearerToken)
+	c.tokenMu.Unlock()
+
 	return metadata.NewOutgoingContext(ctx, md), cancel
 }
 
@@ -101,48 +186,1008 @@ func (c *GophKeeperClient) Register(ctx context.Context, req *pb.RegisterRequest
 }
 
 // Login sends a login request to the GophKeeper server and returns a response containing a token.
+// The received token pair is kept by the client and refreshed automatically before it expires.
+// Accounts with two-factor authentication get a challenge to complete with VerifyLoginTwoFactor instead.
 func (c *GophKeeperClient) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	return c.client.Login(ctx, req)
+	resp, err := c.client.Login(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.username = req.GetUsername()
+	c.passwordAccount = true
+	if resp.GetTwoFactorChallenge() == "" {
+		c.setTokens(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+	}
+
+	return resp, nil
+}
+
+// RegisterSrp registers a new account with the SRP verifier of the password,
+// so the password never leaves the client.
+func (c *GophKeeperClient) RegisterSrp(ctx context.Context, username, password string) (*pb.RegisterResponse, error) {
+	salt, err := srp.NewSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.client.RegisterSrp(ctx, &pb.RegisterSrpRequest{
+		Username: username,
+		Salt:     salt,
+		Verifier: srp.Verifier(password, salt),
+	})
+}
+
+// PasswordPolicy returns the password policy of the server. The passwords SRP verifiers
+// are derived from never reach the server, so the client checks them against it.
+func (c *GophKeeperClient) PasswordPolicy(ctx context.Context) (password.Policy, error) {
+	resp, err := c.client.GetPasswordPolicy(ctx, &emptypb.Empty{})
+	if err != nil {
+		return password.Policy{}, err
+	}
+
+	return password.Policy{
+		MinLength:  int(resp.GetMinLength()),
+		MinEntropy: resp.GetMinEntropy(),
+	}, nil
+}
+
+// LoginSrp logs in with SRP, proving the knowledge of the password without sending it,
+// and checks that the server knows the password verifier. Only accounts the server reports
+// as registered with a password log in with the password, which replaces the password
+// with an SRP verifier. A rejected proof fails the login, the password is never sent after it.
+// The received token pair is kept by the client and refreshed automatically before it expires.
+// Accounts with two-factor authentication get a challenge to complete with VerifyLoginTwoFactor instead.
+func (c *GophKeeperClient) LoginSrp(ctx context.Context, username, password string) (*pb.LoginResponse, error) {
+	srpClient, err := srp.NewClient(password)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := c.client.LoginSrpStart(ctx, &pb.LoginSrpStartRequest{
+		Username:     username,
+		ClientPublic: srpClient.PublicKey(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if challenge.GetPasswordLogin() {
+		return c.upgradeToSrp(ctx, username, password)
+	}
+
+	clientProof, err := srpClient.Proof(username, challenge.GetSalt(), challenge.GetServerPublic())
+	if err != nil {
+		return nil, ErrInvalidServerProof
+	}
+
+	resp, err := c.client.LoginSrpFinish(ctx, &pb.LoginSrpFinishRequest{
+		LoginId:     challenge.GetLoginId(),
+		ClientProof: clientProof,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = srpClient.VerifyServer(resp.GetServerProof()); err != nil {
+		return nil, ErrInvalidServerProof
+	}
+
+	c.username = username
+	c.passwordAccount = false
+	if resp.GetTwoFactorChallenge() == "" {
+		c.setTokens(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+	}
+
+	return resp, nil
+}
+
+// upgradeToSrp logs in an account registered with a password by sending the password, and replaces
+// the password with an SRP verifier. A failed upgrade does not fail the login, it is retried on the next one.
+// Accounts with two-factor authentication are upgraded once the login challenge is completed.
+func (c *GophKeeperClient) upgradeToSrp(ctx context.Context, username, password string) (*pb.LoginResponse, error) {
+	resp, err := c.Login(ctx, &pb.LoginRequest{
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.GetTwoFactorChallenge() != "" {
+		c.srpUpgradePassword = password
+		return resp, nil
+	}
+
+	c.setSrpVerifier(ctx, password)
+
+	return resp, nil
+}
+
+// setSrpVerifier replaces the password of the logged in account with an SRP verifier,
+// proving the knowledge of the password, and logs the failure instead of returning it.
+// An account using SRP already has nothing to replace.
+func (c *GophKeeperClient) setSrpVerifier(ctx context.Context, password string) {
+	salt, err := srp.NewSalt()
+	if err == nil {
+		_, err = c.client.SetSrpVerifier(ctx, &pb.SetSrpVerifierRequest{
+			Salt:     salt,
+			Verifier: srp.Verifier(password, salt),
+			Proof:    &pb.PasswordProof{Password: password},
+		})
+	}
+	if err != nil && status.Code(err) != codes.FailedPrecondition {
+		slog.Warn("Failed to upgrade the account to SRP", slog.String("error", err.Error()))
+		return
+	}
+	c.passwordAccount = false
+}
+
+// VerifyLoginTwoFactor completes the two-factor challenge of a login with a TOTP or recovery code.
+// The received token pair is kept by the client and refreshed automatically before it expires.
+func (c *GophKeeperClient) VerifyLoginTwoFactor(ctx context.Context, req *pb.VerifyLoginTwoFactorRequest) (*pb.LoginResponse, error) {
+	resp, err := c.client.VerifyLoginTwoFactor(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setTokens(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+
+	if c.srpUpgradePassword != "" {
+		c.setSrpVerifier(ctx, c.srpUpgradePassword)
+		c.srpUpgradePassword = ""
+	}
+
+	return resp, nil
+}
+
+// EnrollTwoFactor sends a request to generate a new TOTP secret for the authenticator app.
+func (c *GophKeeperClient) EnrollTwoFactor(ctx context.Context) (*pb.EnrollTwoFactorResponse, error) {
+	return c.client.EnrollTwoFactor(ctx, &emptypb.Empty{})
+}
+
+// ConfirmTwoFactor sends a request to enable two-factor authentication with a code of the enrolled secret.
+func (c *GophKeeperClient) ConfirmTwoFactor(ctx context.Context, req *pb.ConfirmTwoFactorRequest) (*pb.ConfirmTwoFactorResponse, error) {
+	return c.client.ConfirmTwoFactor(ctx, req)
+}
+
+// DisableTwoFactor sends a request to turn off two-factor authentication with a TOTP or recovery code.
+func (c *GophKeeperClient) DisableTwoFactor(ctx context.Context, req *pb.DisableTwoFactorRequest) (*pb.DisableTwoFactorResponse, error) {
+	return c.client.DisableTwoFactor(ctx, req)
+}
+
+// Username returns the username of the logged in account, empty if the client is not logged in.
+func (c *GophKeeperClient) Username() string {
+	return c.username
+}
+
+// ServerAddress returns the address of the server the client is connected to.
+func (c *GophKeeperClient) ServerAddress() string {
+	return c.serverAddress
+}
+
+// Tokens is the token pair of the logged in account, kept to resume the session later.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// Tokens returns the current token pair, which changes whenever the access token is refreshed.
+func (c *GophKeeperClient) Tokens() Tokens {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	return Tokens{
+		AccessToken:  c.BearerToken,
+		RefreshToken: c.refreshToken,
+		ExpiresAt:    c.tokenExpiresAt,
+	}
+}
+
+// ResumeSession resumes the session of the account with the token pair kept by Tokens,
+// the access token is refreshed on the first call if it has expired meanwhile.
+// The account is expected to use SRP, as the login of the session upgraded it.
+func (c *GophKeeperClient) ResumeSession(username string, tokens Tokens) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.username = username
+	c.passwordAccount = false
+	c.BearerToken = tokens.AccessToken
+	c.refreshToken = tokens.RefreshToken
+	c.tokenExpiresAt = tokens.ExpiresAt
+}
+
+// OnTokens sets the function called with every token pair received from the server,
+// so a persisted session keeps the refresh token, which is rotated on every refresh.
+// The function must not call the client, it is called while the token pair is being replaced.
+func (c *GophKeeperClient) OnTokens(fn func(tokens Tokens)) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.onTokens = fn
+}
+
+// Lock forgets the master key without ending the session, data items can not be sent
+// or received until the master key is set again.
+func (c *GophKeeperClient) Lock() {
+	c.cipher = nil
+}
+
+// ChangePassword replaces the password of the logged in account after proving the knowledge
+// of the current one. The new password is set as an SRP verifier, so it never leaves the client.
+// The server revokes all sessions of the account, the client keeps the token pair of the new one.
+func (c *GophKeeperClient) ChangePassword(ctx context.Context, oldPassword, newPassword string) (*pb.LoginResponse, error) {
+	proof, srpClient, err := c.passwordProof(ctx, oldPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := srp.NewSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		Proof:    proof,
+		Salt:     salt,
+		Verifier: srp.Verifier(newPassword, salt),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if srpClient != nil {
+		if err = srpClient.VerifyServer(resp.GetServerProof()); err != nil {
+			return nil, ErrInvalidServerProof
+		}
+	}
+
+	c.setTokens(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+
+	return resp, nil
+}
+
+// ChangeUsername sends a request to rename the logged in account.
+func (c *GophKeeperClient) ChangeUsername(ctx context.Context, username string) (*pb.ChangeUsernameResponse, error) {
+	resp, err := c.client.ChangeUsername(ctx, &pb.ChangeUsernameRequest{
+		Username: username,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.username = username
+
+	return resp, nil
+}
+
+// DeleteAccount deletes the logged in account with all its data after proving the knowledge
+// of the password, and forgets the token pair and the vault key.
+func (c *GophKeeperClient) DeleteAccount(ctx context.Context, password string) (*pb.DeleteAccountResponse, error) {
+	proof, _, err := c.passwordProof(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.DeleteAccount(ctx, &pb.DeleteAccountRequest{
+		Proof: proof,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.setTokens("", "", nil)
+	c.cipher = nil
+	c.username = ""
+	c.passwordAccount = false
+
+	return resp, nil
+}
+
+// passwordProof proves the knowledge of the password of the logged in account. SRP accounts
+// start an SRP login and prove the password without sending it, the returned SRP client checks
+// the server proof of the answer. Accounts still registered with a password after the login
+// send the password itself.
+func (c *GophKeeperClient) passwordProof(ctx context.Context, password string) (*pb.PasswordProof, *srp.Client, error) {
+	if c.username == "" {
+		return nil, nil, ErrNotLoggedIn
+	}
+
+	if c.passwordAccount {
+		return &pb.PasswordProof{Password: password}, nil, nil
+	}
+
+	srpClient, err := srp.NewClient(password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	challenge, err := c.client.LoginSrpStart(ctx, &pb.LoginSrpStartRequest{
+		Username:     c.username,
+		ClientPublic: srpClient.PublicKey(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientProof, err := srpClient.Proof(c.username, challenge.GetSalt(), challenge.GetServerPublic())
+	if err != nil {
+		return nil, nil, ErrInvalidServerProof
+	}
+
+	return &pb.PasswordProof{
+		LoginId:     challenge.GetLoginId(),
+		ClientProof: clientProof,
+	}, srpClient, nil
+}
+
+// RefreshToken exchanges the refresh token kept by the client for a new token pair.
+func (c *GophKeeperClient) RefreshToken(ctx context.Context) error {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	return c.refreshLocked(ctx)
+}
+
+// Logout sends a request to end the current session and forgets the token pair.
+func (c *GophKeeperClient) Logout(ctx context.Context) (*pb.LogoutResponse, error) {
+	resp, err := c.client.Logout(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	c.setTokens("", "", nil)
+	c.cipher = nil
+	c.username = ""
+	c.passwordAccount = false
+
+	return resp, nil
+}
+
+// ListSessions sends a request to retrieve the active sessions of the user.
+func (c *GophKeeperClient) ListSessions(ctx context.Context) (*pb.ListSessionsResponse, error) {
+	return c.client.ListSessions(ctx, &emptypb.Empty{})
+}
+
+// RevokeSession sends a request to revoke the session with the given ID.
+func (c *GophKeeperClient) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	return c.client.RevokeSession(ctx, req)
+}
+
+// SetMasterKey derives the vault key from the master password and the salt received on login.
+// Data items are encrypted and decrypted with this key.
+func (c *GophKeeperClient) SetMasterKey(masterPassword string, kdfSalt []byte) error {
+	if masterPassword == "" || len(kdfSalt) == 0 {
+		return fmt.Errorf("master password and kdf salt are required")
+	}
+
+	cipher, err := crypto.NewFromPassphrase(masterPassword, kdfSalt)
+	if err != nil {
+		return err
+	}
+
+	c.cipher = cipher
+	return nil
+}
+
+// Cipher returns the cipher of the vault key, nil until the master key is set.
+func (c *GophKeeperClient) Cipher() *crypto.Cipher {
+	return c.cipher
 }
 
-// CreateData sends a request to create a new data item in the GophKeeper server.
+// CheckMasterKey verifies the master key against the key check received on login and returns
+// ErrWrongMasterKey if it does not open it. Accounts without a key check are verified against
+// one of their data items, then the key check is sealed with the master key and stored on the
+// server, so the first login on every other machine is verified too.
+func (c *GophKeeperClient) CheckMasterKey(ctx context.Context, keyCheck []byte) error {
+	if c.cipher == nil {
+		return ErrLocked
+	}
+
+	if len(keyCheck) > 0 {
+		value, err := c.cipher.Open(keyCheck, keyCheckAdditionalData)
+		if err != nil || string(value) != keyCheckValue {
+			return ErrWrongMasterKey
+		}
+		return nil
+	}
+
+	list, err := c.client.ListData(ctx, &pb.ListDataRequest{PageSize: 1})
+	if err != nil {
+		return err
+	}
+	if len(list.GetItems()) > 0 {
+		_, err = c.GetData(ctx, &pb.GetDataRequest{Id: list.GetItems()[0].GetId()})
+		if errors.Is(err, crypto.ErrDecrypt) {
+			return ErrWrongMasterKey
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	keyCheck, err = c.cipher.Seal([]byte(keyCheckValue), keyCheckAdditionalData)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.SetKeyCheck(ctx, &pb.SetKeyCheckRequest{KeyCheck: keyCheck})
+	// another client of the account stored its key check first, it is verified on the next login
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil
+	}
+	return err
+}
+
+// CreateData encrypts the data item and sends a request to create it in the GophKeeper server.
 func (c *GophKeeperClient) CreateData(ctx context.Context, req *pb.CreateDataRequest) (*pb.CreateDataResponse, error) {
-	return c.client.CreateData(ctx, req)
+	item, err := c.encryptItem(req.GetData())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.client.CreateData(ctx, &pb.CreateDataRequest{Data: item})
 }
 
-// GetData sends a request to retrieve a data item from the GophKeeper server.
+// GetData sends a request to retrieve a data item from the GophKeeper server and decrypts it.
 func (c *GophKeeperClient) GetData(ctx context.Context, req *pb.GetDataRequest) (*pb.GetDataResponse, error) {
-	return c.client.GetData(ctx, req)
+	resp, err := c.client.GetData(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decryptItems(resp.Data); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
 }
 
-// ListData sends a request to retrieve a data items from GophKeeper server.
-func (c *GophKeeperClient) ListData(ctx context.Context, req *emptypb.Empty) (*pb.ListDataResponse, error) {
-	return c.client.ListData(ctx, req)
+// ListData sends a request to retrieve a page of data item summaries from GophKeeper server
+// and decrypts their meta. The payloads are not received, they are fetched with GetData.
+func (c *GophKeeperClient) ListData(ctx context.Context, req *pb.ListDataRequest) (*pb.ListDataResponse, error) {
+	resp, err := c.client.ListData(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decryptSummaries(resp.Items); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
 }
 
-// UpdateData sends a request to update an existing data item in the GophKeeper server.
+// UpdateData encrypts the data item and sends a request to update it in the GophKeeper server.
 func (c *GophKeeperClient) UpdateData(ctx context.Context, req *pb.UpdateDataRequest) (*pb.UpdateDataResponse, error) {
-	return c.client.UpdateData(ctx, req)
+	item, err := c.encryptItem(req.GetData())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.client.UpdateData(ctx, &pb.UpdateDataRequest{Data: item})
 }
 
-// DeleteData sends a request to delete a data item from the GophKeeper server.
+// DeleteData sends a request to move a data item to the trash of the GophKeeper server.
 func (c *GophKeeperClient) DeleteData(ctx context.Context, req *pb.DeleteDataRequest) (*pb.DeleteDataResponse, error) {
 	return c.client.DeleteData(ctx, req)
 }
 
+// ListTrash sends a request to retrieve the data items in the trash and decrypts them.
+func (c *GophKeeperClient) ListTrash(ctx context.Context) (*pb.ListTrashResponse, error) {
+	resp, err := c.client.ListTrash(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decryptItems(resp.Data); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// RestoreData sends a request to move a data item back from the trash of the GophKeeper server.
+func (c *GophKeeperClient) RestoreData(ctx context.Context, req *pb.RestoreDataRequest) (*pb.RestoreDataResponse, error) {
+	return c.client.RestoreData(ctx, req)
+}
+
+// SearchData sends a request to search the data items by their structured metadata
+// and decrypts the meta of the found summaries.
+func (c *GophKeeperClient) SearchData(ctx context.Context, req *pb.SearchDataRequest) (*pb.SearchDataResponse, error) {
+	resp, err := c.client.SearchData(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decryptSummaries(resp.Items); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CreateFolder sends a request to create a new folder in the GophKeeper server.
+func (c *GophKeeperClient) CreateFolder(ctx context.Context, req *pb.CreateFolderRequest) (*pb.CreateFolderResponse, error) {
+	return c.client.CreateFolder(ctx, req)
+}
+
+// ListFolders sends a request to retrieve all the folders of the user.
+func (c *GophKeeperClient) ListFolders(ctx context.Context) (*pb.ListFoldersResponse, error) {
+	return c.client.ListFolders(ctx, &emptypb.Empty{})
+}
+
+// DeleteFolder sends a request to remove a folder with its subfolders from the GophKeeper server.
+func (c *GophKeeperClient) DeleteFolder(ctx context.Context, req *pb.DeleteFolderRequest) (*pb.DeleteFolderResponse, error) {
+	return c.client.DeleteFolder(ctx, req)
+}
+
+// ListVersions sends a request to retrieve the versions of a data item, newest first,
+// and decrypts their meta. The data of the versions is not received.
+func (c *GophKeeperClient) ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error) {
+	resp, err := c.client.ListVersions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decryptItems(resp.Versions); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetVersion sends a request to retrieve a version of a data item and decrypts it.
+func (c *GophKeeperClient) GetVersion(ctx context.Context, req *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
+	resp, err := c.client.GetVersion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decryptItems([]*pb.DataItem{resp.Data}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// RestoreVersion sends a request to make a version of a data item current again
+// and decrypts the restored item.
+func (c *GophKeeperClient) RestoreVersion(ctx context.Context, req *pb.RestoreVersionRequest) (*pb.RestoreVersionResponse, error) {
+	resp, err := c.client.RestoreVersion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decryptItems([]*pb.DataItem{resp.Data}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 // SyncData sends a request to synchronize data between the client and the GophKeeper server.
+// Local items are encrypted before sending, received items and conflicts are decrypted.
 func (c *GophKeeperClient) SyncData(ctx context.Context, req *pb.SyncDataRequest) (*pb.SyncDataResponse, error) {
-	return c.client.SyncData(ctx, req)
+	encrypted := &pb.SyncDataRequest{
+		Data:         make([]*pb.DataItem, 0, len(req.GetData())),
+		LastSyncedAt: req.GetLastSyncedAt(),
+	}
+	for _, item := range req.GetData() {
+		encryptedItem, err := c.encryptItem(item)
+		if err != nil {
+			return nil, err
+		}
+		encrypted.Data = append(encrypted.Data, encryptedItem)
+	}
+
+	resp, err := c.client.SyncData(ctx, encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decryptItems(resp.Data); err != nil {
+		return nil, err
+	}
+	for _, conflict := range resp.Conflicts {
+		if err = c.decryptItems([]*pb.DataItem{conflict.Client, conflict.Server}); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// UploadBinary encrypts the content read from r and streams it to the GophKeeper server in chunks,
+// creating or replacing the binary item with the given ID. The size is the plaintext size in bytes,
+// or -1 if it is unknown.
+func (c *GophKeeperClient) UploadBinary(ctx context.Context, id, meta string, r io.Reader, size int64) (*pb.UploadBinaryResponse, error) {
+	if c.cipher == nil {
+		return nil, ErrLocked
+	}
+
+	encryptedMeta, err := c.cipher.SealString(meta, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt meta of item %s: %w", id, err)
+	}
+
+	encryptedSize := int64(-1)
+	if size >= 0 {
+		encryptedSize = c.cipher.EncryptedSize(size)
+	}
+
+	encReader, err := c.cipher.NewEncryptReader(r, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt content of item %s: %w", id, err)
+	}
+
+	stream, err := c.client.UploadBinary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stream.Send(&pb.UploadBinaryRequest{
+		Content: &pb.UploadBinaryRequest_Info{
+			Info: &pb.BinaryInfo{Id: id, Meta: encryptedMeta, Size: encryptedSize},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, uploadChunkSize)
+	for {
+		n, err := io.ReadFull(encReader, buf)
+		if n > 0 {
+			sendErr := stream.Send(&pb.UploadBinaryRequest{
+				Content: &pb.UploadBinaryRequest_Chunk{Chunk: buf[:n]},
+			})
+			if sendErr != nil {
+				// the server closed the stream, the actual error is returned by CloseAndRecv
+				break
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			_ = stream.CloseSend()
+			return nil, fmt.Errorf("read content of item %s: %w", id, err)
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+// DownloadBinary streams the binary item with the given ID from the GophKeeper server,
+// decrypts its content into w and returns the item's decrypted meta.
+func (c *GophKeeperClient) DownloadBinary(ctx context.Context, id string, w io.Writer) (string, error) {
+	return c.DownloadBinaryVersion(ctx, id, 0, w)
+}
+
+// DownloadBinaryVersion streams the given version of the binary item from the GophKeeper server,
+// decrypts its content into w and returns the version's decrypted meta.
+// The current version is downloaded if the version is not positive.
+func (c *GophKeeperClient) DownloadBinaryVersion(ctx context.Context, id string, version int32, w io.Writer) (string, error) {
+	if c.cipher == nil {
+		return "", ErrLocked
+	}
+
+	stream, err := c.client.DownloadBinary(ctx, &pb.DownloadBinaryRequest{Id: id, Version: version})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return "", err
+	}
+
+	info := resp.GetInfo()
+	if info == nil {
+		return "", fmt.Errorf("first message of item %s does not contain binary info", id)
+	}
+
+	meta := info.GetMeta()
+	if meta != "" {
+		meta, err = c.cipher.OpenString(meta, []byte(id))
+		if err != nil {
+			return "", fmt.Errorf("decrypt meta of item %s: %w", id, err)
+		}
+	}
+
+	content := bufio.NewReader(&downloadReader{stream: stream})
+	if header, _ := content.Peek(downloadHeaderSize); !crypto.IsStream(header) {
+		// binaries created with CreateData are sealed as a whole
+		sealed, err := io.ReadAll(content)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := c.cipher.Open(sealed, []byte(id))
+		if err != nil {
+			return "", fmt.Errorf("decrypt content of item %s: %w", id, err)
+		}
+
+		_, err = w.Write(data)
+		return meta, err
+	}
+
+	decReader, err := c.cipher.NewDecryptReader(content, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("decrypt content of item %s: %w", id, err)
+	}
+
+	if _, err = io.Copy(w, decReader); err != nil {
+		return "", fmt.Errorf("decrypt content of item %s: %w", id, err)
+	}
+
+	return meta, nil
+}
+
+// encryptItem returns a copy of the data item with its typed payload validated and serialized,
+// and its data and meta encrypted. The item ID is used as additional data, so ciphertexts
+// can not be swapped between items.
+func (c *GophKeeperClient) encryptItem(item *pb.DataItem) (*pb.DataItem, error) {
+	if item == nil {
+		return nil, nil
+	}
+	if c.cipher == nil {
+		return nil, ErrLocked
+	}
+
+	encrypted := proto.Clone(item).(*pb.DataItem)
+
+	if err := payload.Validate(encrypted); err != nil {
+		return nil, err
+	}
+	if err := payload.Marshal(encrypted); err != nil {
+		return nil, err
+	}
+
+	data, err := c.cipher.Seal(encrypted.Data, []byte(item.Id))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt data of item %s: %w", item.Id, err)
+	}
+	encrypted.Data = data
+
+	encrypted.Meta, err = c.cipher.SealString(item.Meta, []byte(item.Id))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt meta of item %s: %w", item.Id, err)
+	}
+
+	return encrypted, nil
+}
+
+// decryptItems decrypts the data and meta of the received data items in place
+// and parses the decrypted data into the typed payload.
+func (c *GophKeeperClient) decryptItems(items []*pb.DataItem) error {
+	if c.cipher == nil {
+		return ErrLocked
+	}
+
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+
+		data, err := c.openData(item.Data, []byte(item.Id))
+		if err != nil {
+			return fmt.Errorf("decrypt data of item %s: %w", item.Id, err)
+		}
+		item.Data = data
+
+		if item.Meta != "" {
+			item.Meta, err = c.cipher.OpenString(item.Meta, []byte(item.Id))
+			if err != nil {
+				return fmt.Errorf("decrypt meta of item %s: %w", item.Id, err)
+			}
+		}
+
+		if err = payload.Unmarshal(item); err != nil {
+			return fmt.Errorf("parse payload of item %s: %w", item.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// decryptSummaries decrypts the meta of the data item summaries in place.
+func (c *GophKeeperClient) decryptSummaries(items []*pb.DataItemSummary) error {
+	if c.cipher == nil {
+		return ErrLocked
+	}
+
+	for _, item := range items {
+		if item == nil || item.Meta == "" {
+			continue
+		}
+
+		meta, err := c.cipher.OpenString(item.Meta, []byte(item.Id))
+		if err != nil {
+			return fmt.Errorf("decrypt meta of item %s: %w", item.Id, err)
+		}
+		item.Meta = meta
+	}
+
+	return nil
+}
+
+// openData decrypts the data of an item. Binaries uploaded with UploadBinary keep their
+// content in the object storage and have no data, streamed ciphertexts are decrypted segment by segment.
+func (c *GophKeeperClient) openData(data, additionalData []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if !crypto.IsStream(data) {
+		return c.cipher.Open(data, additionalData)
+	}
+
+	decReader, err := c.cipher.NewDecryptReader(bytes.NewReader(data), additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(decReader)
+}
+
+// downloadReader adapts the server stream of DownloadBinary to io.Reader.
+type downloadReader struct {
+	stream pb.GophKeeperService_DownloadBinaryClient
+	buf    []byte
 }
 
-// IsServerAvailable pings server
+// Read reads the content of the received chunks, receiving the next chunk when the current one is consumed.
+func (r *downloadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		resp, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = resp.GetChunk()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+// setTokens stores the token pair received from the server.
+func (c *GophKeeperClient) setTokens(token, refreshToken string, expiresAt *timestamppb.Timestamp) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.setTokensLocked(token, refreshToken, expiresAt)
+}
+
+// setTokensLocked stores the token pair, the caller must hold tokenMu.
+func (c *GophKeeperClient) setTokensLocked(token, refreshToken string, expiresAt *timestamppb.Timestamp) {
+	c.BearerToken = token
+	c.refreshToken = refreshToken
+	c.tokenExpiresAt = time.Time{}
+	if expiresAt != nil {
+		c.tokenExpiresAt = expiresAt.AsTime()
+	}
+
+	if c.onTokens != nil && token != "" {
+		c.onTokens(Tokens{
+			AccessToken:  c.BearerToken,
+			RefreshToken: c.refreshToken,
+			ExpiresAt:    c.tokenExpiresAt,
+		})
+	}
+}
+
+// refreshLocked exchanges the refresh token for a new token pair, the caller must hold tokenMu.
+func (c *GophKeeperClient) refreshLocked(ctx context.Context) error {
+	if c.refreshToken == "" {
+		return ErrNoRefreshToken
+	}
+
+	resp, err := c.client.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: c.refreshToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.setTokensLocked(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+	return nil
+}
+
+// freshToken returns the access token, refreshing it first if it expires within refreshLeeway
+// or if it is still the stale token a request was rejected with.
+func (c *GophKeeperClient) freshToken(ctx context.Context, staleToken string) (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	expiring := !c.tokenExpiresAt.IsZero() && time.Until(c.tokenExpiresAt) < refreshLeeway
+	if c.refreshToken != "" && (expiring || (staleToken != "" && c.BearerToken == staleToken)) {
+		if err := c.refreshLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	return c.BearerToken, nil
+}
+
+// withToken returns a copy of the context whose outgoing metadata carries the given access token.
+func withToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set(authorizationMetadataKey, "Bearer "+token)
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// refreshUnaryInterceptor keeps the access token of unary calls fresh. The token is refreshed
+// before it expires, and a call rejected as unauthenticated is retried once with a refreshed token.
+func (c *GophKeeperClient) refreshUnaryInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if publicMethods[method] {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	token, err := c.freshToken(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	err = invoker(withToken(ctx, token), method, req, reply, cc, opts...)
+	if status.Code(err) != codes.Unauthenticated {
+		return err
+	}
+
+	refreshedToken, refreshErr := c.freshToken(ctx, token)
+	if refreshErr != nil || refreshedToken == token {
+		return err
+	}
+
+	return invoker(withToken(ctx, refreshedToken), method, req, reply, cc, opts...)
+}
+
+// refreshStreamInterceptor refreshes the access token of streaming calls before it expires.
+// Streams are not retried, as their content can not be replayed.
+func (c *GophKeeperClient) refreshStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if publicMethods[method] {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	token, err := c.freshToken(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return streamer(withToken(ctx, token), desc, cc, method, opts...)
+}
+
+// IsServerAvailable pings server, calling the reconnect hook when the server is back.
 func (c *GophKeeperClient) IsServerAvailable(ctx context.Context, req *emptypb.Empty, preStartHook bool) {
 	if !preStartHook {
 		defer c.wg.Done()
 	}
 
+	wasAvailable := c.ServerAvailable
+
 	resp, err := c.client.Ping(ctx, req)
 	c.ServerAvailable = !(err != nil || resp == nil)
+
+	if !preStartHook && !wasAvailable && c.ServerAvailable && c.onReconnect != nil {
+		c.onReconnect()
+	}
+}
+
+// OnReconnect sets the function called when the ping loop started by Start finds
+// the server available again after it was not.
+func (c *GophKeeperClient) OnReconnect(fn func()) {
+	c.onReconnect = fn
 }
 
 // Start checks is server available for some period
@@ -2,10 +2,20 @@ package client
 
 import (
 	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gophKeeper/client/internal/crypto"
+	"gophKeeper/pkg/payload"
 	"gophKeeper/pkg/proto/gophkeeper"
+	"gophKeeper/pkg/srp"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestGophKeeperClient_CreateData(t *testing.T) {
@@ -387,3 +397,244 @@ func Test_loadTLSCredentials(t *testing.T) {
 		})
 	}
 }
+
+type refreshClientMock struct {
+	gophkeeper.GophKeeperServiceClient
+	refreshCalls int
+}
+
+func (m *refreshClientMock) RefreshToken(_ context.Context, req *gophkeeper.RefreshTokenRequest, _ ...grpc.CallOption) (*gophkeeper.RefreshTokenResponse, error) {
+	m.refreshCalls++
+	if req.GetRefreshToken() != "refresh" {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	return &gophkeeper.RefreshTokenResponse{
+		Token:        "new-token",
+		RefreshToken: "refresh",
+		ExpiresAt:    timestamppb.New(time.Now().Add(time.Hour)),
+	}, nil
+}
+
+func TestGophKeeperClient_refreshUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name             string
+		token            string
+		refreshToken     string
+		expiresAt        time.Time
+		rejectToken      string
+		wantTokens       []string
+		wantRefreshCalls int
+		wantCode         codes.Code
+	}{
+		{
+			name:         "fresh token",
+			token:        "token",
+			refreshToken: "refresh",
+			expiresAt:    time.Now().Add(time.Hour),
+			wantTokens:   []string{"Bearer token"},
+			wantCode:     codes.OK,
+		},
+		{
+			name:             "expiring token is refreshed before the call",
+			token:            "token",
+			refreshToken:     "refresh",
+			expiresAt:        time.Now().Add(time.Second),
+			wantTokens:       []string{"Bearer new-token"},
+			wantRefreshCalls: 1,
+			wantCode:         codes.OK,
+		},
+		{
+			name:             "rejected token is refreshed and the call is retried",
+			token:            "token",
+			refreshToken:     "refresh",
+			expiresAt:        time.Now().Add(time.Hour),
+			rejectToken:      "Bearer token",
+			wantTokens:       []string{"Bearer token", "Bearer new-token"},
+			wantRefreshCalls: 1,
+			wantCode:         codes.OK,
+		},
+		{
+			name:             "rejected token without valid refresh token",
+			token:            "token",
+			refreshToken:     "revoked",
+			expiresAt:        time.Now().Add(time.Hour),
+			rejectToken:      "Bearer token",
+			wantTokens:       []string{"Bearer token"},
+			wantRefreshCalls: 1,
+			wantCode:         codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &refreshClientMock{}
+			c := &GophKeeperClient{
+				client:         mock,
+				BearerToken:    tt.token,
+				refreshToken:   tt.refreshToken,
+				tokenExpiresAt: tt.expiresAt,
+			}
+
+			var gotTokens []string
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				token := md.Get(authorizationMetadataKey)[0]
+				gotTokens = append(gotTokens, token)
+				if token == tt.rejectToken {
+					return status.Error(codes.Unauthenticated, "invalid token")
+				}
+				return nil
+			}
+
+			ctx, cancel := c.CreateContextWithMetadata(time.Second)
+			defer cancel()
+
+			err := c.refreshUnaryInterceptor(ctx, gophkeeper.GophKeeperService_ListData_FullMethodName, nil, nil, nil, invoker)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("refreshUnaryInterceptor() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if !reflect.DeepEqual(gotTokens, tt.wantTokens) {
+				t.Errorf("refreshUnaryInterceptor() sent tokens = %v, want %v", gotTokens, tt.wantTokens)
+			}
+			if mock.refreshCalls != tt.wantRefreshCalls {
+				t.Errorf("refreshUnaryInterceptor() refresh calls = %v, want %v", mock.refreshCalls, tt.wantRefreshCalls)
+			}
+		})
+	}
+}
+
+type srpLoginClientMock struct {
+	gophkeeper.GophKeeperServiceClient
+	passwordLogin bool
+	loginCalls    int
+}
+
+func (m *srpLoginClientMock) LoginSrpStart(_ context.Context, _ *gophkeeper.LoginSrpStartRequest, _ ...grpc.CallOption) (*gophkeeper.LoginSrpStartResponse, error) {
+	if m.passwordLogin {
+		return &gophkeeper.LoginSrpStartResponse{PasswordLogin: true}, nil
+	}
+
+	salt, _ := srp.NewSalt()
+	_, public, _ := srp.NewServerKey(srp.Verifier("password", salt))
+	return &gophkeeper.LoginSrpStartResponse{LoginId: "login", Salt: salt, ServerPublic: public}, nil
+}
+
+func (m *srpLoginClientMock) LoginSrpFinish(_ context.Context, _ *gophkeeper.LoginSrpFinishRequest, _ ...grpc.CallOption) (*gophkeeper.LoginResponse, error) {
+	return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+}
+
+func (m *srpLoginClientMock) Login(_ context.Context, _ *gophkeeper.LoginRequest, _ ...grpc.CallOption) (*gophkeeper.LoginResponse, error) {
+	m.loginCalls++
+	return &gophkeeper.LoginResponse{Token: "token", ExpiresAt: timestamppb.New(time.Now().Add(time.Hour))}, nil
+}
+
+func (m *srpLoginClientMock) SetSrpVerifier(_ context.Context, _ *gophkeeper.SetSrpVerifierRequest, _ ...grpc.CallOption) (*gophkeeper.SetSrpVerifierResponse, error) {
+	return &gophkeeper.SetSrpVerifierResponse{}, nil
+}
+
+func TestGophKeeperClient_LoginSrp(t *testing.T) {
+	tests := []struct {
+		name           string
+		passwordLogin  bool
+		wantCode       codes.Code
+		wantLoginCalls int
+	}{
+		{
+			name:     "rejected proof does not send the password",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:           "account registered with a password logs in with it",
+			passwordLogin:  true,
+			wantCode:       codes.OK,
+			wantLoginCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &srpLoginClientMock{passwordLogin: tt.passwordLogin}
+			c := &GophKeeperClient{client: mock}
+
+			_, err := c.LoginSrp(context.Background(), "bob", "wrong password")
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("LoginSrp() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if mock.loginCalls != tt.wantLoginCalls {
+				t.Errorf("LoginSrp() Login calls = %v, want %v", mock.loginCalls, tt.wantLoginCalls)
+			}
+		})
+	}
+}
+
+type keyCheckClientMock struct {
+	gophkeeper.GophKeeperServiceClient
+	item     *gophkeeper.DataItem
+	keyCheck []byte
+}
+
+func (m *keyCheckClientMock) ListData(_ context.Context, _ *gophkeeper.ListDataRequest, _ ...grpc.CallOption) (*gophkeeper.ListDataResponse, error) {
+	if m.item == nil {
+		return &gophkeeper.ListDataResponse{}, nil
+	}
+	return &gophkeeper.ListDataResponse{Items: []*gophkeeper.DataItemSummary{{Id: m.item.Id, Type: m.item.Type}}}, nil
+}
+
+func (m *keyCheckClientMock) GetData(_ context.Context, _ *gophkeeper.GetDataRequest, _ ...grpc.CallOption) (*gophkeeper.GetDataResponse, error) {
+	return &gophkeeper.GetDataResponse{Data: []*gophkeeper.DataItem{{Id: m.item.Id, Type: m.item.Type, Data: m.item.Data}}}, nil
+}
+
+func (m *keyCheckClientMock) SetKeyCheck(_ context.Context, req *gophkeeper.SetKeyCheckRequest, _ ...grpc.CallOption) (*gophkeeper.SetKeyCheckResponse, error) {
+	if m.keyCheck != nil {
+		return nil, status.Error(codes.FailedPrecondition, "key check is already set")
+	}
+	m.keyCheck = req.GetKeyCheck()
+	return &gophkeeper.SetKeyCheckResponse{}, nil
+}
+
+func TestGophKeeperClient_CheckMasterKey(t *testing.T) {
+	rightKey, _ := crypto.New(make([]byte, 32))
+	wrongKey, _ := crypto.New(append(make([]byte, 31), 1))
+
+	keyCheck, _ := rightKey.Seal([]byte(keyCheckValue), keyCheckAdditionalData)
+	content, _ := rightKey.Seal([]byte("content"), []byte("item-1"))
+	item := &gophkeeper.DataItem{Id: "item-1", Type: payload.BinaryType, Data: content}
+
+	tests := []struct {
+		name         string
+		cipher       *crypto.Cipher
+		keyCheck     []byte
+		item         *gophkeeper.DataItem
+		wantErr      error
+		wantKeyCheck bool
+	}{
+		{name: "key check opened", cipher: rightKey, keyCheck: keyCheck},
+		{name: "key check not opened", cipher: wrongKey, keyCheck: keyCheck, wantErr: ErrWrongMasterKey},
+		{name: "first login stores the key check", cipher: rightKey, wantKeyCheck: true},
+		{name: "item opened stores the key check", cipher: rightKey, item: item, wantKeyCheck: true},
+		{name: "item not opened", cipher: wrongKey, item: item, wantErr: ErrWrongMasterKey},
+		{name: "locked", keyCheck: keyCheck, wantErr: ErrLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &keyCheckClientMock{item: tt.item}
+			c := &GophKeeperClient{client: mock, cipher: tt.cipher}
+
+			if err := c.CheckMasterKey(context.Background(), tt.keyCheck); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckMasterKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if (mock.keyCheck != nil) != tt.wantKeyCheck {
+				t.Fatalf("CheckMasterKey() stored key check = %v, want %v", mock.keyCheck != nil, tt.wantKeyCheck)
+			}
+			if tt.wantKeyCheck {
+				// the stored key check verifies the same key on the next login, and no other key
+				if err := c.CheckMasterKey(context.Background(), mock.keyCheck); err != nil {
+					t.Errorf("CheckMasterKey() of the stored key check error = %v", err)
+				}
+				c.cipher = wrongKey
+				if err := c.CheckMasterKey(context.Background(), mock.keyCheck); !errors.Is(err, ErrWrongMasterKey) {
+					t.Errorf("CheckMasterKey() of the stored key check with another key error = %v, want %v", err, ErrWrongMasterKey)
+				}
+			}
+		})
+	}
+}
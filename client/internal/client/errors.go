@@ -0,0 +1,83 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gophKeeper/pkg/payload"
+	"strings"
+	"time"
+)
+
+// codeMessages describes the gRPC status codes returned by the server in user terms.
+var codeMessages = map[codes.Code]string{
+	codes.InvalidArgument:   "Invalid input",
+	codes.NotFound:          "Not found",
+	codes.AlreadyExists:     "Already exists",
+	codes.Unauthenticated:   "Not authenticated",
+	codes.PermissionDenied:  "Permission denied",
+	codes.ResourceExhausted: "Too many attempts",
+	codes.Unavailable:       "Server is not available",
+	codes.DeadlineExceeded:  "Server did not respond in time",
+	codes.Internal:          "Server error",
+}
+
+// ErrorMessage returns a human-readable description of an error returned by the client.
+// Errors received from the server are described by their status message and the delay
+// to retry after, followed by every invalid field reported in the error details.
+func ErrorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var validationErr *payload.ValidationError
+	if errors.As(err, &validationErr) {
+		return formatViolations("Invalid input", validationErr.Violations)
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err.Error()
+	}
+
+	var (
+		violations []payload.FieldViolation
+		retryDelay time.Duration
+	)
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				violations = append(violations, payload.FieldViolation{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		case *errdetails.RetryInfo:
+			retryDelay = d.GetRetryDelay().AsDuration()
+		}
+	}
+
+	title, ok := codeMessages[st.Code()]
+	if !ok {
+		title = st.Code().String()
+	}
+	if st.Message() != "" && st.Code() != codes.Unavailable {
+		title = fmt.Sprintf("%s: %s", title, st.Message())
+	}
+	if retryDelay > 0 {
+		title = fmt.Sprintf("%s (retry in %s)", title, retryDelay.Round(time.Second))
+	}
+
+	return formatViolations(title, violations)
+}
+
+// formatViolations returns the title followed by one line per field violation.
+func formatViolations(title string, violations []payload.FieldViolation) string {
+	var builder strings.Builder
+	builder.WriteString(title)
+	for _, v := range violations {
+		builder.WriteString(fmt.Sprintf("\n- %s: %s", v.Field, v.Description))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"gophKeeper/pkg/payload"
+	"testing"
+	"time"
+)
+
+func TestErrorMessage(t *testing.T) {
+	badRequest, err := status.New(codes.InvalidArgument, "invalid request fields").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "username", Description: "must not be empty"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locked, err := status.New(codes.ResourceExhausted, "account is temporarily locked").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(90 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "status with field violations",
+			err:  badRequest.Err(),
+			want: "Invalid input: invalid request fields\n- username: must not be empty",
+		},
+		{
+			name: "status with retry info",
+			err:  locked.Err(),
+			want: "Too many attempts: account is temporarily locked (retry in 1m30s)",
+		},
+		{
+			name: "not found status",
+			err:  status.Error(codes.NotFound, "data item not found"),
+			want: "Not found: data item not found",
+		},
+		{
+			name: "unavailable status",
+			err:  status.Error(codes.Unavailable, "connection refused"),
+			want: "Server is not available",
+		},
+		{
+			name: "client-side validation error",
+			err: &payload.ValidationError{Violations: []payload.FieldViolation{
+				{Field: "bank_card.cvv", Description: "must be 3 or 4 digits"},
+			}},
+			want: "Invalid input\n- bank_card.cvv: must be 3 or 4 digits",
+		},
+		{
+			name: "plain error",
+			err:  errors.New("vault is locked"),
+			want: "vault is locked",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorMessage(tt.err); got != tt.want {
+				t.Errorf("ErrorMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,25 +5,31 @@ package conf
 import (
 	"flag"
 	"github.com/caarlos0/env/v9"
+	"time"
 )
 
 // Conf holds the configuration settings for the client, including the gRPC server address,
-// paths to the CA and client certificates, and the option to enable TLS.
+// paths to the CA and client certificates, the option to enable TLS and the paths
+// of the local vault and of the sessions of the command line client and of the TUI,
+// which default to the configuration directory of the user, and the idle timeout after which
+// the TUI locks, zero to never lock it.
 var Conf = struct {
-	ServerAddress  string `env:"server_address"`
-	RedisAddress   string `env:"redis_address" envDefault:"localhost:6379"`
-	RedisPassword  string `env:"redis_password" envDefault:"password"`
-	RedisDB        int    `env:"redis_db" envDefault:"0"`
-	CAFile         string `env:"CA_FILE" envDefault:"cert/ca-cert.pem"`
-	ClientCertFile string `env:"client_cert_file" envDefault:"cert/client-cert.pem"`
-	ClientKeyFile  string `env:"client_key_file" envDefault:"cert/client-key.pem"`
-	EnableTLS      bool   `env:"ENABLE_TLS" envDefault:"true"`
+	ServerAddress  string        `env:"server_address"`
+	VaultFile      string        `env:"vault_file"`
+	SessionFile    string        `env:"session_file"`
+	TUISessionFile string        `env:"tui_session_file"`
+	CAFile         string        `env:"CA_FILE" envDefault:"cert/ca-cert.pem"`
+	ClientCertFile string        `env:"client_cert_file" envDefault:"cert/client-cert.pem"`
+	ClientKeyFile  string        `env:"client_key_file" envDefault:"cert/client-key.pem"`
+	EnableTLS      bool          `env:"ENABLE_TLS" envDefault:"true"`
+	IdleTimeout    time.Duration `env:"idle_timeout" envDefault:"5m"`
 }{}
 
 // init initializes the configuration by parsing command-line flags and environment variables.
 // It sets default values for fields like the gRPC server address and certificate paths.
 func init() {
 	flag.StringVar(&Conf.ServerAddress, "a", "localhost:5050", "address and port where grpc server start")
+	flag.StringVar(&Conf.VaultFile, "v", "", "path of the local vault file")
 
 	if err := env.Parse(&Conf); err != nil {
 		panic(err)
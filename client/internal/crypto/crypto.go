@@ -0,0 +1,125 @@
+// Package crypto implements the client-side encryption layer of the GophKeeper client.
+// A master key is derived from the user's passphrase with Argon2id, and data item payloads
+// are sealed with AES-256-GCM before they leave the client, so the server only stores ciphertext.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// KeySize is the size of the master key in bytes.
+	KeySize = 32
+
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+
+	formatVersion byte = 1
+)
+
+var (
+	// ErrInvalidKey is returned when the key has an unexpected size.
+	ErrInvalidKey = errors.New("invalid key size")
+	// ErrInvalidCiphertext is returned when the ciphertext is malformed or was produced by an unknown format.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	// ErrDecrypt is returned when the ciphertext can not be authenticated, e.g. because of a wrong key.
+	ErrDecrypt = errors.New("failed to decrypt data")
+)
+
+// Cipher seals and opens data item payloads with an AEAD keyed by the master key.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// DeriveKey derives the master key from the passphrase and the user's salt using Argon2id.
+func DeriveKey(passphrase string, salt []byte) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, argon2Time, argon2Memory, argon2Threads, KeySize)
+}
+
+// New creates a new Cipher for the given master key.
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// NewFromPassphrase derives the master key from the passphrase and salt and creates a new Cipher.
+func NewFromPassphrase(passphrase string, salt []byte) (*Cipher, error) {
+	return New(DeriveKey(passphrase, salt))
+}
+
+// Seal encrypts and authenticates the plaintext, binding it to the additional data.
+// The result is formatted as version || nonce || ciphertext.
+func (c *Cipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, 1+len(nonce)+len(plaintext)+c.aead.Overhead())
+	out = append(out, formatVersion)
+	out = append(out, nonce...)
+
+	return c.aead.Seal(out, nonce, plaintext, additionalData), nil
+}
+
+// Open authenticates and decrypts a ciphertext produced by Seal with the same additional data.
+func (c *Cipher) Open(ciphertext, additionalData []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < 1+nonceSize+c.aead.Overhead() || ciphertext[0] != formatVersion {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce := ciphertext[1 : 1+nonceSize]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext[1+nonceSize:], additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
+
+// SealString encrypts a string and returns the ciphertext encoded in base64,
+// so it can be stored in text fields.
+func (c *Cipher) SealString(plaintext string, additionalData []byte) (string, error) {
+	ciphertext, err := c.Seal([]byte(plaintext), additionalData)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// OpenString decodes a base64 ciphertext produced by SealString and decrypts it.
+func (c *Cipher) OpenString(ciphertext string, additionalData []byte) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	plaintext, err := c.Open(raw, additionalData)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,119 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCipher_SealOpen(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+
+	c, err := NewFromPassphrase("correct horse battery staple", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewFromPassphrase("wrong passphrase", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("my secret")
+	ciphertext, err := c.Seal(plaintext, []byte("item-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name           string
+		cipher         *Cipher
+		ciphertext     []byte
+		additionalData []byte
+		want           []byte
+		wantErr        error
+	}{
+		{
+			name:           "valid ciphertext",
+			cipher:         c,
+			ciphertext:     ciphertext,
+			additionalData: []byte("item-1"),
+			want:           plaintext,
+		},
+		{
+			name:           "wrong key",
+			cipher:         other,
+			ciphertext:     ciphertext,
+			additionalData: []byte("item-1"),
+			wantErr:        ErrDecrypt,
+		},
+		{
+			name:           "wrong additional data",
+			cipher:         c,
+			ciphertext:     ciphertext,
+			additionalData: []byte("item-2"),
+			wantErr:        ErrDecrypt,
+		},
+		{
+			name:           "tampered ciphertext",
+			cipher:         c,
+			ciphertext:     tampered,
+			additionalData: []byte("item-1"),
+			wantErr:        ErrDecrypt,
+		},
+		{
+			name:           "plaintext instead of ciphertext",
+			cipher:         c,
+			ciphertext:     plaintext,
+			additionalData: []byte("item-1"),
+			wantErr:        ErrInvalidCiphertext,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cipher.Open(tt.ciphertext, tt.additionalData)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Open() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCipher_SealString(t *testing.T) {
+	c, err := New(bytes.Repeat([]byte{1}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := c.SealString("meta", []byte("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.SealString("meta", []byte("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("SealString() produced the same ciphertext twice")
+	}
+
+	got, err := c.OpenString(first, []byte("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "meta" {
+		t.Errorf("OpenString() got = %v, want %v", got, "meta")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New([]byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("New() error = %v, want %v", err, ErrInvalidKey)
+	}
+}
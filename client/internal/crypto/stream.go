@@ -0,0 +1,187 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+)
+
+// Streams are encrypted in segments, so large files never have to be held in memory.
+// The stream starts with a header (version || nonce prefix), followed by segments
+// formatted as length || sealed chunk. The nonce of each segment is built from the prefix,
+// the segment counter and a flag marking the last segment, which protects the stream
+// against reordering and truncation.
+const (
+	// StreamChunkSize is the size of the plaintext chunk sealed in a single segment.
+	StreamChunkSize = 64 * 1024
+
+	streamFormatVersion byte = 2
+	noncePrefixSize          = 7
+	streamHeaderSize         = 1 + noncePrefixSize
+	segmentLengthSize        = 4
+)
+
+// IsStream reports whether the ciphertext was produced by a stream encryption.
+func IsStream(ciphertext []byte) bool {
+	return len(ciphertext) >= streamHeaderSize && ciphertext[0] == streamFormatVersion
+}
+
+// EncryptedSize returns the size of the encrypted stream for a plaintext of the given size.
+func (c *Cipher) EncryptedSize(size int64) int64 {
+	segments := size/StreamChunkSize + 1
+	return streamHeaderSize + segments*int64(segmentLengthSize+c.aead.Overhead()) + size
+}
+
+// NewEncryptReader returns a reader producing the encrypted stream of the plaintext read from src,
+// binding every segment to the additional data.
+func (c *Cipher) NewEncryptReader(src io.Reader, additionalData []byte) (io.Reader, error) {
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, err
+	}
+
+	r := &encryptReader{
+		cipher:         c,
+		src:            src,
+		additionalData: additionalData,
+		prefix:         prefix,
+		plain:          make([]byte, StreamChunkSize),
+	}
+	r.buf.WriteByte(streamFormatVersion)
+	r.buf.Write(prefix)
+
+	return r, nil
+}
+
+// NewDecryptReader returns a reader producing the plaintext of the encrypted stream read from src.
+// Reading fails with ErrDecrypt if the stream was tampered with or truncated.
+func (c *Cipher) NewDecryptReader(src io.Reader, additionalData []byte) (io.Reader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	if header[0] != streamFormatVersion {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return &decryptReader{
+		cipher:         c,
+		src:            src,
+		additionalData: additionalData,
+		prefix:         header[1:],
+	}, nil
+}
+
+// segmentNonce builds the nonce of the segment from the stream prefix, the counter and the last flag.
+func (c *Cipher) segmentNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, c.aead.NonceSize())
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+type encryptReader struct {
+	cipher         *Cipher
+	src            io.Reader
+	additionalData []byte
+	prefix         []byte
+	counter        uint32
+	plain          []byte
+	buf            bytes.Buffer
+	done           bool
+}
+
+// Read returns the next bytes of the encrypted stream, sealing a new segment when needed.
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	return r.buf.Read(p)
+}
+
+// sealSegment reads the next plaintext chunk and writes the sealed segment to the buffer.
+func (r *encryptReader) sealSegment() error {
+	n, err := io.ReadFull(r.src, r.plain)
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		r.done = true
+	case err != nil:
+		return err
+	}
+
+	sealed := r.cipher.aead.Seal(nil, r.cipher.segmentNonce(r.prefix, r.counter, r.done), r.plain[:n], r.additionalData)
+	r.counter++
+
+	length := make([]byte, segmentLengthSize)
+	binary.BigEndian.PutUint32(length, uint32(len(sealed)))
+	r.buf.Write(length)
+	r.buf.Write(sealed)
+
+	return nil
+}
+
+type decryptReader struct {
+	cipher         *Cipher
+	src            io.Reader
+	additionalData []byte
+	prefix         []byte
+	counter        uint32
+	buf            bytes.Buffer
+	done           bool
+}
+
+// Read returns the next bytes of the plaintext, opening a new segment when needed.
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.openSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	return r.buf.Read(p)
+}
+
+// openSegment reads the next sealed segment and writes its plaintext to the buffer.
+func (r *decryptReader) openSegment() error {
+	length := make([]byte, segmentLengthSize)
+	if _, err := io.ReadFull(r.src, length); err != nil {
+		// the stream ended before the last segment
+		return ErrDecrypt
+	}
+
+	size := binary.BigEndian.Uint32(length)
+	if size > StreamChunkSize+uint32(r.cipher.aead.Overhead()) {
+		return ErrInvalidCiphertext
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.src, sealed); err != nil {
+		return ErrDecrypt
+	}
+
+	plain, err := r.cipher.aead.Open(nil, r.cipher.segmentNonce(r.prefix, r.counter, false), sealed, r.additionalData)
+	if err != nil {
+		plain, err = r.cipher.aead.Open(nil, r.cipher.segmentNonce(r.prefix, r.counter, true), sealed, r.additionalData)
+		if err != nil {
+			return ErrDecrypt
+		}
+		r.done = true
+	}
+	r.counter++
+	r.buf.Write(plain)
+
+	return nil
+}
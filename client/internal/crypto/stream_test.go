@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCipher_Stream(t *testing.T) {
+	c, err := New(bytes.Repeat([]byte{7}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty", size: 0},
+		{name: "smaller than chunk", size: 100},
+		{name: "exactly one chunk", size: StreamChunkSize},
+		{name: "several chunks", size: 3*StreamChunkSize + 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext := bytes.Repeat([]byte{'a'}, tt.size)
+
+			encReader, err := c.NewEncryptReader(bytes.NewReader(plaintext), []byte("id"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ciphertext, err := io.ReadAll(encReader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(ciphertext)) != c.EncryptedSize(int64(tt.size)) {
+				t.Errorf("EncryptedSize() = %v, want %v", c.EncryptedSize(int64(tt.size)), len(ciphertext))
+			}
+			if !IsStream(ciphertext) {
+				t.Errorf("IsStream() = false, want true")
+			}
+
+			decReader, err := c.NewDecryptReader(bytes.NewReader(ciphertext), []byte("id"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(decReader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("decrypted stream differs from plaintext")
+			}
+
+			truncated := ciphertext[:len(ciphertext)-1]
+			decReader, err = c.NewDecryptReader(bytes.NewReader(truncated), []byte("id"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = io.ReadAll(decReader); !errors.Is(err, ErrDecrypt) {
+				t.Errorf("truncated stream error = %v, want %v", err, ErrDecrypt)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Package session persists the login of the client between its runs, a file in the configuration
+// directory of the user readable by its owner only. The token pair is sealed with the master key,
+// so the file alone does not give access to the account, and the session is resumed with
+// the master password instead of a new login.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gophKeeper/client/internal/client"
+	"gophKeeper/client/internal/crypto"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMode restricts the session file to its owner.
+const fileMode = 0o600
+
+var (
+	// ErrNoSession is returned by Load when nobody is logged in.
+	ErrNoSession = errors.New("not logged in")
+	// ErrWrongKey is returned when the token pair is opened with another master key.
+	ErrWrongKey = errors.New("wrong master password")
+)
+
+// Session is the login kept between the runs of the client. The master password is not kept,
+// only the kdf salt the master key is derived with.
+type Session struct {
+	ServerAddress string `json:"server_address"`
+	Username      string `json:"username"`
+	KdfSalt       []byte `json:"kdf_salt"`
+	// SealedTokens holds the token pair sealed with the master key.
+	SealedTokens []byte `json:"sealed_tokens"`
+}
+
+// sealedTokens is the sealed form of client.Tokens.
+type sealedTokens struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// New returns the session of the account with the token pair sealed with the master key.
+func New(serverAddress, username string, kdfSalt []byte, tokens client.Tokens, cipher *crypto.Cipher) (*Session, error) {
+	s := &Session{
+		ServerAddress: serverAddress,
+		Username:      username,
+		KdfSalt:       kdfSalt,
+	}
+	if err := s.SetTokens(tokens, cipher); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SetTokens replaces the token pair of the session, sealing it with the master key.
+func (s *Session) SetTokens(tokens client.Tokens, cipher *crypto.Cipher) error {
+	plaintext, err := json.Marshal(sealedTokens(tokens))
+	if err != nil {
+		return err
+	}
+
+	s.SealedTokens, err = cipher.Seal(plaintext, s.additionalData())
+	if err != nil {
+		return fmt.Errorf("encrypt session: %w", err)
+	}
+
+	return nil
+}
+
+// Tokens opens the token pair of the session with the master key, ErrWrongKey for another key.
+func (s *Session) Tokens(cipher *crypto.Cipher) (client.Tokens, error) {
+	plaintext, err := cipher.Open(s.SealedTokens, s.additionalData())
+	if err != nil {
+		return client.Tokens{}, ErrWrongKey
+	}
+
+	var value sealedTokens
+	if err = json.Unmarshal(plaintext, &value); err != nil {
+		return client.Tokens{}, fmt.Errorf("parse session: %w", err)
+	}
+
+	return client.Tokens(value), nil
+}
+
+// additionalData binds the sealed token pair to the server and the account.
+func (s *Session) additionalData() []byte {
+	return []byte("session:" + s.ServerAddress + ":" + s.Username)
+}
+
+// DefaultPath returns the path of the named session file in the configuration directory of the user.
+// The command line client and the TUI keep sessions of their own, as the server rotates
+// the refresh token of a session on every refresh.
+func DefaultPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "gophkeeper", name+"-session.json")
+}
+
+// Load reads the session from the file at the path, ErrNoSession if there is none.
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSession
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read session %s: %w", path, err)
+	}
+
+	s := &Session{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parse session %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Save writes the session to the file at the path. The file is replaced atomically,
+// so a concurrent run never reads a partially written session.
+func Save(path string, s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create session directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".session-*")
+	if err != nil {
+		return fmt.Errorf("create session file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = tmp.Chmod(fileMode); err == nil {
+		_, err = tmp.Write(data)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write session file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Remove deletes the session file, removing a missing file is not an error.
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"bytes"
+	"errors"
+	"gophKeeper/client/internal/client"
+	"gophKeeper/client/internal/crypto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophkeeper", "session.json")
+
+	if _, err := Load(path); !errors.Is(err, ErrNoSession) {
+		t.Fatalf("Load() of a missing session error = %v, want %v", err, ErrNoSession)
+	}
+
+	cipher, err := crypto.NewFromPassphrase("master", []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := client.Tokens{
+		AccessToken:  "access-token",
+		RefreshToken: "refresh-token",
+		ExpiresAt:    time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC),
+	}
+	want, err := New("localhost:5050", "alice", []byte("0123456789abcdef"), tokens, cipher)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err = Save(path, want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(tokens.AccessToken)) || bytes.Contains(data, []byte(tokens.RefreshToken)) {
+		t.Errorf("session file holds the tokens in plaintext: %s", data)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != fileMode {
+			t.Errorf("session file mode = %o, want %o", mode, fileMode)
+		}
+	}
+
+	if err = Remove(path); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err = Remove(path); err != nil {
+		t.Errorf("Remove() of a missing session error = %v", err)
+	}
+	if _, err = Load(path); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Load() after Remove() error = %v, want %v", err, ErrNoSession)
+	}
+}
+
+func TestSession_Tokens(t *testing.T) {
+	cipher, err := crypto.NewFromPassphrase("master", []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCipher, err := crypto.NewFromPassphrase("other", []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := client.Tokens{
+		AccessToken:  "access-token",
+		RefreshToken: "refresh-token",
+		ExpiresAt:    time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC),
+	}
+	s, err := New("localhost:5050", "alice", []byte("0123456789abcdef"), tokens, cipher)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got, err := s.Tokens(cipher)
+	if err != nil {
+		t.Fatalf("Tokens() error = %v", err)
+	}
+	if got.AccessToken != tokens.AccessToken || got.RefreshToken != tokens.RefreshToken || !got.ExpiresAt.Equal(tokens.ExpiresAt) {
+		t.Errorf("Tokens() = %+v, want %+v", got, tokens)
+	}
+
+	if _, err = s.Tokens(otherCipher); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Tokens() with another key error = %v, want %v", err, ErrWrongKey)
+	}
+
+	s.Username = "bob"
+	if _, err = s.Tokens(cipher); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Tokens() of another account error = %v, want %v", err, ErrWrongKey)
+	}
+
+	s.Username = "alice"
+	tokens.AccessToken = "refreshed-token"
+	if err = s.SetTokens(tokens, cipher); err != nil {
+		t.Fatalf("SetTokens() error = %v", err)
+	}
+	if got, err = s.Tokens(cipher); err != nil || got.AccessToken != tokens.AccessToken {
+		t.Errorf("Tokens() after SetTokens() = %+v, %v, want %+v", got, err, tokens)
+	}
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// deleteAccountConfirmation is the text typed to confirm the deletion of the account.
+const deleteAccountConfirmation = "DELETE"
+
+// account displays the options to change the password or the username and to delete the account.
+func (t *TUI) account() {
+	if !t.client.ServerAvailable {
+		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	list := tview.NewList().
+		AddItem("Change password", "Replace the login password, other sessions are logged out", 'p', t.changePassword).
+		AddItem("Change username", "Rename the account", 'u', t.changeUsername).
+		AddItem("Delete account", "Delete the account with all its data", 'd', t.deleteAccount).
+		AddItem("Back", "Return to the main menu", 'b', t.showMainMenu)
+
+	t.app.SetRoot(list, true).SetFocus(list)
+}
+
+// changePassword displays the form replacing the login password, checking the new one
+// against the password policy before it is sent.
+func (t *TUI) changePassword() {
+	form := tview.NewForm()
+	form.
+		AddPasswordField("Current password", "", 20, '*', nil).
+		AddPasswordField("New password", "", 20, '*', nil).
+		AddPasswordField("Repeat new password", "", 20, '*', nil).
+		AddButton("Change", func() {
+			oldPassword := form.GetFormItemByLabel("Current password").(*tview.InputField).GetText()
+			newPassword := form.GetFormItemByLabel("New password").(*tview.InputField).GetText()
+			repeated := form.GetFormItemByLabel("Repeat new password").(*tview.InputField).GetText()
+
+			if newPassword != repeated {
+				t.showMessage("The new passwords do not match. Press Enter to go back.", t.changePassword)
+				return
+			}
+			if problem := t.checkNewPassword(newPassword, t.client.Username()); problem != "" {
+				t.showMessage(fmt.Sprintf("Failed to change the password.\n%s\nPress Enter to go back.", problem), t.changePassword)
+				return
+			}
+
+			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+			defer cancel()
+
+			_, err := t.client.ChangePassword(ctx, oldPassword, newPassword)
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to change the password.\n%s\nPress Enter to go back.", errorDetails(err)), t.account)
+				return
+			}
+
+			t.showMessage("Password changed, other sessions are logged out. Press Enter to go back.", t.showMainMenu)
+		}).
+		AddButton("Cancel", func() {
+			t.account()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// changeUsername displays the form renaming the account. The session file is sealed anew,
+// as its token pair is bound to the username.
+func (t *TUI) changeUsername() {
+	form := tview.NewForm()
+	form.
+		AddInputField("New username", "", 20, nil, nil).
+		AddButton("Change", func() {
+			username := form.GetFormItemByLabel("New username").(*tview.InputField).GetText()
+
+			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+			defer cancel()
+
+			_, err := t.client.ChangeUsername(ctx, username)
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to change the username.\n%s\nPress Enter to go back.", errorDetails(err)), t.account)
+				return
+			}
+
+			t.sessionMu.Lock()
+			s := t.session
+			t.sessionMu.Unlock()
+			if s != nil {
+				t.saveSession(s.KdfSalt)
+			}
+
+			t.showMessage(fmt.Sprintf("Username changed to %s. Press Enter to go back.", username), t.showMainMenu)
+		}).
+		AddButton("Cancel", func() {
+			t.account()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// deleteAccount displays the form deleting the account, which asks for the password
+// and for the confirmation typed out, as the data can not be restored afterwards.
+func (t *TUI) deleteAccount() {
+	form := tview.NewForm()
+	form.
+		AddPasswordField("Password", "", 20, '*', nil).
+		AddInputField(fmt.Sprintf("Type %s to confirm", deleteAccountConfirmation), "", 20, nil, nil).
+		AddButton("Delete", func() {
+			password := form.GetFormItemByLabel("Password").(*tview.InputField).GetText()
+			confirmation := form.GetFormItemByLabel(fmt.Sprintf("Type %s to confirm", deleteAccountConfirmation)).(*tview.InputField).GetText()
+
+			if confirmation != deleteAccountConfirmation {
+				t.showMessage("The account was not deleted. Press Enter to go back.", t.account)
+				return
+			}
+
+			ctx, cancel := t.client.CreateContextWithMetadata(30 * time.Second)
+			defer cancel()
+
+			_, err := t.client.DeleteAccount(ctx, password)
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to delete the account.\n%s\nPress Enter to go back.", errorDetails(err)), t.account)
+				return
+			}
+
+			t.vault.Lock()
+			t.forgetSession()
+
+			t.showMessage("Account deleted. Press Enter to continue.", t.restart)
+		}).
+		AddButton("Cancel", func() {
+			t.account()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
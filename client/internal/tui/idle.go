@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// watchIdle locks the client once the user has not pressed a key or used the mouse for the idle
+// timeout, replacing the shown view, which may hold decrypted secrets, with the unlock form.
+// The capture functions set on the application before are kept and called after the timer is reset.
+// A timeout of zero never locks the client.
+func (t *TUI) watchIdle() {
+	if t.idleTimeout <= 0 {
+		return
+	}
+
+	// a timer which has fired is started again, so the client locks after the next idle period
+	idleTimer := time.AfterFunc(t.idleTimeout, t.onIdle)
+
+	inputCapture := t.app.GetInputCapture()
+	t.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		idleTimer.Reset(t.idleTimeout)
+		if inputCapture != nil {
+			return inputCapture(event)
+		}
+		return event
+	})
+
+	mouseCapture := t.app.GetMouseCapture()
+	t.app.SetMouseCapture(func(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+		idleTimer.Reset(t.idleTimeout)
+		if mouseCapture != nil {
+			return mouseCapture(event, action)
+		}
+		return event, action
+	})
+}
+
+// onIdle locks the client if it is unlocked when the idle timeout passes.
+func (t *TUI) onIdle() {
+	t.app.QueueUpdateDraw(func() {
+		if t.client.Cipher() == nil {
+			// nobody is logged in or the client is locked already
+			return
+		}
+
+		t.lock()
+	})
+}
@@ -0,0 +1,381 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gophKeeper/client/internal/vault"
+	proto "gophKeeper/pkg/proto/gophkeeper"
+)
+
+// errNotReplayed is returned when the changes made offline can not be sent,
+// because the client is not logged in on the server.
+var errNotReplayed = errors.New("log in to send the changes made offline")
+
+// onReconnect is called by the ping loop of the client when the server is available again.
+// It sends the changes made offline, asking the user to log in first if the session
+// was opened offline, and to resolve the conflicts with the server if there are any.
+func (t *TUI) onReconnect() {
+	entries, err := t.vault.Journal()
+	if err != nil || len(entries) == 0 {
+		// the vault is locked until somebody logs in, nothing to send yet
+		return
+	}
+
+	if t.client.Username() == "" {
+		t.app.QueueUpdateDraw(func() {
+			t.showMessage("Server is available again. Log in to send the changes made offline. Press Enter to log in.", t.login)
+		})
+		return
+	}
+
+	applied, conflicts, err := t.replayJournal()
+	if err != nil {
+		log.Printf("Failed to send the changes made offline: %v", err)
+		return
+	}
+	log.Printf("Sent %d changes made offline, %d conflict with the server", applied, conflicts)
+
+	if conflicts > 0 {
+		t.app.QueueUpdateDraw(func() {
+			t.showMessage(fmt.Sprintf("Server is available again. Changes made offline sent: %d, conflicts: %d. Press Enter to resolve the conflicts.", applied, conflicts), t.offlineChanges)
+		})
+	}
+}
+
+// recordOffline stores the change made while the server is not available in the local vault,
+// keeping the local copy of the item up to date and the change in the journal until it is sent.
+func (t *TUI) recordOffline(op vault.Operation, item *proto.DataItem, baseVersion int32) error {
+	if err := t.vault.Record(op, item, baseVersion); err != nil {
+		return err
+	}
+
+	if op == vault.OpDelete {
+		t.removeLocally(item.Id)
+	} else {
+		t.storeLocally(item)
+	}
+
+	return nil
+}
+
+// replayJournal sends the changes made offline to the server, see syncWithServer.
+func (t *TUI) replayJournal() (applied, conflicts int, err error) {
+	_, applied, conflicts, err = t.syncWithServer()
+	return applied, conflicts, err
+}
+
+// syncWithServer sends the changes made offline to the server and synchronizes the local vault with it.
+// The deletions are sent one by one, unless the item changed on the server since. The created and updated
+// items are sent in a single synchronization, which applies the changes based on the current server versions
+// and reports the others as conflicts. The conflicting entries are kept for the user to resolve, the others
+// are removed from the journal. The items changed on the server since the last synchronization and the applied
+// changes with their new versions are stored in the local vault, the deleted ones are dropped from it.
+// It returns the response of the synchronization, the number of changes sent and the number of conflicts.
+func (t *TUI) syncWithServer() (resp *proto.SyncDataResponse, applied, conflicts int, err error) {
+	t.replayMu.Lock()
+	defer t.replayMu.Unlock()
+
+	if t.client.Username() == "" {
+		return nil, 0, 0, errNotReplayed
+	}
+
+	entries, err := t.vault.Journal()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	req := &proto.SyncDataRequest{}
+	pending := make(map[string]*vault.JournalEntry, len(entries))
+	for _, entry := range entries {
+		switch {
+		case entry.Conflicted:
+			conflicts++
+		case entry.Op == vault.OpDelete:
+			conflict, server, err := t.applyDeletion(entry)
+			if err != nil {
+				return nil, applied, conflicts, fmt.Errorf("%s %s: %w", entry.Op, entry.Item.Id, err)
+			}
+
+			if conflict {
+				err = t.vault.MarkConflict(entry.Seq, server)
+				conflicts++
+			} else {
+				err = t.vault.Ack(entry.Seq)
+				applied++
+			}
+			if err != nil {
+				return nil, applied, conflicts, err
+			}
+		default:
+			// the version of a synchronized item is the server version the change is based on
+			entry.Item.Version = entry.BaseVersion
+			req.Data = append(req.Data, entry.Item)
+			pending[entry.Item.Id] = entry
+		}
+	}
+
+	if lastSyncedAt, err := t.vault.LastSyncedAt(); err != nil {
+		log.Printf("Failed to read the last sync time from the local vault: %v", err)
+	} else if !lastSyncedAt.IsZero() {
+		req.LastSyncedAt = timestamppb.New(lastSyncedAt)
+	}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err = t.client.SyncData(ctx, req)
+	if err != nil {
+		return nil, applied, conflicts, err
+	}
+
+	for _, conflict := range resp.Conflicts {
+		entry, ok := pending[conflict.Client.GetId()]
+		if !ok {
+			continue
+		}
+		delete(pending, entry.Item.Id)
+
+		server := conflict.Server
+		if server.GetDeletedAt() != nil {
+			// the item was moved to the trash on the server, keeping the local change restores it
+			server = nil
+		}
+		if err = t.vault.MarkConflict(entry.Seq, server); err != nil {
+			return nil, applied, conflicts, err
+		}
+		conflicts++
+	}
+	for _, entry := range pending {
+		if err = t.vault.Ack(entry.Seq); err != nil {
+			return nil, applied, conflicts, err
+		}
+		applied++
+	}
+
+	for _, item := range resp.Data {
+		if item.DeletedAt != nil {
+			// the item was deleted on the server, its tombstone removes the local copy
+			t.removeLocally(item.Id)
+		} else {
+			t.storeLocally(item)
+		}
+	}
+
+	if err = t.vault.SetLastSyncedAt(resp.SyncedAt.AsTime()); err != nil {
+		log.Printf("Failed to store the last sync time in the local vault: %v", err)
+	}
+
+	return resp, applied, conflicts, nil
+}
+
+// applyDeletion sends the deletion made offline to the server unless the item changed there since,
+// returning whether the deletion conflicts with the server and the server copy of the item.
+func (t *TUI) applyDeletion(entry *vault.JournalEntry) (bool, *proto.DataItem, error) {
+	server, found, err := t.fetchServerItem(entry.Item.Id, entry.Item.Type)
+	if err != nil {
+		return false, nil, err
+	}
+	if !found {
+		// the item is already deleted on the server
+		return false, nil, nil
+	}
+	if server.Version != entry.BaseVersion {
+		return true, server, nil
+	}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	_, err = t.client.DeleteData(ctx, &proto.DeleteDataRequest{Id: entry.Item.Id})
+	return false, nil, err
+}
+
+// fetchServerItem returns the server copy of the item, and whether the item exists outside the trash.
+func (t *TUI) fetchServerItem(id, dataType string) (*proto.DataItem, bool, error) {
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.GetData(ctx, &proto.GetDataRequest{Id: id, Type: dataType})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, false, nil
+	}
+
+	return resp.Data[0], true, nil
+}
+
+// refreshLocally replaces the local copy of the item with the server one, logging the failure.
+func (t *TUI) refreshLocally(id, dataType string) {
+	item, found, err := t.fetchServerItem(id, dataType)
+	if err != nil {
+		log.Printf("Failed to refresh data in the local vault: %v", err)
+		return
+	}
+	if found {
+		t.storeLocally(item)
+	}
+}
+
+// offlineChanges lists the changes made offline that were not sent to the server yet,
+// selecting a change shows it with the options to resolve its conflict or to discard it.
+func (t *TUI) offlineChanges() {
+	entries, err := t.vault.Journal()
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to read the changes made offline.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	list := tview.NewList()
+	for _, entry := range entries {
+		entry := entry
+
+		label := fmt.Sprintf("%s %s", entry.Op, entry.Item.Id)
+		if entry.Conflicted {
+			label += " - conflict"
+		}
+		list.AddItem(label, "Recorded at "+entry.RecordedAt.Format(time.RFC3339), 0, func() {
+			t.showOfflineChange(entry, false)
+		})
+	}
+	list.AddItem("Send now", "Send the pending changes to the server", 's', func() {
+		if !t.client.ServerAvailable {
+			t.showMessage("Server not available. Press Enter to go back.", t.offlineChanges)
+			return
+		}
+
+		applied, conflicts, err := t.replayJournal()
+		if err != nil {
+			t.showMessage(fmt.Sprintf("Failed to send the changes made offline.\n%s\nPress Enter to go back.", errorDetails(err)), t.offlineChanges)
+			return
+		}
+
+		t.showMessage(fmt.Sprintf("Changes sent: %d, conflicts: %d. Press Enter to go back.", applied, conflicts), t.offlineChanges)
+	})
+	list.AddItem("Back", "Return to the main menu", 'b', t.showMainMenu)
+
+	t.app.SetRoot(list, true).SetFocus(list)
+}
+
+// showOfflineChange displays the change made offline next to the server copy of the item if the change
+// conflicts with it. The conflict is resolved by keeping the local change, which is sent on top
+// of the server copy, or by keeping the server copy, which discards the local change.
+// The secrets of both are masked until they are revealed.
+func (t *TUI) showOfflineChange(entry *vault.JournalEntry, reveal bool) {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Local change: %s\n", entry.Op))
+	if entry.Op != vault.OpDelete {
+		builder.WriteString(formatDataItem(entry.Item, reveal))
+	}
+	if entry.Conflicted {
+		if entry.Server != nil {
+			builder.WriteString("Changed on the server since:\n")
+			builder.WriteString(formatDataItem(entry.Server, reveal))
+		} else {
+			builder.WriteString("Deleted on the server since, keeping the local change restores it from the trash.\n")
+		}
+	}
+
+	text := tview.NewTextView().SetText(builder.String())
+
+	form := tview.NewForm()
+	if reveal {
+		form.AddButton("Mask", func() {
+			t.showOfflineChange(entry, false)
+		})
+	} else {
+		form.AddButton("Reveal", func() {
+			t.showOfflineChange(entry, true)
+		})
+	}
+	if entry.Conflicted {
+		form.AddButton("Keep mine", func() {
+			t.keepLocalChange(entry)
+		})
+		form.AddButton("Keep server's", func() {
+			t.keepServerCopy(entry)
+		})
+	} else {
+		form.AddButton("Discard", func() {
+			t.keepServerCopy(entry)
+		})
+	}
+	form.AddButton("Back", func() {
+		t.offlineChanges()
+	})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(text, 0, 1, false).
+		AddItem(form, 3, 0, true)
+
+	t.app.SetRoot(layout, true).SetFocus(form)
+}
+
+// keepLocalChange resolves the conflict in favor of the local change and sends it to the server.
+// An item deleted on the server is restored from the trash first.
+func (t *TUI) keepLocalChange(entry *vault.JournalEntry) {
+	if !t.client.ServerAvailable {
+		t.showMessage("Server not available. Press Enter to go back.", t.offlineChanges)
+		return
+	}
+
+	server := entry.Server
+	if server == nil {
+		ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+		defer cancel()
+
+		if _, err := t.client.RestoreData(ctx, &proto.RestoreDataRequest{Id: entry.Item.Id}); err != nil {
+			t.showMessage(fmt.Sprintf("Failed to restore data.\n%s\nPress Enter to go back.", errorDetails(err)), t.offlineChanges)
+			return
+		}
+
+		item, found, err := t.fetchServerItem(entry.Item.Id, entry.Item.Type)
+		if err != nil || !found {
+			t.showMessage(fmt.Sprintf("Failed to get the restored data.\n%s\nPress Enter to go back.", errorDetails(err)), t.offlineChanges)
+			return
+		}
+		server = item
+	}
+
+	if err := t.vault.Rebase(entry.Seq, server.Version); err != nil {
+		t.showMessage(fmt.Sprintf("Failed to resolve the conflict.\n%s\nPress Enter to go back.", errorDetails(err)), t.offlineChanges)
+		return
+	}
+
+	applied, conflicts, err := t.replayJournal()
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to send the changes made offline.\n%s\nPress Enter to go back.", errorDetails(err)), t.offlineChanges)
+		return
+	}
+
+	t.showMessage(fmt.Sprintf("Changes sent: %d, conflicts: %d. Press Enter to go back.", applied, conflicts), t.offlineChanges)
+}
+
+// keepServerCopy discards the local change, replacing the local copy of the item with the server one.
+func (t *TUI) keepServerCopy(entry *vault.JournalEntry) {
+	if err := t.vault.Ack(entry.Seq); err != nil {
+		t.showMessage(fmt.Sprintf("Failed to discard the change.\n%s\nPress Enter to go back.", errorDetails(err)), t.offlineChanges)
+		return
+	}
+
+	switch {
+	case entry.Server != nil:
+		t.storeLocally(entry.Server)
+	case entry.Conflicted || entry.Op == vault.OpCreate:
+		t.removeLocally(entry.Item.Id)
+	case entry.Op == vault.OpDelete:
+		t.storeLocally(entry.Item)
+	case t.client.ServerAvailable:
+		t.refreshLocally(entry.Item.Id, entry.Item.Type)
+	}
+
+	t.showMessage("Local change discarded. Press Enter to go back.", t.offlineChanges)
+}
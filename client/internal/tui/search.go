@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	proto "gophKeeper/pkg/proto/gophkeeper"
+)
+
+// searchLimit is the number of data items shown in the search results.
+const searchLimit = 50
+
+// searchData displays the search box with the filters over the structured metadata,
+// followed by the list of the found data items.
+func (t *TUI) searchData() {
+	if !t.client.ServerAvailable {
+		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	folderLabels, folderIDs := t.folderOptions("any")
+	typeOptions := append([]string{"all"}, dataTypeLabels...)
+
+	form := tview.NewForm()
+	form.
+		AddInputField("Search", "", 40, nil, nil).
+		AddInputField("Tags (comma separated)", "", 40, nil, nil).
+		AddInputField("Labels (key=value, ...)", "", 40, nil, nil).
+		AddDropDown("Folder", folderLabels, 0, nil).
+		AddCheckbox("Include subfolders", true, nil).
+		AddDropDown("Type", typeOptions, 0, nil).
+		AddButton("Search", func() {
+			labels, err := parseLabels(form.GetFormItemByLabel("Labels (key=value, ...)").(*tview.InputField).GetText())
+			if err != nil {
+				t.showMessage(fmt.Sprintf("%v\nPress Enter to go back.", err), t.searchData)
+				return
+			}
+
+			_, folder := form.GetFormItemByLabel("Folder").(*tview.DropDown).GetCurrentOption()
+			_, typeLabel := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
+
+			t.showSearchResults(&proto.SearchDataRequest{
+				Query:     form.GetFormItemByLabel("Search").(*tview.InputField).GetText(),
+				Tags:      splitList(form.GetFormItemByLabel("Tags (comma separated)").(*tview.InputField).GetText()),
+				Labels:    labels,
+				FolderId:  folderIDs[folder],
+				Recursive: form.GetFormItemByLabel("Include subfolders").(*tview.Checkbox).IsChecked(),
+				Type:      dataTypes[typeLabel],
+				Limit:     searchLimit,
+			})
+		}).
+		AddButton("Cancel", func() {
+			t.showMainMenu()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// showSearchResults displays the data items found by the request.
+func (t *TUI) showSearchResults(req *proto.SearchDataRequest) {
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.SearchData(ctx, req)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to search data.\n%s\nPress Enter to go back.", errorDetails(err)), t.searchData)
+		return
+	}
+	if len(resp.Items) == 0 {
+		t.showMessage("Nothing found. Press Enter to go back.", t.searchData)
+		return
+	}
+
+	list := t.summaryList(resp.Items)
+	list.AddItem("New search", "Change the search filters", 'n', t.searchData)
+	list.AddItem("Back", "Return to the main menu", 'b', t.showMainMenu)
+
+	t.app.SetRoot(list, true).SetFocus(list)
+}
+
+// listFolders displays the folders of the user with a form to create or delete a folder.
+func (t *TUI) listFolders() {
+	if !t.client.ServerAvailable {
+		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.ListFolders(ctx)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to list folders.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	paths := folderPaths(resp.Folders)
+	labels := make([]string, 0, len(resp.Folders)+1)
+	ids := map[int]string{}
+	labels = append(labels, "none")
+	for _, folder := range sortedByPath(resp.Folders, paths) {
+		ids[len(labels)] = folder.Id
+		labels = append(labels, paths[folder.Id])
+	}
+
+	var builder strings.Builder
+	for _, label := range labels[1:] {
+		builder.WriteString(label + "\n")
+	}
+	if len(resp.Folders) == 0 {
+		builder.WriteString("There are no folders yet.")
+	}
+
+	folders := tview.NewTextView().SetText(builder.String())
+
+	form := tview.NewForm()
+	form.
+		AddDropDown("Folder", labels, 0, nil).
+		AddInputField("New folder name", "", 40, nil, nil).
+		AddButton("Create inside", func() {
+			index, _ := form.GetFormItemByLabel("Folder").(*tview.DropDown).GetCurrentOption()
+			name := form.GetFormItemByLabel("New folder name").(*tview.InputField).GetText()
+
+			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+			defer cancel()
+
+			_, err := t.client.CreateFolder(ctx, &proto.CreateFolderRequest{ParentId: ids[index], Name: name})
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to create folder.\n%s\nPress Enter to go back.", errorDetails(err)), t.listFolders)
+				return
+			}
+
+			t.listFolders()
+		}).
+		AddButton("Delete", func() {
+			index, _ := form.GetFormItemByLabel("Folder").(*tview.DropDown).GetCurrentOption()
+			if ids[index] == "" {
+				t.showMessage("Choose the folder to delete. Press Enter to go back.", t.listFolders)
+				return
+			}
+
+			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+			defer cancel()
+
+			_, err := t.client.DeleteFolder(ctx, &proto.DeleteFolderRequest{Id: ids[index]})
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to delete folder.\n%s\nPress Enter to go back.", errorDetails(err)), t.listFolders)
+				return
+			}
+
+			t.showMessage("Folder deleted with its subfolders, their data is kept outside of any folder. Press Enter to go back.", t.listFolders)
+		}).
+		AddButton("Back", func() {
+			t.showMainMenu()
+		})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(folders, 0, 1, false).
+		AddItem(form, 9, 0, true)
+
+	t.app.SetRoot(layout, true).SetFocus(form)
+}
+
+// folderOptions returns the labels of the folder dropdown, starting with the label of no folder,
+// and the IDs of the folders by their labels. Only the label of no folder is returned
+// if the folders can not be listed.
+func (t *TUI) folderOptions(noFolder string) ([]string, map[string]string) {
+	labels := []string{noFolder}
+	ids := map[string]string{noFolder: ""}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.ListFolders(ctx)
+	if err != nil {
+		return labels, ids
+	}
+
+	paths := folderPaths(resp.Folders)
+	for _, folder := range sortedByPath(resp.Folders, paths) {
+		labels = append(labels, paths[folder.Id])
+		ids[paths[folder.Id]] = folder.Id
+	}
+
+	return labels, ids
+}
+
+// folderPaths returns the paths of the folders from the top-level folder by the folder IDs.
+func folderPaths(folders []*proto.Folder) map[string]string {
+	byID := make(map[string]*proto.Folder, len(folders))
+	for _, folder := range folders {
+		byID[folder.Id] = folder
+	}
+
+	paths := make(map[string]string, len(folders))
+	var path func(folder *proto.Folder) string
+	path = func(folder *proto.Folder) string {
+		if p, ok := paths[folder.Id]; ok {
+			return p
+		}
+		p := "/" + folder.Name
+		if parent, ok := byID[folder.ParentId]; ok {
+			p = path(parent) + p
+		}
+		paths[folder.Id] = p
+		return p
+	}
+
+	for _, folder := range folders {
+		path(folder)
+	}
+
+	return paths
+}
+
+// sortedByPath returns the folders ordered by their paths, so subfolders follow their parents.
+func sortedByPath(folders []*proto.Folder, paths map[string]string) []*proto.Folder {
+	sorted := append([]*proto.Folder(nil), folders...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return paths[sorted[i].Id] < paths[sorted[j].Id]
+	})
+	return sorted
+}
+
+// splitList returns the non-empty comma separated values of the text.
+func splitList(text string) []string {
+	var values []string
+	for _, value := range strings.Split(text, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseLabels parses the comma separated key=value pairs of the text.
+func parseLabels(text string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range splitList(text) {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("label %q must be formatted as key=value", pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// formatMetadata returns the lines describing the structured metadata of a data item.
+func formatMetadata(metadata *proto.DataItemMetadata) string {
+	var builder strings.Builder
+
+	if metadata.GetName() != "" {
+		builder.WriteString(fmt.Sprintf("Name: %s\n", metadata.GetName()))
+	}
+	if len(metadata.GetTags()) > 0 {
+		builder.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(metadata.GetTags(), ", ")))
+	}
+	if len(metadata.GetLabels()) > 0 {
+		keys := make([]string, 0, len(metadata.GetLabels()))
+		for key := range metadata.GetLabels() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, key+"="+metadata.GetLabels()[key])
+		}
+		builder.WriteString(fmt.Sprintf("Labels: %s\n", strings.Join(pairs, ", ")))
+	}
+
+	return builder.String()
+}
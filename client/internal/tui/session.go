@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rivo/tview"
+	"gophKeeper/client/internal/client"
+	"gophKeeper/client/internal/session"
+)
+
+// loadSession returns the session kept from the last run for the server the client is connected to,
+// nil if there is none.
+func (t *TUI) loadSession() *session.Session {
+	s, err := session.Load(t.sessionPath)
+	if err != nil {
+		if !errors.Is(err, session.ErrNoSession) {
+			log.Printf("Failed to read the session: %v", err)
+		}
+		return nil
+	}
+	if s.ServerAddress != t.client.ServerAddress() {
+		return nil
+	}
+
+	return s
+}
+
+// saveSession seals the current token pair of the logged in account with the master key
+// and keeps it in the session file, so the next run resumes the session with the master password.
+func (t *TUI) saveSession(kdfSalt []byte) {
+	s, err := session.New(t.client.ServerAddress(), t.client.Username(), kdfSalt, t.client.Tokens(), t.client.Cipher())
+	if err == nil {
+		t.sessionMu.Lock()
+		t.session = s
+		err = session.Save(t.sessionPath, s)
+		t.sessionMu.Unlock()
+	}
+	if err != nil {
+		log.Printf("Failed to store the session: %v", err)
+	}
+}
+
+// persistTokens keeps the token pair received from the server in the session file, as the server
+// rotates the refresh token on every refresh. It is called by the client while the token pair
+// is replaced, so it must not call the client. The token pair received while the client
+// is locked is kept on unlock.
+func (t *TUI) persistTokens(tokens client.Tokens) {
+	t.sessionMu.Lock()
+	defer t.sessionMu.Unlock()
+
+	cipher := t.client.Cipher()
+	if t.session == nil || cipher == nil {
+		return
+	}
+
+	err := t.session.SetTokens(tokens, cipher)
+	if err == nil {
+		err = session.Save(t.sessionPath, t.session)
+	}
+	if err != nil {
+		log.Printf("Failed to store the session: %v", err)
+	}
+}
+
+// forgetSession removes the session file, the next run starts with the login.
+func (t *TUI) forgetSession() {
+	t.sessionMu.Lock()
+	defer t.sessionMu.Unlock()
+
+	t.session = nil
+	if err := session.Remove(t.sessionPath); err != nil {
+		log.Printf("Failed to remove the session: %v", err)
+	}
+}
+
+// showResume displays the form resuming the session kept from the last run with the master password.
+func (t *TUI) showResume(s *session.Session) {
+	t.showMasterPasswordForm(fmt.Sprintf("Resume the session of %s", s.Username), func(masterPassword string) {
+		t.resumeSession(s, masterPassword)
+	})
+}
+
+// resumeSession opens the token pair of the session with the master key and unlocks the local vault.
+// An expired access token is refreshed right away, and if the session has ended on the server meanwhile,
+// the user is asked to log in again. While the server is not available, the session is resumed
+// with the local vault and refreshed once the server is back.
+func (t *TUI) resumeSession(s *session.Session, masterPassword string) {
+	back := func() {
+		t.showResume(s)
+	}
+
+	if err := t.client.SetMasterKey(masterPassword, s.KdfSalt); err != nil {
+		t.showMessage("Failed to derive master key. Press Enter to go back.", back)
+		return
+	}
+
+	tokens, err := s.Tokens(t.client.Cipher())
+	if err != nil {
+		t.client.Lock()
+		t.showMessage(fmt.Sprintf("Failed to resume the session.\n%s\nPress Enter to go back.", errorDetails(err)), back)
+		return
+	}
+	if err = t.vault.Unlock(s.Username, t.client.Cipher()); err != nil {
+		t.client.Lock()
+		t.showMessage(fmt.Sprintf("Failed to unlock the local vault.\n%s\nPress Enter to go back.", errorDetails(err)), back)
+		return
+	}
+
+	t.client.ResumeSession(s.Username, tokens)
+	t.sessionMu.Lock()
+	t.session = s
+	t.sessionMu.Unlock()
+
+	if !t.client.ServerAvailable {
+		t.showMessage("Session resumed. Server not available, the data is read from the local vault. Press Enter to open Menu.", t.showMainMenu)
+		return
+	}
+
+	if time.Now().After(tokens.ExpiresAt) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		// the refreshed token pair is sealed into the session file by persistTokens
+		if err = t.client.RefreshToken(ctx); err != nil {
+			t.forgetSession()
+			t.client.ResumeSession("", client.Tokens{})
+			t.client.Lock()
+			t.vault.Lock()
+
+			t.showMessage(fmt.Sprintf("The session has expired, log in again.\n%s\nPress Enter to log in.", errorDetails(err)), t.login)
+			return
+		}
+	}
+
+	t.openMenu("Session resumed.")
+}
+
+// lock forgets the master key of the client and of the local vault without ending the session,
+// which is resumed with the master password. A session opened offline has no login to resume,
+// locking it returns to the start screen.
+func (t *TUI) lock() {
+	username := t.client.Username()
+
+	kdfSalt, found, err := t.vault.KdfSalt(username)
+	t.client.Lock()
+	t.vault.Lock()
+
+	if username == "" || err != nil || !found {
+		if err != nil {
+			log.Printf("Failed to read the kdf salt from the local vault: %v", err)
+		}
+		t.restart()
+		return
+	}
+
+	t.showUnlock(username, kdfSalt)
+}
+
+// showUnlock displays the form unlocking the locked client with the master password.
+func (t *TUI) showUnlock(username string, kdfSalt []byte) {
+	t.showMasterPasswordForm(fmt.Sprintf("Unlock the session of %s", username), func(masterPassword string) {
+		back := func() {
+			t.showUnlock(username, kdfSalt)
+		}
+
+		if err := t.client.SetMasterKey(masterPassword, kdfSalt); err != nil {
+			t.showMessage("Failed to derive master key. Press Enter to go back.", back)
+			return
+		}
+		if err := t.vault.Unlock(username, t.client.Cipher()); err != nil {
+			t.client.Lock()
+			t.showMessage(fmt.Sprintf("Failed to unlock.\n%s\nPress Enter to go back.", errorDetails(err)), back)
+			return
+		}
+
+		// the token pair may have been refreshed while the client was locked
+		t.saveSession(kdfSalt)
+
+		t.showMainMenu()
+	})
+}
+
+// showMasterPasswordForm displays the form asking for the master password, with the options
+// to log in as somebody else and to quit the application instead.
+func (t *TUI) showMasterPasswordForm(title string, onUnlock func(masterPassword string)) {
+	form := tview.NewForm()
+	form.
+		AddPasswordField("Master password", "", 20, '*', nil).
+		AddButton("Unlock", func() {
+			onUnlock(form.GetFormItemByLabel("Master password").(*tview.InputField).GetText())
+		}).
+		AddButton("Log in", func() {
+			t.login()
+		}).
+		AddButton("Quit", func() {
+			t.app.Stop()
+		})
+	form.SetBorder(true).SetTitle(title)
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
@@ -6,38 +6,90 @@ package tui
 import (
 	"context"
 	"fmt"
-	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"gophKeeper/client/internal/client"
+	"gophKeeper/client/internal/session"
+	"gophKeeper/client/internal/vault"
+	"gophKeeper/pkg/payload"
 	proto "gophKeeper/pkg/proto/gophkeeper"
 )
 
 // TUI represents the text-based user interface for the GophKeeper client,
 // handling the display and interaction logic for user registration, login,
-// and data item management.
+// and data item management. The received data items are kept in the local vault,
+// which serves them while the server is not available. The changes made meanwhile
+// are journaled in the vault and sent when the server is available again. The login is kept
+// in the session file, so the next run resumes it with the master password. The client
+// is locked after the idle timeout without input from the user.
 type TUI struct {
-	client *client.GophKeeperClient
-	cache  *redis.Client
-	app    *tview.Application
+	client      *client.GophKeeperClient
+	vault       *vault.Vault
+	app         *tview.Application
+	sessionPath string
+	idleTimeout time.Duration
+
+	// replayMu keeps the journal from being replayed by the ping loop and the user at the same time.
+	replayMu sync.Mutex
+
+	// sessionMu guards the session, whose token pair is replaced whenever the client refreshes it.
+	sessionMu sync.Mutex
+	session   *session.Session
 }
 
-// NewTUI creates a new TUI instance with the given gRPC client, initializing
-// the application and setting up the user interface.
-func NewTUI(client *client.GophKeeperClient, rDB *redis.Client) *TUI {
-	return &TUI{
-		client: client,
-		cache:  rDB,
-		app:    tview.NewApplication(),
+// NewTUI creates a new TUI instance with the given gRPC client, local vault, the path
+// of the session file and the idle timeout, initializing the application and setting up
+// the user interface. Mouse events are enabled, so the idle timer sees the mouse activity too.
+func NewTUI(client *client.GophKeeperClient, vault *vault.Vault, sessionPath string, idleTimeout time.Duration) *TUI {
+	t := &TUI{
+		client:      client,
+		vault:       vault,
+		app:         tview.NewApplication().EnableMouse(true),
+		sessionPath: sessionPath,
+		idleTimeout: idleTimeout,
 	}
+	client.OnReconnect(t.onReconnect)
+	client.OnTokens(t.persistTokens)
+	t.watchIdle()
+
+	return t
+}
+
+// binaryTransferTimeout limits the time of streaming a binary item to or from the server.
+const binaryTransferTimeout = 10 * time.Minute
+
+// dataTypeLabels lists the data item types in the order they are shown to the user.
+var dataTypeLabels = []string{"credentials", "bank card", "text", "binary"}
+
+// listPageSize is the number of data items shown on a page of the list.
+const listPageSize = 20
+
+// sortLabels lists the fields the data items can be ordered by, in the order they are shown to the user.
+var sortLabels = []string{"updated", "created", "id"}
+
+// sortFields maps the sort labels to the sort fields of the list requests.
+var sortFields = map[string]proto.ListDataSort{
+	"updated": proto.ListDataSort_LIST_DATA_SORT_UPDATED_AT,
+	"created": proto.ListDataSort_LIST_DATA_SORT_CREATED_AT,
+	"id":      proto.ListDataSort_LIST_DATA_SORT_ID,
+}
+
+// dataTypes maps the data item type labels to the types stored on the server.
+var dataTypes = map[string]string{
+	"credentials": payload.CredentialsType,
+	"bank card":   payload.BankCardType,
+	"text":        payload.TextType,
+	"binary":      payload.BinaryType,
 }
 
 func generateUniqueID() string {
@@ -45,8 +97,14 @@ func generateUniqueID() string {
 }
 
 // Run starts the TUI application, displaying the main form with options for
-// user registration, login, and quitting the application.
+// user registration, login, and quitting the application. A session kept from
+// the last run is offered to be resumed with the master password instead.
 func (t *TUI) Run() error {
+	if s := t.loadSession(); s != nil {
+		t.showResume(s)
+		return t.app.Run()
+	}
+
 	form := tview.NewForm()
 
 	form.AddButton("Register", t.register).
@@ -60,7 +118,7 @@ func (t *TUI) Run() error {
 }
 
 // register handles the user registration process, displaying a form to input
-// a username and password and sending the registration request to the server.
+// a username and password and registering the SRP verifier of the password on the server.
 func (t *TUI) register() {
 	if !t.client.ServerAvailable {
 		return
@@ -69,20 +127,23 @@ func (t *TUI) register() {
 
 	form.
 		AddInputField("Username", "", 20, nil, nil).
-		AddInputField("Password", "", 20, nil, nil).
+		AddPasswordField("Password", "", 20, '*', nil).
 		AddButton("Register", func() {
 			username := form.GetFormItemByLabel("Username").(*tview.InputField).GetText()
 			password := form.GetFormItemByLabel("Password").(*tview.InputField).GetText()
 
+			// the server only receives the SRP verifier, so its password policy is enforced here
+			if problem := t.checkNewPassword(password, username); problem != "" {
+				t.showMessage(fmt.Sprintf("Register failed.\n%s\nPress Enter to go back.", problem), t.register)
+				return
+			}
+
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 			defer cancel()
 
-			_, err := t.client.Register(ctx, &proto.RegisterRequest{
-				Username: username,
-				Password: password,
-			})
+			_, err := t.client.RegisterSrp(ctx, username, password)
 			if err != nil {
-				t.showMessage("Register failed. Press Enter to go back.", t.restart)
+				t.showMessage(fmt.Sprintf("Register failed.\n%s\nPress Enter to go back.", errorDetails(err)), t.restart)
 				return
 			}
 
@@ -95,34 +156,48 @@ func (t *TUI) register() {
 	t.app.SetRoot(form, true).SetFocus(form)
 }
 
-// login handles the user login process, displaying a form to input a username
-// and password and sending the login request to the server.
+// login handles the user login process, displaying a form to input a username,
+// password and master password, logging in with SRP, so the password never leaves
+// the client, and deriving the vault key used to encrypt data items. While the server
+// is not available, the master password unlocks the local vault of the user instead.
 func (t *TUI) login() {
 	form := tview.NewForm()
 	form.
 		AddInputField("Username", "", 20, nil, nil).
 		AddPasswordField("Password", "", 20, '*', nil).
+		AddPasswordField("Master password", "", 20, '*', nil).
 		AddButton("Login", func() {
 			username := form.GetFormItemByLabel("Username").(*tview.InputField).GetText()
 			password := form.GetFormItemByLabel("Password").(*tview.InputField).GetText()
+			masterPassword := form.GetFormItemByLabel("Master password").(*tview.InputField).GetText()
+
+			if masterPassword == "" {
+				t.showMessage("Master password is required to decrypt your data. Press Enter to go back.", t.restart)
+				return
+			}
+
+			if !t.client.ServerAvailable {
+				t.loginOffline(username, masterPassword)
+				return
+			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 			defer cancel()
 
 			ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs())
 
-			resp, err := t.client.Login(ctx, &proto.LoginRequest{
-				Username: username,
-				Password: password,
-			})
+			resp, err := t.client.LoginSrp(ctx, username, password)
 			if err != nil {
-				t.showMessage("Login failed. Press Enter to go back.", t.restart)
+				t.showMessage(fmt.Sprintf("Login failed.\n%s\nPress Enter to go back.", errorDetails(err)), t.restart)
 				return
 			}
 
-			t.client.BearerToken = resp.Token
+			if resp.TwoFactorChallenge != "" {
+				t.verifyLoginTwoFactor(resp.TwoFactorChallenge, masterPassword)
+				return
+			}
 
-			t.showMessage("Login successful. Press Enter to open Menu.", t.showMainMenu)
+			t.finishLogin(masterPassword, resp.KdfSalt, resp.KeyCheck)
 		}).
 		AddButton("Cancel", func() {
 			t.restart()
@@ -131,6 +206,81 @@ func (t *TUI) login() {
 	t.app.SetRoot(form, true).SetFocus(form)
 }
 
+// finishLogin derives the vault key from the master password and the kdf salt
+// received on login, checks it against the key check of the account, unlocks
+// the local vault with it and opens the main menu. The kdf salt is kept in the
+// vault, so the key can be derived offline, and the session is kept in the
+// session file, so the next run resumes it.
+func (t *TUI) finishLogin(masterPassword string, kdfSalt []byte, keyCheck []byte) {
+	err := t.client.SetMasterKey(masterPassword, kdfSalt)
+	if err != nil {
+		t.showMessage("Failed to derive master key. Press Enter to go back.", t.restart)
+		return
+	}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	if err = t.client.CheckMasterKey(ctx, keyCheck); err != nil {
+		t.showMessage(fmt.Sprintf("Failed to check the master password.\n%s\nPress Enter to go back.", errorDetails(err)), t.restart)
+		return
+	}
+
+	if err = t.vault.SaveKdfSalt(t.client.Username(), kdfSalt); err != nil {
+		log.Printf("Failed to store the kdf salt in the local vault: %v", err)
+	}
+	if err = t.vault.Unlock(t.client.Username(), t.client.Cipher()); err != nil {
+		t.showMessage(fmt.Sprintf("Failed to unlock the local vault.\n%s\nPress Enter to go back.", errorDetails(err)), t.restart)
+		return
+	}
+
+	t.saveSession(kdfSalt)
+
+	t.openMenu("Login successful.")
+}
+
+// openMenu sends the changes made offline and opens the main menu, reporting the sent changes
+// and the conflicts with the server after the greeting.
+func (t *TUI) openMenu(greeting string) {
+	applied, conflicts, err := t.replayJournal()
+	switch {
+	case err != nil:
+		t.showMessage(fmt.Sprintf("%s Sending the changes made offline failed.\n%s\nPress Enter to open Menu.", greeting, errorDetails(err)), t.showMainMenu)
+	case conflicts > 0:
+		t.showMessage(fmt.Sprintf("%s Changes made offline sent: %d, conflicts: %d. Press Enter to resolve the conflicts.", greeting, applied, conflicts), t.offlineChanges)
+	case applied > 0:
+		t.showMessage(fmt.Sprintf("%s Changes made offline sent: %d. Press Enter to open Menu.", greeting, applied), t.showMainMenu)
+	default:
+		t.showMessage(greeting+" Press Enter to open Menu.", t.showMainMenu)
+	}
+}
+
+// loginOffline derives the vault key from the master password and the kdf salt kept
+// in the local vault on the last login of the user, and unlocks the vault with it,
+// so the stored data items can be read while the server is not available.
+func (t *TUI) loginOffline(username, masterPassword string) {
+	kdfSalt, found, err := t.vault.KdfSalt(username)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to read the local vault.\n%s\nPress Enter to go back.", errorDetails(err)), t.restart)
+		return
+	}
+	if !found {
+		t.showMessage("Server not available and the user never logged in on this machine. Press Enter to go back.", t.restart)
+		return
+	}
+
+	if err = t.client.SetMasterKey(masterPassword, kdfSalt); err != nil {
+		t.showMessage("Failed to derive master key. Press Enter to go back.", t.restart)
+		return
+	}
+	if err = t.vault.Unlock(username, t.client.Cipher()); err != nil {
+		t.showMessage(fmt.Sprintf("Failed to unlock the local vault.\n%s\nPress Enter to go back.", errorDetails(err)), t.restart)
+		return
+	}
+
+	t.showMessage("Server not available, the data is read from the local vault. Press Enter to open Menu.", t.showMainMenu)
+}
+
 // showMainMenu displays the main menu with options for creating, getting,
 // updating, and deleting data items, as well as quitting the application.
 func (t *TUI) showMainMenu() {
@@ -138,8 +288,19 @@ func (t *TUI) showMainMenu() {
 		AddItem("Create Data", "Create new data", 'c', t.createData).
 		AddItem("Get Data", "Get existing data", 'g', t.getData).
 		AddItem("List Data", "List existing data", 'l', t.listData).
+		AddItem("Search", "Search data by name, tags, labels and folder", 'f', t.searchData).
 		AddItem("Update Data", "Update existing data", 'u', t.updateData).
 		AddItem("Delete Data", "Delete existing data", 'd', t.deleteData).
+		AddItem("History", "Show and restore previous versions of data", 'h', t.history).
+		AddItem("Trash", "List and restore deleted data", 't', t.listTrash).
+		AddItem("Folders", "List, create and delete folders", 'r', t.listFolders).
+		AddItem("Sync Data", "Synchronize data with server", 's', t.syncData).
+		AddItem("Offline changes", "Review the changes not sent to the server and resolve conflicts", 'j', t.offlineChanges).
+		AddItem("Sessions", "List and revoke active sessions", 'a', t.listSessions).
+		AddItem("Two-factor authentication", "Enable or disable codes from an authenticator app", '2', t.twoFactor).
+		AddItem("Account", "Change the password or the username, delete the account", 'p', t.account).
+		AddItem("Lock", "Forget the master key until it is entered again, staying logged in", 'k', t.lock).
+		AddItem("Logout", "End the current session", 'o', t.logout).
 		AddItem("Quit", "Press to exit", 'q', func() {
 			t.app.Stop()
 		})
@@ -147,68 +308,63 @@ func (t *TUI) showMainMenu() {
 	t.app.SetRoot(menu, true).SetFocus(menu)
 }
 
-// createData displays a form for creating a new data item, allowing the user
-// to input the type, data, and metadata, and sending the create request to the server.
+// createData lets the user pick the type of a new data item and displays the form
+// dedicated to that type, sending the create request to the server on submit.
+// While the server is not available, the item is recorded in the local vault instead.
 func (t *TUI) createData() {
-	if !t.client.ServerAvailable {
-		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
-		return
-	}
+	t.selectDataType(func(dataType string) {
+		if dataType == payload.BinaryType {
+			if !t.client.ServerAvailable {
+				t.showMessage("Server not available, binary data can only be uploaded online. Press Enter to go back.", t.showMainMenu)
+				return
+			}
 
-	form := tview.NewForm()
+			t.showBinaryForm(func(path, meta string) {
+				id := generateUniqueID()
+				if err := t.uploadBinary(id, path, meta); err != nil {
+					t.showMessage(fmt.Sprintf("Failed to create data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+					return
+				}
 
-	form.
-		AddDropDown("Type", []string{"binary", "text", "credentials", "bank card"}, 0, nil).
-		AddInputField("Data", "", 20, nil, nil).
-		AddInputField("Meta", "", 20, nil, nil).
-		AddButton("Submit", func() {
-			_, typeField := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
-			dataField := form.GetFormItemByLabel("Data").(*tview.InputField).GetText()
-			metaField := form.GetFormItemByLabel("Meta").(*tview.InputField).GetText()
+				t.showMessage(fmt.Sprintf("Data created successfully.\nID - %s \nPress Enter to go back.", id), t.showMainMenu)
+			})
+			return
+		}
 
-			var data []byte
-			var err error
+		t.showPayloadForm(dataType, func(item *proto.DataItem) {
+			item.Id = generateUniqueID()
 
-			if typeField == "binary" {
-				data, err = os.ReadFile(dataField)
-				if err != nil {
-					t.showMessage(fmt.Sprintf("Failed to read file: %v", err), t.showMainMenu)
+			if !t.client.ServerAvailable {
+				item.CreatedAt = timestamppb.Now()
+				item.UpdatedAt = item.CreatedAt
+
+				if err := t.recordOffline(vault.OpCreate, item, 0); err != nil {
+					t.showMessage(fmt.Sprintf("Failed to create data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
 					return
 				}
-			} else {
-				data = []byte(dataField)
+
+				t.showMessage(fmt.Sprintf("Server not available, the data is saved locally and sent when the server is back.\nID - %s \nPress Enter to go back.", item.Id), t.showMainMenu)
+				return
 			}
 
 			req := &proto.CreateDataRequest{
-				Data: &proto.DataItem{
-					Id:   generateUniqueID(),
-					Type: typeField,
-					Data: data,
-					Meta: metaField,
-				},
+				Data: item,
 			}
 
 			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
 			defer cancel()
 
-			_, err = t.client.CreateData(ctx, req)
+			_, err := t.client.CreateData(ctx, req)
 			if err != nil {
-				t.showMessage("Failed to create data. Press Enter to go back.", t.showMainMenu)
+				t.showMessage(fmt.Sprintf("Failed to create data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
 				return
 			}
 
-			err = t.cache.Set(context.Background(), req.Data.Id, req.Data.Data, 0).Err()
-			if err != nil {
-				log.Printf("Failed to cache data: %v", err)
-			}
+			t.storeLocally(req.Data)
 
 			t.showMessage(fmt.Sprintf("Data created successfully.\nID - %s \nPress Enter to go back.", req.Data.Id), t.showMainMenu)
-		}).
-		AddButton("Cancel", func() {
-			t.showMainMenu()
 		})
-
-	t.app.SetRoot(form, true).SetFocus(form)
+	})
 }
 
 // getData displays a form for retrieving an existing data item, allowing the user
@@ -217,10 +373,11 @@ func (t *TUI) getData() {
 	form := tview.NewForm()
 	form.
 		AddInputField("ID", "", 40, nil, nil).
-		AddDropDown("Type", []string{"binary", "text", "credentials", "bank card"}, 0, nil).
+		AddDropDown("Type", dataTypeLabels, 0, nil).
 		AddButton("Submit", func() {
 			idField := form.GetFormItemByLabel("ID").(*tview.InputField).GetText()
-			_, typeField := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
+			_, typeLabel := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
+			typeField := dataTypes[typeLabel]
 
 			if t.client.ServerAvailable {
 				req := &proto.GetDataRequest{
@@ -231,38 +388,40 @@ func (t *TUI) getData() {
 				ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
 				defer cancel()
 
+				if typeField == payload.BinaryType {
+					fileName := fmt.Sprintf("downloaded_file_%s", idField)
+					if err := t.downloadBinary(idField, fileName); err != nil {
+						t.showMessage(fmt.Sprintf("Failed to download file.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+						return
+					}
+					t.showMessage(fmt.Sprintf("File downloaded and saved as %s. Press Enter to go back.", fileName), t.showMainMenu)
+					return
+				}
+
 				resp, err := t.client.GetData(ctx, req)
 				if err != nil {
-					t.showMessage("Failed to get data. Press Enter to go back.", t.showMainMenu)
+					t.showMessage(fmt.Sprintf("Failed to get data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
 					return
 				}
 				if len(resp.Data) > 0 {
-					if typeField == "binary" {
-						fileName := fmt.Sprintf("downloaded_file_%s", idField)
-						err = os.WriteFile(fileName, resp.Data[0].Data, 0644)
-						if err != nil {
-							t.showMessage(fmt.Sprintf("Failed to save file: %v", err), t.showMainMenu)
-							return
-						}
-						t.showMessage(fmt.Sprintf("File downloaded and saved as %s. Press Enter to go back.", fileName), t.showMainMenu)
-					} else {
-						t.showMessage(formatDataItem(resp.Data[0]), t.showMainMenu)
-					}
+					t.showDataItemMessage(resp.Data[0], "Press Enter to go back.", t.showMainMenu)
 
-					err = t.cache.Set(context.Background(), idField, resp.Data[0].Data, 0).Err()
-					if err != nil {
-						log.Printf("Failed to cache data: %v", err)
-					}
+					t.storeLocally(resp.Data[0])
 				} else {
 					t.showMessage("No data found. Press Enter to go back.", t.showMainMenu)
 				}
 			} else {
-				data, err := t.cache.Get(context.Background(), idField).Result()
+				item, found, err := t.vault.Get(idField)
 				if err != nil {
-					t.showMessage("Failed to get data. Press Enter to go back.", t.showMainMenu)
+					t.showMessage(fmt.Sprintf("Failed to get data from the local vault.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+					return
+				}
+				if !found {
+					t.showMessage("Server not available and the data is not in the local vault. Press Enter to go back.", t.showMainMenu)
+					return
 				}
 
-				t.showMessage(fmt.Sprintf("%s\nServer not available, there is information only about data.", data), t.showMainMenu)
+				t.showDataItemMessage(item, "Server not available, the data is from the local vault. Press Enter to go back.", t.showMainMenu)
 			}
 		}).
 		AddButton("Cancel", func() {
@@ -272,85 +431,430 @@ func (t *TUI) getData() {
 	t.app.SetRoot(form, true).SetFocus(form)
 }
 
-// listData displays a form for retrieving an existing data items, allowing the user
-// to input the ID
+// listData displays a form for choosing the type and the order of the listed data items,
+// followed by the first page of the items.
 func (t *TUI) listData() {
 	if !t.client.ServerAvailable {
-		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+		t.listLocalData()
+		return
+	}
+
+	typeOptions := append([]string{"all"}, dataTypeLabels...)
+
+	form := tview.NewForm()
+	form.
+		AddDropDown("Type", typeOptions, 0, nil).
+		AddDropDown("Sort by", sortLabels, 0, nil).
+		AddCheckbox("Descending", true, nil).
+		AddButton("Submit", func() {
+			_, typeLabel := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
+			_, sortLabel := form.GetFormItemByLabel("Sort by").(*tview.DropDown).GetCurrentOption()
+
+			t.showDataPage(&proto.ListDataRequest{
+				PageSize:   listPageSize,
+				SortBy:     sortFields[sortLabel],
+				Descending: form.GetFormItemByLabel("Descending").(*tview.Checkbox).IsChecked(),
+				Type:       dataTypes[typeLabel],
+			})
+		}).
+		AddButton("Cancel", func() {
+			t.showMainMenu()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// listLocalData displays the data items stored in the local vault while the server is not available.
+func (t *TUI) listLocalData() {
+	items, err := t.vault.List()
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to list data from the local vault.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
 		return
 	}
 
+	list := tview.NewList()
+	for _, item := range items {
+		item := item
+		list.AddItem(fmt.Sprintf("%s (%s)", item.Meta, item.Type), item.Id, 0, func() {
+			t.showDataItemMessage(item, "Server not available, the data is from the local vault. Press Enter to go back.", t.listLocalData)
+		})
+	}
+	list.AddItem("Back", "Return to the main menu", 'b', t.showMainMenu)
+
+	t.app.SetRoot(list, true).SetFocus(list)
+}
+
+// showDataPage displays the page of data item summaries selected by the request, followed by
+// an entry leading to the next page if there is one. Only the summaries are listed, the payload
+// of an item is fetched when the item is selected.
+func (t *TUI) showDataPage(req *proto.ListDataRequest) {
 	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
 	defer cancel()
 
-	resp, err := t.client.ListData(ctx, &emptypb.Empty{})
+	resp, err := t.client.ListData(ctx, req)
 	if err != nil {
-		t.showMessage("Failed to list data. Press Enter to go back.", t.showMainMenu)
+		t.showMessage(fmt.Sprintf("Failed to list data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+	if len(resp.Items) == 0 {
+		t.showMessage("No data found. Press Enter to go back.", t.showMainMenu)
 		return
 	}
-	if len(resp.Data) > 0 {
-		var builder strings.Builder
-		for _, item := range resp.Data {
-			err = t.cache.Set(context.Background(), item.Id, item.Data, 0).Err()
-			if err != nil {
-				log.Printf("Failed to cache data: %v", err)
-			}
 
-			builder.WriteString(formatDataItem(item))
-		}
+	list := t.summaryList(resp.Items)
+	if resp.NextPageToken != "" {
+		list.AddItem("Next page", "Show the next page of data", 'n', func() {
+			t.showDataPage(&proto.ListDataRequest{
+				PageSize:   req.PageSize,
+				PageToken:  resp.NextPageToken,
+				SortBy:     req.SortBy,
+				Descending: req.Descending,
+				Type:       req.Type,
+			})
+		})
+	}
+	list.AddItem("Back", "Return to the main menu", 'b', t.showMainMenu)
+
+	t.app.SetRoot(list, true).SetFocus(list)
+}
+
+// summaryList returns the list of the data item summaries, selecting an item shows its payload.
+func (t *TUI) summaryList(items []*proto.DataItemSummary) *tview.List {
+	list := tview.NewList()
+	for _, item := range items {
+		item := item
+		list.AddItem(summaryLabel(item), summaryDetails(item), 0, func() {
+			t.showDataItem(item.Id, item.Type)
+		})
+	}
+	return list
+}
 
-		builder.WriteString("Press Enter to go back.")
+// showDataItem fetches the payload of the data item and displays it.
+// The content of binary items is downloaded to a file instead.
+func (t *TUI) showDataItem(id, dataType string) {
+	if dataType == payload.BinaryType {
+		fileName := fmt.Sprintf("downloaded_file_%s", id)
+		if err := t.downloadBinary(id, fileName); err != nil {
+			t.showMessage(fmt.Sprintf("Failed to download file.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+			return
+		}
+		t.showMessage(fmt.Sprintf("File downloaded and saved as %s. Press Enter to go back.", fileName), t.showMainMenu)
+		return
+	}
 
-		t.showMessage(builder.String(), t.showMainMenu)
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
 
-	} else {
+	resp, err := t.client.GetData(ctx, &proto.GetDataRequest{Id: id, Type: dataType})
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to get data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+	if len(resp.Data) == 0 {
 		t.showMessage("No data found. Press Enter to go back.", t.showMainMenu)
+		return
 	}
+
+	t.storeLocally(resp.Data[0])
+
+	t.showDataItemMessage(resp.Data[0], "Press Enter to go back.", t.showMainMenu)
 }
 
-// updateData displays a form for updating an existing data item, allowing the user
-// to input the ID, type, data, and metadata, and sending the update request to the server.
+// updateData displays a form asking for the ID and type of an existing data item,
+// followed by the form dedicated to that type, and sends the update request to the server.
+// While the server is not available, the update of the local copy is recorded instead.
 func (t *TUI) updateData() {
-	if !t.client.ServerAvailable {
-		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+	form := tview.NewForm()
+	form.
+		AddInputField("ID", "", 40, nil, nil).
+		AddDropDown("Type", dataTypeLabels, 0, nil).
+		AddButton("Next", func() {
+			idField := form.GetFormItemByLabel("ID").(*tview.InputField).GetText()
+			_, typeLabel := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
+
+			if dataTypes[typeLabel] == payload.BinaryType {
+				if !t.client.ServerAvailable {
+					t.showMessage("Server not available, binary data can only be uploaded online. Press Enter to go back.", t.showMainMenu)
+					return
+				}
+
+				t.showBinaryForm(func(path, meta string) {
+					if err := t.uploadBinary(idField, path, meta); err != nil {
+						log.Printf("failed to update data: %v", err)
+						t.showMessage(fmt.Sprintf("Failed to update data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+						return
+					}
+
+					t.showMessage("Data updated successfully. Press Enter to go back.", t.showMainMenu)
+				})
+				return
+			}
+
+			t.showPayloadForm(dataTypes[typeLabel], func(item *proto.DataItem) {
+				item.Id = idField
+
+				if !t.client.ServerAvailable {
+					t.updateOffline(item)
+					return
+				}
+
+				req := &proto.UpdateDataRequest{
+					Data: item,
+				}
+
+				ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+				defer cancel()
+
+				resp, err := t.client.UpdateData(ctx, req)
+				if err != nil {
+					log.Printf("failed to update data: %v", err)
+					t.showMessage(fmt.Sprintf("Failed to update data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+					return
+				}
+				if len(resp.Message) > 0 {
+					log.Printf("UpdateData response: %s", resp.Message)
+					t.storeLocally(item)
+					t.showMessage("Data updated successfully. Press Enter to go back.", t.showMainMenu)
+				} else {
+					log.Printf("UpdateData response: no data found")
+					t.showMessage("No data found. Press Enter to go back.", t.showMainMenu)
+				}
+			})
+		}).
+		AddButton("Cancel", func() {
+			t.showMainMenu()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// selectDataType displays the list of data item types and calls onSelect with the chosen type.
+func (t *TUI) selectDataType(onSelect func(dataType string)) {
+	list := tview.NewList()
+	for _, label := range dataTypeLabels {
+		dataType := dataTypes[label]
+		list.AddItem(label, "", 0, func() {
+			onSelect(dataType)
+		})
+	}
+	list.AddItem("Cancel", "Back to menu", 'q', t.showMainMenu)
+
+	t.app.SetRoot(list, true).SetFocus(list)
+}
+
+// showPayloadForm displays the form dedicated to the given data item type and calls onSubmit
+// with the data item built from the entered values.
+func (t *TUI) showPayloadForm(dataType string, onSubmit func(item *proto.DataItem)) {
+	form := tview.NewForm()
+
+	switch dataType {
+	case payload.CredentialsType:
+		form.
+			AddInputField("Login", "", 40, nil, nil).
+			AddPasswordField("Password", "", 40, '*', nil).
+			AddInputField("URL", "", 40, nil, nil).
+			AddInputField("TOTP secret", "", 40, nil, nil)
+	case payload.BankCardType:
+		form.
+			AddInputField("Number", "", 24, nil, nil).
+			AddInputField("Holder", "", 40, nil, nil).
+			AddInputField("Expiry (MM/YY)", "", 6, nil, nil).
+			AddPasswordField("CVV", "", 4, '*', nil)
+	case payload.TextType:
+		form.AddTextArea("Text", "", 40, 5, 0, nil)
+	}
+
+	folderLabels, folderIDs := t.folderOptions("none")
+
+	form.
+		AddInputField("Meta", "", 40, nil, nil).
+		AddInputField("Name", "", 40, nil, nil).
+		AddDropDown("Folder", folderLabels, 0, nil).
+		AddInputField("Tags (comma separated)", "", 40, nil, nil).
+		AddInputField("Labels (key=value, ...)", "", 40, nil, nil).
+		AddButton("Submit", func() {
+			item := &proto.DataItem{
+				Type: dataType,
+				Meta: form.GetFormItemByLabel("Meta").(*tview.InputField).GetText(),
+			}
+
+			_, folder := form.GetFormItemByLabel("Folder").(*tview.DropDown).GetCurrentOption()
+			labels, err := parseLabels(form.GetFormItemByLabel("Labels (key=value, ...)").(*tview.InputField).GetText())
+			if err != nil {
+				t.showMessage(fmt.Sprintf("%v\nPress Enter to go back.", err), t.showMainMenu)
+				return
+			}
+			item.Metadata = &proto.DataItemMetadata{
+				Name:     form.GetFormItemByLabel("Name").(*tview.InputField).GetText(),
+				FolderId: folderIDs[folder],
+				Tags:     splitList(form.GetFormItemByLabel("Tags (comma separated)").(*tview.InputField).GetText()),
+				Labels:   labels,
+			}
+
+			getText := func(label string) string {
+				switch field := form.GetFormItemByLabel(label).(type) {
+				case *tview.InputField:
+					return field.GetText()
+				case *tview.TextArea:
+					return field.GetText()
+				default:
+					return ""
+				}
+			}
+
+			switch dataType {
+			case payload.CredentialsType:
+				item.Payload = &proto.DataItem_Credentials{Credentials: &proto.Credentials{
+					Login:      getText("Login"),
+					Password:   getText("Password"),
+					Url:        getText("URL"),
+					TotpSecret: getText("TOTP secret"),
+				}}
+			case payload.BankCardType:
+				item.Payload = &proto.DataItem_BankCard{BankCard: &proto.BankCard{
+					Number: getText("Number"),
+					Holder: getText("Holder"),
+					Expiry: getText("Expiry (MM/YY)"),
+					Cvv:    getText("CVV"),
+				}}
+			case payload.TextType:
+				item.Payload = &proto.DataItem_Text{Text: &proto.Text{
+					Content: getText("Text"),
+				}}
+			}
+
+			onSubmit(item)
+		}).
+		AddButton("Cancel", func() {
+			t.showMainMenu()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// showBinaryForm displays the form asking for the path of the file to upload
+// and calls onSubmit with the entered path and meta.
+func (t *TUI) showBinaryForm(onSubmit func(path, meta string)) {
+	form := tview.NewForm()
+	form.
+		AddInputField("File path", "", 40, nil, nil).
+		AddInputField("Meta", "", 40, nil, nil).
+		AddButton("Submit", func() {
+			onSubmit(
+				form.GetFormItemByLabel("File path").(*tview.InputField).GetText(),
+				form.GetFormItemByLabel("Meta").(*tview.InputField).GetText(),
+			)
+		}).
+		AddButton("Cancel", func() {
+			t.showMainMenu()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// uploadBinary streams the file at the given path to the server as the binary item with the given ID,
+// so large files are never loaded into memory.
+func (t *TUI) uploadBinary(id, path, meta string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(binaryTransferTimeout)
+	defer cancel()
+
+	_, err = t.client.UploadBinary(ctx, id, meta, file, info.Size())
+	return err
+}
+
+// downloadBinary streams the binary item with the given ID from the server into the file with the given name.
+func (t *TUI) downloadBinary(id, fileName string) error {
+	return t.downloadBinaryVersion(id, 0, fileName)
+}
+
+// downloadBinaryVersion streams the given version of the binary item from the server into the file,
+// removing the file if the download fails. The current version is downloaded if the version is not positive.
+func (t *TUI) downloadBinaryVersion(id string, version int32, fileName string) error {
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(binaryTransferTimeout)
+	defer cancel()
+
+	if _, err = t.client.DownloadBinaryVersion(ctx, id, version, file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(fileName)
+		return err
+	}
+
+	return file.Close()
+}
+
+// updateOffline records the update of the local copy of the item, sent on top of the version
+// of the copy when the server is available again.
+func (t *TUI) updateOffline(item *proto.DataItem) {
+	local, found, err := t.vault.Get(item.Id)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to update data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+	if !found {
+		t.showMessage("Server not available and the data is not in the local vault. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	item.Version = local.Version
+	item.CreatedAt = local.CreatedAt
+	item.UpdatedAt = timestamppb.Now()
+
+	if err = t.recordOffline(vault.OpUpdate, item, local.Version); err != nil {
+		t.showMessage(fmt.Sprintf("Failed to update data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
 		return
 	}
 
+	t.showMessage("Server not available, the update is saved locally and sent when the server is back. Press Enter to go back.", t.showMainMenu)
+}
+
+// deleteData displays a form for deleting a data item, allowing the user
+// to input the ID and sending the delete request to the server.
+// While the server is not available, the deletion of the local copy is recorded instead.
+func (t *TUI) deleteData() {
 	form := tview.NewForm()
 	form.
 		AddInputField("ID", "", 40, nil, nil).
-		AddDropDown("Type", []string{"binary", "text", "credentials", "bank card"}, 0, nil).
-		AddInputField("Data", "", 40, nil, nil).
-		AddInputField("Meta", "", 20, nil, nil).
 		AddButton("Submit", func() {
 			idField := form.GetFormItemByLabel("ID").(*tview.InputField).GetText()
-			_, typeField := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
-			dataField := form.GetFormItemByLabel("Data").(*tview.InputField).GetText()
-			metaField := form.GetFormItemByLabel("Meta").(*tview.InputField).GetText()
 
-			req := &proto.UpdateDataRequest{
-				Data: &proto.DataItem{
-					Id:   idField,
-					Type: typeField,
-					Data: []byte(dataField),
-					Meta: metaField,
-				},
+			if !t.client.ServerAvailable {
+				t.deleteOffline(idField)
+				return
+			}
+
+			req := &proto.DeleteDataRequest{
+				Id: idField,
 			}
 
 			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
 			defer cancel()
 
-			resp, err := t.client.UpdateData(ctx, req)
+			resp, err := t.client.DeleteData(ctx, req)
 			if err != nil {
-				log.Printf("failed to get data: %v", err)
-				t.showMessage("Failed to get data. Press Enter to go back.", t.showMainMenu)
+				t.showMessage(fmt.Sprintf("Failed to delete data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
 				return
 			}
 			if len(resp.Message) > 0 {
-				log.Printf("UpdateData response: %s", resp.Message)
-				t.showMessage("Data updated successfully. Press Enter to go back.", t.showMainMenu)
+				t.removeLocally(idField)
+				t.showMessage("Data moved to trash. Press Enter to go back.", t.showMainMenu)
 			} else {
-				log.Printf("UpdateData response: no data found")
 				t.showMessage("No data found. Press Enter to go back.", t.showMainMenu)
 			}
 		}).
@@ -361,9 +865,87 @@ func (t *TUI) updateData() {
 	t.app.SetRoot(form, true).SetFocus(form)
 }
 
-// deleteData displays a form for deleting a data item, allowing the user
-// to input the ID and sending the delete request to the server.
-func (t *TUI) deleteData() {
+// deleteOffline records the deletion of the local copy of the item, the item is moved
+// to the trash when the server is available again.
+func (t *TUI) deleteOffline(id string) {
+	local, found, err := t.vault.Get(id)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to delete data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+	if !found {
+		t.showMessage("Server not available and the data is not in the local vault. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	if err = t.recordOffline(vault.OpDelete, local, local.Version); err != nil {
+		t.showMessage(fmt.Sprintf("Failed to delete data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	t.showMessage("Server not available, the data is moved to trash when the server is back. Press Enter to go back.", t.showMainMenu)
+}
+
+// listTrash displays the deleted data items with a form to restore one of them.
+func (t *TUI) listTrash() {
+	if !t.client.ServerAvailable {
+		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.ListTrash(ctx)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to list trash.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	var builder strings.Builder
+	for _, item := range resp.Data {
+		builder.WriteString(fmt.Sprintf("ID: %s\nType: %s\nMeta: %s\nDeleted At: %s\n\n",
+			item.Id, item.Type, item.Meta,
+			item.DeletedAt.AsTime().Format(time.RFC3339),
+		))
+	}
+	if len(resp.Data) == 0 {
+		builder.WriteString("Trash is empty.")
+	}
+
+	items := tview.NewTextView().SetText(builder.String())
+
+	form := tview.NewForm()
+	form.
+		AddInputField("ID", "", 40, nil, nil).
+		AddButton("Restore", func() {
+			idField := form.GetFormItemByLabel("ID").(*tview.InputField).GetText()
+
+			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+			defer cancel()
+
+			_, err := t.client.RestoreData(ctx, &proto.RestoreDataRequest{Id: idField})
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to restore data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+				return
+			}
+
+			t.showMessage("Data restored successfully. Press Enter to go back.", t.showMainMenu)
+		}).
+		AddButton("Cancel", func() {
+			t.showMainMenu()
+		})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(items, 0, 1, false).
+		AddItem(form, 7, 0, true)
+
+	t.app.SetRoot(layout, true).SetFocus(form)
+}
+
+// history displays a form asking for the ID of a data item, followed by the list of its versions.
+func (t *TUI) history() {
 	if !t.client.ServerAvailable {
 		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
 		return
@@ -374,36 +956,287 @@ func (t *TUI) deleteData() {
 		AddInputField("ID", "", 40, nil, nil).
 		AddButton("Submit", func() {
 			idField := form.GetFormItemByLabel("ID").(*tview.InputField).GetText()
+			t.listVersions(idField)
+		}).
+		AddButton("Cancel", func() {
+			t.showMainMenu()
+		})
 
-			req := &proto.DeleteDataRequest{
-				Id: idField,
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// listVersions displays the versions of the data item with a form to show or restore one of them.
+func (t *TUI) listVersions(id string) {
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.ListVersions(ctx, &proto.ListVersionsRequest{Id: id})
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to list versions.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	var builder strings.Builder
+	for i, version := range resp.Versions {
+		current := ""
+		if i == 0 {
+			current = " (current)"
+		}
+		builder.WriteString(fmt.Sprintf("Version: %d%s\nType: %s\nMeta: %s\nUpdated At: %s\n\n",
+			version.Version, current, version.Type, version.Meta,
+			version.UpdatedAt.AsTime().Format(time.RFC3339),
+		))
+	}
+
+	versions := tview.NewTextView().SetText(builder.String())
+
+	form := tview.NewForm()
+	form.
+		AddInputField("Version", "", 10, tview.InputFieldInteger, nil).
+		AddButton("Show", func() {
+			version, ok := t.versionField(form)
+			if !ok {
+				return
+			}
+			t.showVersion(id, version)
+		}).
+		AddButton("Restore", func() {
+			version, ok := t.versionField(form)
+			if !ok {
+				return
 			}
 
 			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
 			defer cancel()
 
-			resp, err := t.client.DeleteData(ctx, req)
+			resp, err := t.client.RestoreVersion(ctx, &proto.RestoreVersionRequest{Id: id, Version: version})
 			if err != nil {
-				t.showMessage("Failed to delete data. Press Enter to go back.", t.showMainMenu)
+				t.showMessage(fmt.Sprintf("Failed to restore version.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
 				return
 			}
-			if len(resp.Message) > 0 {
-				t.showMessage("Data deleted successfully. Press Enter to go back.", t.showMainMenu)
 
-				err = t.cache.Del(context.Background(), idField).Err()
-				if err != nil {
-					t.showMessage(fmt.Sprintf("Failed to delete data from cache: %v", err), t.showMainMenu)
-					return
-				}
-			} else {
-				t.showMessage("No data found. Press Enter to go back.", t.showMainMenu)
+			t.storeLocally(resp.Data)
+
+			t.showMessage(fmt.Sprintf("Version %d restored as version %d. Press Enter to go back.", version, resp.Data.Version), t.showMainMenu)
+		}).
+		AddButton("Cancel", func() {
+			t.showMainMenu()
+		})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(versions, 0, 1, false).
+		AddItem(form, 7, 0, true)
+
+	t.app.SetRoot(layout, true).SetFocus(form)
+}
+
+// showVersion displays the content of the version of the data item.
+// The content of binary items is downloaded to a file instead.
+func (t *TUI) showVersion(id string, version int32) {
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.GetVersion(ctx, &proto.GetVersionRequest{Id: id, Version: version})
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to get version.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	if resp.Data.Type == payload.BinaryType {
+		fileName := fmt.Sprintf("downloaded_file_%s_v%d", id, version)
+		if err = t.downloadBinaryVersion(id, version, fileName); err != nil {
+			t.showMessage(fmt.Sprintf("Failed to download file.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+			return
+		}
+		t.showMessage(fmt.Sprintf("File downloaded and saved as %s. Press Enter to go back.", fileName), t.showMainMenu)
+		return
+	}
+
+	t.showDataItemMessage(resp.Data, "Press Enter to go back.", t.showMainMenu)
+}
+
+// versionField returns the version number entered in the form, reporting an invalid one to the user.
+func (t *TUI) versionField(form *tview.Form) (int32, bool) {
+	text := form.GetFormItemByLabel("Version").(*tview.InputField).GetText()
+
+	version, err := strconv.ParseInt(text, 10, 32)
+	if err != nil || version <= 0 {
+		t.showMessage("Version must be a positive number. Press Enter to go back.", t.showMainMenu)
+		return 0, false
+	}
+
+	return int32(version), true
+}
+
+// listSessions displays the active sessions of the user with a form to revoke one of them.
+func (t *TUI) listSessions() {
+	if !t.client.ServerAvailable {
+		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.ListSessions(ctx)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to list sessions.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	var builder strings.Builder
+	for _, session := range resp.Sessions {
+		current := ""
+		if session.Current {
+			current = " (current)"
+		}
+		builder.WriteString(fmt.Sprintf("ID: %s%s\nClient: %s\nCreated At: %s\nLast Used At: %s\n\n",
+			session.Id, current, session.UserAgent,
+			session.CreatedAt.AsTime().Format(time.RFC3339),
+			session.LastUsedAt.AsTime().Format(time.RFC3339),
+		))
+	}
+
+	sessions := tview.NewTextView().SetText(builder.String())
+
+	form := tview.NewForm()
+	form.
+		AddInputField("Session ID", "", 40, nil, nil).
+		AddButton("Revoke", func() {
+			idField := form.GetFormItemByLabel("Session ID").(*tview.InputField).GetText()
+
+			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+			defer cancel()
+
+			_, err := t.client.RevokeSession(ctx, &proto.RevokeSessionRequest{Id: idField})
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to revoke session.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+				return
 			}
+
+			t.showMessage("Session revoked successfully. Press Enter to go back.", t.showMainMenu)
 		}).
 		AddButton("Cancel", func() {
 			t.showMainMenu()
 		})
 
-	t.app.SetRoot(form, true).SetFocus(form)
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(sessions, 0, 1, false).
+		AddItem(form, 7, 0, true)
+
+	t.app.SetRoot(layout, true).SetFocus(form)
+}
+
+// logout ends the current session on the server, removes the session file and returns to the start screen.
+func (t *TUI) logout() {
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	_, err := t.client.Logout(ctx)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to log out.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	t.vault.Lock()
+	t.forgetSession()
+
+	t.showMessage("Logged out. Press Enter to continue.", t.restart)
+}
+
+// syncData synchronizes the data with the server, sending the changes made offline along with it,
+// storing the items that changed on the server since the last synchronization in the local vault,
+// dropping the deleted ones, and reporting the detected conflicts.
+func (t *TUI) syncData() {
+	if !t.client.ServerAvailable {
+		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	resp, applied, conflicts, err := t.syncWithServer()
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to sync data.\n%s\nPress Enter to go back.", errorDetails(err)), t.showMainMenu)
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Sync completed. Updated items: %d\n\n", len(resp.Data)))
+	if applied > 0 {
+		builder.WriteString(fmt.Sprintf("Changes made offline sent: %d\n", applied))
+	}
+	if conflicts > 0 {
+		builder.WriteString(fmt.Sprintf("Changes made offline conflicting with the server: %d, resolve them in Offline changes.\n", conflicts))
+	}
+	builder.WriteString("Press Enter to go back.")
+
+	t.showMessage(builder.String(), t.showMainMenu)
+}
+
+// storeLocally keeps the data item in the local vault, logging the failure instead of returning it.
+func (t *TUI) storeLocally(item *proto.DataItem) {
+	if err := t.vault.Put(item); err != nil {
+		log.Printf("Failed to store data in the local vault: %v", err)
+	}
+}
+
+// removeLocally removes the data item from the local vault, logging the failure instead of returning it.
+func (t *TUI) removeLocally(id string) {
+	if err := t.vault.Delete(id); err != nil {
+		log.Printf("Failed to delete data from the local vault: %v", err)
+	}
+}
+
+// maskedSecret replaces a secret shown masked, hiding its length as well.
+const maskedSecret = "********"
+
+// maskSecret returns the mask shown instead of the secret, nothing for an empty one.
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return maskedSecret
+}
+
+// maskCardNumber masks the card number but its last four digits, so the card can be told apart.
+func maskCardNumber(number string) string {
+	digits := strings.ReplaceAll(number, " ", "")
+	if len(digits) <= 4 {
+		return maskSecret(number)
+	}
+
+	return "**** " + digits[len(digits)-4:]
+}
+
+// showDataItemMessage displays the data item with its secrets masked, followed by the footer.
+// The secrets are revealed and masked again with the r key, Enter returns to the specified function.
+func (t *TUI) showDataItemMessage(item *proto.DataItem, footer string, doneFunc func()) {
+	reveal := false
+	textView := tview.NewTextView()
+	render := func() {
+		hint := "Press r to reveal the secrets."
+		if reveal {
+			hint = "Press r to mask the secrets."
+		}
+		textView.SetText(formatDataItem(item, reveal) + footer + "\n" + hint)
+	}
+	render()
+
+	textView.
+		SetDoneFunc(func(key tcell.Key) {
+			doneFunc()
+		}).
+		SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyRune && event.Rune() == 'r' {
+				reveal = !reveal
+				render()
+				return nil
+			}
+			return event
+		})
+	t.app.SetRoot(textView, true).SetFocus(textView)
 }
 
 // showMessage displays a message to the user with a prompt to press Enter to continue,
@@ -427,12 +1260,109 @@ func (t *TUI) restart() {
 	}()
 }
 
-// formatDataItem returns specified string format for Data Item.
-func formatDataItem(item *proto.DataItem) string {
+// formatDataItem returns specified string format for Data Item. The passwords, the card numbers,
+// the CVV codes, the TOTP secrets and the texts are masked unless they are revealed.
+func formatDataItem(item *proto.DataItem, reveal bool) string {
+	secret := maskSecret
+	cardNumber := maskCardNumber
+	if reveal {
+		secret = func(value string) string { return value }
+		cardNumber = secret
+	}
+
+	var data string
+	switch p := item.Payload.(type) {
+	case *proto.DataItem_Credentials:
+		data = fmt.Sprintf("Login: %s\nPassword: %s\nURL: %s\nTOTP secret: %s",
+			p.Credentials.Login, secret(p.Credentials.Password), p.Credentials.Url, secret(p.Credentials.TotpSecret))
+	case *proto.DataItem_BankCard:
+		data = fmt.Sprintf("Number: %s\nHolder: %s\nExpiry: %s\nCVV: %s",
+			cardNumber(p.BankCard.Number), p.BankCard.Holder, p.BankCard.Expiry, secret(p.BankCard.Cvv))
+	case *proto.DataItem_Text:
+		data = fmt.Sprintf("Text: %s", secret(p.Text.Content))
+	case *proto.DataItem_Binary:
+		data = fmt.Sprintf("Binary: %d bytes", len(p.Binary.Content))
+	default:
+		data = fmt.Sprintf("Data: %s", string(item.Data))
+	}
+
 	return fmt.Sprintf(
-		"ID: %s\nType: %s\nData: %s\nMeta: %s\nCreated At: %s\nUpdated At: %s\n\n",
-		item.Id, item.Type, string(item.Data), item.Meta,
+		"ID: %s\nType: %s\nVersion: %d\n%s\nMeta: %s\n%sCreated At: %s\nUpdated At: %s\n\n",
+		item.Id, item.Type, item.Version, data, item.Meta, formatMetadata(item.Metadata),
 		item.CreatedAt.AsTime().Format(time.RFC3339),
 		item.UpdatedAt.AsTime().Format(time.RFC3339),
 	)
 }
+
+// summaryLabel returns the label of the data item in the list, which is its name, its meta
+// or its ID if the item has neither.
+func summaryLabel(item *proto.DataItemSummary) string {
+	if name := item.GetMetadata().GetName(); name != "" {
+		return name
+	}
+	if item.Meta != "" {
+		return item.Meta
+	}
+	return item.Id
+}
+
+// summaryDetails returns the secondary text of the data item in the list.
+func summaryDetails(item *proto.DataItemSummary) string {
+	details := fmt.Sprintf("%s, %s, updated %s",
+		item.Type, formatSize(item.Size),
+		item.UpdatedAt.AsTime().Local().Format("2006-01-02 15:04"),
+	)
+	if tags := item.GetMetadata().GetTags(); len(tags) > 0 {
+		details += ", #" + strings.Join(tags, " #")
+	}
+	return details
+}
+
+// formatSize returns the size in bytes in a human-readable form.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// errorDetails returns a human-readable description of the error,
+// listing every invalid field reported by the client or the server.
+func errorDetails(err error) string {
+	return client.ErrorMessage(err)
+}
+
+// checkNewPassword checks the new password against the password policy of the server.
+// It returns the description of the problem, empty if the password is acceptable.
+func (t *TUI) checkNewPassword(password, username string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	policy, err := t.client.PasswordPolicy(ctx)
+	if err != nil {
+		return fmt.Sprintf("Failed to get the password policy.\n%s", errorDetails(err))
+	}
+	if violations := policy.Check(password, username); len(violations) > 0 {
+		return passwordViolations(violations)
+	}
+
+	return ""
+}
+
+// passwordViolations describes the requirements of the password policy the password does not meet.
+func passwordViolations(violations []string) string {
+	var builder strings.Builder
+	builder.WriteString("Weak password:")
+	for _, v := range violations {
+		builder.WriteString("\n- password " + v)
+	}
+
+	return builder.String()
+}
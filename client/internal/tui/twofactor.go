@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/skip2/go-qrcode"
+	proto "gophKeeper/pkg/proto/gophkeeper"
+)
+
+// verifyLoginTwoFactor displays the code entry of a login of an account with two-factor
+// authentication, completing the login challenge with a TOTP or recovery code.
+func (t *TUI) verifyLoginTwoFactor(challengeToken, masterPassword string) {
+	form := tview.NewForm()
+	form.
+		AddInputField("Code or recovery code", "", 20, nil, nil).
+		AddButton("Verify", func() {
+			code := form.GetFormItemByLabel("Code or recovery code").(*tview.InputField).GetText()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			defer cancel()
+
+			resp, err := t.client.VerifyLoginTwoFactor(ctx, &proto.VerifyLoginTwoFactorRequest{
+				ChallengeToken: challengeToken,
+				Code:           code,
+			})
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Verification failed.\n%s\nPress Enter to go back.", errorDetails(err)), func() {
+					t.verifyLoginTwoFactor(challengeToken, masterPassword)
+				})
+				return
+			}
+
+			t.finishLogin(masterPassword, resp.KdfSalt, resp.KeyCheck)
+		}).
+		AddButton("Cancel", func() {
+			t.restart()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
+
+// twoFactor displays the options to enable and disable two-factor authentication.
+func (t *TUI) twoFactor() {
+	if !t.client.ServerAvailable {
+		t.showMessage("Server not available. Press Enter to go back.", t.showMainMenu)
+		return
+	}
+
+	list := tview.NewList().
+		AddItem("Enable", "Add an authenticator app to the account", 'e', t.enrollTwoFactor).
+		AddItem("Disable", "Turn off two-factor authentication", 'd', t.disableTwoFactor).
+		AddItem("Back", "Return to the main menu", 'b', t.showMainMenu)
+
+	t.app.SetRoot(list, true).SetFocus(list)
+}
+
+// enrollTwoFactor generates a new TOTP secret and displays it as a QR code to scan
+// with the authenticator app, followed by the code entry confirming it.
+func (t *TUI) enrollTwoFactor() {
+	ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+	defer cancel()
+
+	resp, err := t.client.EnrollTwoFactor(ctx)
+	if err != nil {
+		t.showMessage(fmt.Sprintf("Failed to enable two-factor authentication.\n%s\nPress Enter to go back.", errorDetails(err)), t.twoFactor)
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Scan the QR code with your authenticator app or enter the secret manually.\n\n")
+	if code, err := qrcode.New(resp.ProvisioningUri, qrcode.Medium); err == nil {
+		// dark modules are drawn blank, so the code scans on the dark background of terminals
+		builder.WriteString(code.ToSmallString(true))
+	}
+	builder.WriteString(fmt.Sprintf("\nSecret: %s\n", resp.Secret))
+
+	enrollment := tview.NewTextView().SetText(builder.String())
+
+	form := tview.NewForm()
+	form.
+		AddInputField("Code", "", 10, nil, nil).
+		AddButton("Confirm", func() {
+			code := form.GetFormItemByLabel("Code").(*tview.InputField).GetText()
+
+			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+			defer cancel()
+
+			resp, err := t.client.ConfirmTwoFactor(ctx, &proto.ConfirmTwoFactorRequest{Code: code})
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to confirm the code.\n%s\nPress Enter to go back.", errorDetails(err)), t.twoFactor)
+				return
+			}
+
+			t.showMessage(fmt.Sprintf("Two-factor authentication enabled.\n\n"+
+				"Store these recovery codes in a safe place, each of them logs in once without the app:\n\n%s\n\nPress Enter to go back.",
+				strings.Join(resp.RecoveryCodes, "\n")), t.showMainMenu)
+		}).
+		AddButton("Cancel", func() {
+			t.twoFactor()
+		})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(enrollment, 0, 1, false).
+		AddItem(form, 7, 0, true)
+
+	t.app.SetRoot(layout, true).SetFocus(form)
+}
+
+// disableTwoFactor displays the code entry turning off two-factor authentication.
+func (t *TUI) disableTwoFactor() {
+	form := tview.NewForm()
+	form.
+		AddInputField("Code or recovery code", "", 20, nil, nil).
+		AddButton("Disable", func() {
+			code := form.GetFormItemByLabel("Code or recovery code").(*tview.InputField).GetText()
+
+			ctx, cancel := t.client.CreateContextWithMetadata(15 * time.Second)
+			defer cancel()
+
+			_, err := t.client.DisableTwoFactor(ctx, &proto.DisableTwoFactorRequest{Code: code})
+			if err != nil {
+				t.showMessage(fmt.Sprintf("Failed to disable two-factor authentication.\n%s\nPress Enter to go back.", errorDetails(err)), t.twoFactor)
+				return
+			}
+
+			t.showMessage("Two-factor authentication disabled. Press Enter to go back.", t.showMainMenu)
+		}).
+		AddButton("Cancel", func() {
+			t.twoFactor()
+		})
+
+	t.app.SetRoot(form, true).SetFocus(form)
+}
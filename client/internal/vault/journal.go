@@ -0,0 +1,290 @@
+package vault
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+	"gophKeeper/client/internal/crypto"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"strconv"
+	"time"
+)
+
+// Operation is the kind of change recorded in the journal.
+type Operation string
+
+const (
+	OpCreate Operation = "create"
+	OpUpdate Operation = "update"
+	OpDelete Operation = "delete"
+)
+
+var journalBucket = []byte("journal")
+
+// JournalEntry is a change made while the server was not available, waiting to be sent to the server.
+type JournalEntry struct {
+	// Seq orders the entries, the entries are replayed in the order of their sequence numbers.
+	Seq uint64
+	Op  Operation
+	// Item holds the changed item, the deleted one for OpDelete.
+	Item *pb.DataItem
+	// BaseVersion is the version of the item on the server the change was made to,
+	// the change conflicts with the server if the item has another version there.
+	BaseVersion int32
+	RecordedAt  time.Time
+
+	// Conflicted is set when the replay found the item changed or deleted on the server,
+	// the entry is kept until the user resolves the conflict.
+	Conflicted bool
+	// Server holds the server copy of the conflicted item, nil if it was deleted on the server.
+	Server *pb.DataItem
+}
+
+// journalRecord is the stored form of JournalEntry, sealed with the master key.
+type journalRecord struct {
+	Op          Operation `json:"op"`
+	Item        []byte    `json:"item"`
+	BaseVersion int32     `json:"base_version"`
+	RecordedAt  time.Time `json:"recorded_at"`
+	Conflicted  bool      `json:"conflicted,omitempty"`
+	Server      []byte    `json:"server,omitempty"`
+}
+
+// Record appends the change of the item to the journal. A change of an item with a pending entry
+// is merged into that entry, so the journal holds a single entry per item: an update of a created
+// item stays a create, a delete of a created item drops the entry, a delete of an updated item
+// becomes a delete of the original version.
+func (v *Vault) Record(op Operation, item *pb.DataItem, baseVersion int32) error {
+	bucket, cipher, err := v.unlocked()
+	if err != nil {
+		return err
+	}
+
+	return v.db.Update(func(tx *bbolt.Tx) error {
+		journal, err := userBucket(tx, bucket, journalBucket)
+		if err != nil {
+			return err
+		}
+
+		entries, err := readJournal(journal, cipher, bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.Item.GetId() != item.GetId() || entry.Op == OpDelete {
+				continue
+			}
+
+			switch {
+			case entry.Op == OpCreate && op == OpDelete:
+				return journal.Delete(seqKey(entry.Seq))
+			case entry.Op == OpCreate:
+				entry.Item = item
+			default:
+				entry.Op = op
+				entry.Item = item
+			}
+			entry.RecordedAt = time.Now()
+
+			return putJournalEntry(journal, cipher, bucket, entry)
+		}
+
+		seq, err := journal.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return putJournalEntry(journal, cipher, bucket, &JournalEntry{
+			Seq:         seq,
+			Op:          op,
+			Item:        item,
+			BaseVersion: baseVersion,
+			RecordedAt:  time.Now(),
+		})
+	})
+}
+
+// Journal returns the pending entries in the order they are replayed.
+func (v *Vault) Journal() ([]*JournalEntry, error) {
+	bucket, cipher, err := v.unlocked()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*JournalEntry
+	err = v.db.View(func(tx *bbolt.Tx) error {
+		journal := existingBucket(tx, bucket, journalBucket)
+		if journal == nil {
+			return nil
+		}
+
+		entries, err = readJournal(journal, cipher, bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// MarkConflict marks the entry as conflicting with the server copy of the item, nil if the item
+// was deleted on the server.
+func (v *Vault) MarkConflict(seq uint64, server *pb.DataItem) error {
+	return v.updateJournalEntry(seq, func(entry *JournalEntry) {
+		entry.Conflicted = true
+		entry.Server = server
+	})
+}
+
+// Rebase resolves the conflict of the entry in favor of the local change, which is replayed
+// on top of the given server version of the item. A create of an item existing on the server
+// becomes its update.
+func (v *Vault) Rebase(seq uint64, baseVersion int32) error {
+	return v.updateJournalEntry(seq, func(entry *JournalEntry) {
+		if entry.Op == OpCreate {
+			entry.Op = OpUpdate
+		}
+		entry.BaseVersion = baseVersion
+		entry.Conflicted = false
+		entry.Server = nil
+	})
+}
+
+// Ack removes the entry from the journal once it is applied on the server or discarded.
+func (v *Vault) Ack(seq uint64) error {
+	bucket, _, err := v.unlocked()
+	if err != nil {
+		return err
+	}
+
+	return v.db.Update(func(tx *bbolt.Tx) error {
+		if journal := existingBucket(tx, bucket, journalBucket); journal != nil {
+			return journal.Delete(seqKey(seq))
+		}
+		return nil
+	})
+}
+
+// updateJournalEntry applies the change to the stored entry, a missing entry is not an error.
+func (v *Vault) updateJournalEntry(seq uint64, change func(entry *JournalEntry)) error {
+	bucket, cipher, err := v.unlocked()
+	if err != nil {
+		return err
+	}
+
+	return v.db.Update(func(tx *bbolt.Tx) error {
+		journal := existingBucket(tx, bucket, journalBucket)
+		if journal == nil {
+			return nil
+		}
+
+		sealed := journal.Get(seqKey(seq))
+		if sealed == nil {
+			return nil
+		}
+
+		entry, err := openJournalEntry(cipher, bucket, seq, sealed)
+		if err != nil {
+			return err
+		}
+		change(entry)
+
+		return putJournalEntry(journal, cipher, bucket, entry)
+	})
+}
+
+// readJournal decrypts all entries of the journal bucket, the keys keep them in sequence order.
+func readJournal(journal *bbolt.Bucket, cipher *crypto.Cipher, bucket []byte) ([]*JournalEntry, error) {
+	var entries []*JournalEntry
+	err := journal.ForEach(func(key, sealed []byte) error {
+		entry, err := openJournalEntry(cipher, bucket, binary.BigEndian.Uint64(key), sealed)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+
+	return entries, err
+}
+
+// putJournalEntry seals and stores the entry under its sequence number.
+func putJournalEntry(journal *bbolt.Bucket, cipher *crypto.Cipher, bucket []byte, entry *JournalEntry) error {
+	record := journalRecord{
+		Op:          entry.Op,
+		BaseVersion: entry.BaseVersion,
+		RecordedAt:  entry.RecordedAt,
+		Conflicted:  entry.Conflicted,
+	}
+
+	var err error
+	if record.Item, err = proto.Marshal(entry.Item); err != nil {
+		return fmt.Errorf("marshal journal entry %d: %w", entry.Seq, err)
+	}
+	if entry.Server != nil {
+		if record.Server, err = proto.Marshal(entry.Server); err != nil {
+			return fmt.Errorf("marshal journal entry %d: %w", entry.Seq, err)
+		}
+	}
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal journal entry %d: %w", entry.Seq, err)
+	}
+
+	sealed, err := cipher.Seal(value, journalAdditionalData(bucket, entry.Seq))
+	if err != nil {
+		return fmt.Errorf("encrypt journal entry %d: %w", entry.Seq, err)
+	}
+
+	return journal.Put(seqKey(entry.Seq), sealed)
+}
+
+// openJournalEntry decrypts and parses the sealed journal entry.
+func openJournalEntry(cipher *crypto.Cipher, bucket []byte, seq uint64, sealed []byte) (*JournalEntry, error) {
+	value, err := cipher.Open(sealed, journalAdditionalData(bucket, seq))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt journal entry %d: %w", seq, err)
+	}
+
+	var record journalRecord
+	if err = json.Unmarshal(value, &record); err != nil {
+		return nil, fmt.Errorf("unmarshal journal entry %d: %w", seq, err)
+	}
+
+	entry := &JournalEntry{
+		Seq:         seq,
+		Op:          record.Op,
+		Item:        &pb.DataItem{},
+		BaseVersion: record.BaseVersion,
+		RecordedAt:  record.RecordedAt,
+		Conflicted:  record.Conflicted,
+	}
+	if err = proto.Unmarshal(record.Item, entry.Item); err != nil {
+		return nil, fmt.Errorf("unmarshal journal entry %d: %w", seq, err)
+	}
+	if record.Server != nil {
+		entry.Server = &pb.DataItem{}
+		if err = proto.Unmarshal(record.Server, entry.Server); err != nil {
+			return nil, fmt.Errorf("unmarshal journal entry %d: %w", seq, err)
+		}
+	}
+
+	return entry, nil
+}
+
+// seqKey encodes the sequence number as a big-endian key, so bbolt iterates the entries in order.
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+// journalAdditionalData binds the sealed entry to the user and its sequence number.
+func journalAdditionalData(bucket []byte, seq uint64) []byte {
+	return []byte("vault:" + string(bucket) + ":journal:" + strconv.FormatUint(seq, 10))
+}
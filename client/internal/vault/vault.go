@@ -0,0 +1,376 @@
+// Package vault implements the encrypted on-disk store of the GophKeeper client, a bbolt file
+// keeping the data items of the users who logged in on this machine, so the client works offline,
+// and the journal of the changes made offline, replayed when the server is available again.
+// Every user has a bucket of its own. The records of the items and the journal entries are sealed
+// with the master key of the user, only the IDs of the items, the kdf salt and the time of the last
+// synchronization are stored in plaintext.
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+	"gophKeeper/client/internal/crypto"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// fileMode restricts the vault file to its owner.
+	fileMode = 0o600
+	// openTimeout is how long Open waits for another client holding the vault file.
+	openTimeout = time.Second
+
+	// checkValue is sealed into the key check record, which tells whether the master key is right.
+	checkValue = "gophkeeper-vault"
+)
+
+var (
+	itemsBucket = []byte("items")
+	metaBucket  = []byte("meta")
+
+	kdfSaltKey      = []byte("kdf_salt")
+	keyCheckKey     = []byte("key_check")
+	lastSyncedAtKey = []byte("last_synced_at")
+)
+
+var (
+	// ErrLocked is returned when the items are accessed before the vault is unlocked.
+	ErrLocked = errors.New("vault is locked")
+	// ErrWrongKey is returned when the vault of the user is unlocked with another master key.
+	ErrWrongKey = errors.New("wrong master password for the local vault")
+)
+
+// Vault is the encrypted store of the data items, unlocked for a single user at a time.
+type Vault struct {
+	db *bbolt.DB
+
+	mu     sync.RWMutex
+	bucket []byte
+	cipher *crypto.Cipher
+}
+
+// Open opens the vault file at the path, creating it and its directory if they do not exist.
+func Open(path string) (*Vault, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create vault directory: %w", err)
+	}
+
+	db, err := bbolt.Open(path, fileMode, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open vault %s: %w", path, err)
+	}
+
+	return &Vault{db: db}, nil
+}
+
+// DefaultPath returns the path of the vault file in the configuration directory of the user.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "gophkeeper", "vault.db")
+}
+
+// Close closes the vault file.
+func (v *Vault) Close() error {
+	return v.db.Close()
+}
+
+// SaveKdfSalt remembers the kdf salt of the user received on login,
+// so the master key can be derived when the server is not available.
+func (v *Vault) SaveKdfSalt(username string, kdfSalt []byte) error {
+	return v.db.Update(func(tx *bbolt.Tx) error {
+		meta, err := userBucket(tx, userBucketName(username), metaBucket)
+		if err != nil {
+			return err
+		}
+
+		return meta.Put(kdfSaltKey, kdfSalt)
+	})
+}
+
+// KdfSalt returns the kdf salt of the user saved on the last login, and whether there is one.
+func (v *Vault) KdfSalt(username string) ([]byte, bool, error) {
+	var kdfSalt []byte
+
+	err := v.db.View(func(tx *bbolt.Tx) error {
+		if meta := existingBucket(tx, userBucketName(username), metaBucket); meta != nil {
+			kdfSalt = cloneBytes(meta.Get(kdfSaltKey))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return kdfSalt, kdfSalt != nil, nil
+}
+
+// Unlock opens the vault of the user with the cipher of the master key. The first unlock
+// stores a key check record, the following ones return ErrWrongKey for another key.
+func (v *Vault) Unlock(username string, cipher *crypto.Cipher) error {
+	bucket := userBucketName(username)
+
+	err := v.db.Update(func(tx *bbolt.Tx) error {
+		meta, err := userBucket(tx, bucket, metaBucket)
+		if err != nil {
+			return err
+		}
+
+		additionalData := append(append([]byte{}, bucket...), keyCheckKey...)
+		if check := meta.Get(keyCheckKey); check != nil {
+			if value, err := cipher.Open(check, additionalData); err != nil || string(value) != checkValue {
+				return ErrWrongKey
+			}
+			return nil
+		}
+
+		check, err := cipher.Seal([]byte(checkValue), additionalData)
+		if err != nil {
+			return err
+		}
+
+		return meta.Put(keyCheckKey, check)
+	})
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.bucket = bucket
+	v.cipher = cipher
+	return nil
+}
+
+// Lock forgets the master key, the items can not be accessed until the vault is unlocked again.
+func (v *Vault) Lock() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.bucket = nil
+	v.cipher = nil
+}
+
+// Put stores the data item, replacing the stored one with the same ID.
+func (v *Vault) Put(item *pb.DataItem) error {
+	bucket, cipher, err := v.unlocked()
+	if err != nil {
+		return err
+	}
+
+	record, err := proto.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("marshal item %s: %w", item.GetId(), err)
+	}
+
+	sealed, err := cipher.Seal(record, itemAdditionalData(bucket, item.GetId()))
+	if err != nil {
+		return fmt.Errorf("encrypt item %s: %w", item.GetId(), err)
+	}
+
+	return v.db.Update(func(tx *bbolt.Tx) error {
+		items, err := userBucket(tx, bucket, itemsBucket)
+		if err != nil {
+			return err
+		}
+
+		return items.Put([]byte(item.GetId()), sealed)
+	})
+}
+
+// Get returns the stored data item with the ID, and whether it is stored.
+func (v *Vault) Get(id string) (*pb.DataItem, bool, error) {
+	bucket, cipher, err := v.unlocked()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var sealed []byte
+	err = v.db.View(func(tx *bbolt.Tx) error {
+		if items := existingBucket(tx, bucket, itemsBucket); items != nil {
+			sealed = cloneBytes(items.Get([]byte(id)))
+		}
+		return nil
+	})
+	if err != nil || sealed == nil {
+		return nil, false, err
+	}
+
+	item, err := openItem(cipher, bucket, id, sealed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return item, true, nil
+}
+
+// List returns all stored data items ordered by the time of their last update, the latest first.
+func (v *Vault) List() ([]*pb.DataItem, error) {
+	bucket, cipher, err := v.unlocked()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*pb.DataItem
+	err = v.db.View(func(tx *bbolt.Tx) error {
+		items := existingBucket(tx, bucket, itemsBucket)
+		if items == nil {
+			return nil
+		}
+
+		return items.ForEach(func(id, sealed []byte) error {
+			item, err := openItem(cipher, bucket, string(id), sealed)
+			if err != nil {
+				return err
+			}
+			result = append(result, item)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].GetUpdatedAt().AsTime().After(result[j].GetUpdatedAt().AsTime())
+	})
+
+	return result, nil
+}
+
+// Delete removes the stored data item with the ID, deleting a missing item is not an error.
+func (v *Vault) Delete(id string) error {
+	bucket, _, err := v.unlocked()
+	if err != nil {
+		return err
+	}
+
+	return v.db.Update(func(tx *bbolt.Tx) error {
+		if items := existingBucket(tx, bucket, itemsBucket); items != nil {
+			return items.Delete([]byte(id))
+		}
+		return nil
+	})
+}
+
+// LastSyncedAt returns the time of the last synchronization with the server, zero if there was none.
+func (v *Vault) LastSyncedAt() (time.Time, error) {
+	bucket, _, err := v.unlocked()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var syncedAt time.Time
+	err = v.db.View(func(tx *bbolt.Tx) error {
+		if meta := existingBucket(tx, bucket, metaBucket); meta != nil {
+			if value := meta.Get(lastSyncedAtKey); value != nil {
+				return syncedAt.UnmarshalBinary(value)
+			}
+		}
+		return nil
+	})
+
+	return syncedAt, err
+}
+
+// SetLastSyncedAt stores the time of the last synchronization with the server.
+func (v *Vault) SetLastSyncedAt(syncedAt time.Time) error {
+	bucket, _, err := v.unlocked()
+	if err != nil {
+		return err
+	}
+
+	value, err := syncedAt.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return v.db.Update(func(tx *bbolt.Tx) error {
+		meta, err := userBucket(tx, bucket, metaBucket)
+		if err != nil {
+			return err
+		}
+
+		return meta.Put(lastSyncedAtKey, value)
+	})
+}
+
+// unlocked returns the bucket and the cipher of the unlocked user, ErrLocked if the vault is locked.
+func (v *Vault) unlocked() ([]byte, *crypto.Cipher, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if v.cipher == nil {
+		return nil, nil, ErrLocked
+	}
+
+	return v.bucket, v.cipher, nil
+}
+
+// openItem decrypts and parses the sealed record of the data item.
+func openItem(cipher *crypto.Cipher, bucket []byte, id string, sealed []byte) (*pb.DataItem, error) {
+	record, err := cipher.Open(sealed, itemAdditionalData(bucket, id))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt item %s: %w", id, err)
+	}
+
+	item := &pb.DataItem{}
+	if err = proto.Unmarshal(record, item); err != nil {
+		return nil, fmt.Errorf("unmarshal item %s: %w", id, err)
+	}
+
+	return item, nil
+}
+
+// itemAdditionalData binds the sealed record to the user and the ID of the item, so records
+// can not be swapped, and separates it from the item data the server stores sealed with the same key.
+func itemAdditionalData(bucket []byte, id string) []byte {
+	return []byte("vault:" + string(bucket) + ":" + id)
+}
+
+// userBucketName returns the name of the bucket of the user, the hash of the username.
+func userBucketName(username string) []byte {
+	sum := sha256.Sum256([]byte(username))
+	return []byte(hex.EncodeToString(sum[:]))
+}
+
+// userBucket returns the nested bucket of the user, creating the buckets if they do not exist.
+func userBucket(tx *bbolt.Tx, user, name []byte) (*bbolt.Bucket, error) {
+	parent, err := tx.CreateBucketIfNotExists(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return parent.CreateBucketIfNotExists(name)
+}
+
+// existingBucket returns the nested bucket of the user, nil if it does not exist.
+func existingBucket(tx *bbolt.Tx, user, name []byte) *bbolt.Bucket {
+	parent := tx.Bucket(user)
+	if parent == nil {
+		return nil
+	}
+
+	return parent.Bucket(name)
+}
+
+// cloneBytes copies a value read from the vault, which is only valid during its transaction.
+func cloneBytes(value []byte) []byte {
+	if value == nil {
+		return nil
+	}
+
+	return append([]byte{}, value...)
+}
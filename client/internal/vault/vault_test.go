@@ -0,0 +1,216 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gophKeeper/client/internal/crypto"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newCipher(t *testing.T, passphrase string) *crypto.Cipher {
+	t.Helper()
+
+	cipher, err := crypto.NewFromPassphrase(passphrase, []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
+
+func TestVault_Items(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+	v, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer v.Close()
+
+	secret := &pb.DataItem{
+		Id:        "1",
+		Type:      "text",
+		Meta:      "notes",
+		Payload:   &pb.DataItem_Text{Text: &pb.Text{Content: "my secret"}},
+		UpdatedAt: timestamppb.New(time.Unix(100, 0)),
+	}
+	newer := &pb.DataItem{Id: "2", Type: "text", UpdatedAt: timestamppb.New(time.Unix(200, 0))}
+
+	if err = v.Put(secret); !errors.Is(err, ErrLocked) {
+		t.Errorf("Put() of a locked vault error = %v, want %v", err, ErrLocked)
+	}
+
+	if err = v.Unlock("alice", newCipher(t, "alice's master password")); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	for _, item := range []*pb.DataItem{secret, newer} {
+		if err = v.Put(item); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	got, found, err := v.Get("1")
+	if err != nil || !found || !proto.Equal(got, secret) {
+		t.Errorf("Get() = %v, %v, %v, want %v", got, found, err, secret)
+	}
+	list, err := v.List()
+	if err != nil || len(list) != 2 || list[0].GetId() != "2" {
+		t.Errorf("List() = %v, %v, want both items, the latest first", list, err)
+	}
+
+	if err = v.Delete("1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, found, _ = v.Get("1"); found {
+		t.Errorf("Get() found a deleted item")
+	}
+
+	if err = v.Unlock("bob", newCipher(t, "bob's master password")); err != nil {
+		t.Fatalf("Unlock() of another user error = %v", err)
+	}
+	if list, _ = v.List(); len(list) != 0 {
+		t.Errorf("List() of another user = %v, want no items", list)
+	}
+
+	if err = v.Unlock("alice", newCipher(t, "wrong master password")); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Unlock() with a wrong key error = %v, want %v", err, ErrWrongKey)
+	}
+
+	v.Lock()
+	if _, _, err = v.Get("2"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Get() after Lock() error = %v, want %v", err, ErrLocked)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("my secret")) || bytes.Contains(raw, []byte("notes")) {
+		t.Errorf("vault file contains the plaintext of the item")
+	}
+}
+
+func TestVault_Meta(t *testing.T) {
+	v, err := Open(filepath.Join(t.TempDir(), "vault.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer v.Close()
+
+	if _, found, err := v.KdfSalt("alice"); found || err != nil {
+		t.Errorf("KdfSalt() of an unknown user found = %v, error = %v", found, err)
+	}
+	if err = v.SaveKdfSalt("alice", []byte("salt")); err != nil {
+		t.Fatalf("SaveKdfSalt() error = %v", err)
+	}
+	if salt, found, err := v.KdfSalt("alice"); !found || err != nil || string(salt) != "salt" {
+		t.Errorf("KdfSalt() = %q, %v, %v, want %q", salt, found, err, "salt")
+	}
+
+	if err = v.Unlock("alice", newCipher(t, "alice's master password")); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if syncedAt, err := v.LastSyncedAt(); err != nil || !syncedAt.IsZero() {
+		t.Errorf("LastSyncedAt() = %v, %v, want zero time", syncedAt, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if err = v.SetLastSyncedAt(now); err != nil {
+		t.Fatalf("SetLastSyncedAt() error = %v", err)
+	}
+	if syncedAt, err := v.LastSyncedAt(); err != nil || !syncedAt.Equal(now) {
+		t.Errorf("LastSyncedAt() = %v, %v, want %v", syncedAt, err, now)
+	}
+}
+
+func TestVault_Journal(t *testing.T) {
+	v, err := Open(filepath.Join(t.TempDir(), "vault.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer v.Close()
+
+	if err = v.Unlock("alice", newCipher(t, "alice's master password")); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	record := func(op Operation, id, content string, baseVersion int32) {
+		t.Helper()
+
+		item := &pb.DataItem{Id: id, Type: "text", Payload: &pb.DataItem_Text{Text: &pb.Text{Content: content}}}
+		if err := v.Record(op, item, baseVersion); err != nil {
+			t.Fatalf("Record(%s, %s) error = %v", op, id, err)
+		}
+	}
+
+	record(OpUpdate, "1", "first edit", 3)
+	record(OpCreate, "2", "created", 0)
+	record(OpCreate, "3", "created", 0)
+	record(OpUpdate, "1", "second edit", 4)
+	record(OpUpdate, "2", "edited after create", 0)
+	record(OpDelete, "3", "", 0)
+
+	entries, err := v.Journal()
+	if err != nil {
+		t.Fatalf("Journal() error = %v", err)
+	}
+
+	type want struct {
+		id          string
+		op          Operation
+		content     string
+		baseVersion int32
+	}
+	wants := []want{
+		{id: "1", op: OpUpdate, content: "second edit", baseVersion: 3},
+		{id: "2", op: OpCreate, content: "edited after create"},
+	}
+	if len(entries) != len(wants) {
+		t.Fatalf("Journal() = %v, want %d entries", entries, len(wants))
+	}
+	for i, w := range wants {
+		got := entries[i]
+		if got.Item.GetId() != w.id || got.Op != w.op || got.Item.GetText().GetContent() != w.content || got.BaseVersion != w.baseVersion {
+			t.Errorf("Journal()[%d] = %s %s %q base %d, want %s %s %q base %d", i,
+				got.Op, got.Item.GetId(), got.Item.GetText().GetContent(), got.BaseVersion, w.op, w.id, w.content, w.baseVersion)
+		}
+	}
+
+	server := &pb.DataItem{Id: "1", Type: "text", Version: 5}
+	if err = v.MarkConflict(entries[0].Seq, server); err != nil {
+		t.Fatalf("MarkConflict() error = %v", err)
+	}
+	if entries, _ = v.Journal(); !entries[0].Conflicted || !proto.Equal(entries[0].Server, server) {
+		t.Errorf("Journal() after MarkConflict() = %v, want a conflict with %v", entries[0], server)
+	}
+
+	if err = v.Rebase(entries[0].Seq, 5); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	if entries, _ = v.Journal(); entries[0].Conflicted || entries[0].Server != nil || entries[0].BaseVersion != 5 {
+		t.Errorf("Journal() after Rebase() = %v, want a pending change of version 5", entries[0])
+	}
+
+	if err = v.Ack(entries[0].Seq); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if entries, _ = v.Journal(); len(entries) != 1 || entries[0].Item.GetId() != "2" {
+		t.Errorf("Journal() after Ack() = %v, want the create of 2", entries)
+	}
+
+	if err = v.Rebase(entries[0].Seq, 1); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	if entries, _ = v.Journal(); entries[0].Op != OpUpdate {
+		t.Errorf("Journal() after Rebase() of a create = %v, want an update", entries[0])
+	}
+
+	v.Lock()
+	if _, err = v.Journal(); !errors.Is(err, ErrLocked) {
+		t.Errorf("Journal() after Lock() error = %v, want %v", err, ErrLocked)
+	}
+}
@@ -0,0 +1,353 @@
+// Package password implements the password policy shared by the server, which checks the passwords
+// of accounts registered with a password, and the client, which checks the passwords it derives
+// SRP verifiers from, as the server never sees them.
+//
+// Besides the minimum length, the policy requires a minimum strength estimated in the spirit
+// of zxcvbn: the password is split into the cheapest sequence of guessable patterns (common
+// passwords, the username, repeats, sequences, keyboard rows and years) and characters guessed
+// by brute force, and the strength is the number of bits of the guesses needed to find it.
+package password
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxCheckedLength bounds the part of the password the strength is estimated for,
+	// as longer passwords are strong enough anyway.
+	maxCheckedLength = 128
+	// minPatternLength is the length of the shortest pattern matched in a password.
+	minPatternLength = 3
+)
+
+// DefaultPolicy is the policy the server uses unless configured otherwise and publishes to the clients.
+var DefaultPolicy = Policy{
+	MinLength:  10,
+	MinEntropy: 40,
+}
+
+var (
+	//go:embed common.txt
+	commonList string
+
+	// commonRanks maps the common passwords to their popularity rank, starting from 1.
+	commonRanks = parseCommon(commonList)
+
+	// keyboardRows lists the rows of the QWERTY keyboard, walking along them is a guessable pattern.
+	keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+
+	// leetSubstitutions maps the characters commonly substituted for letters back to the letters.
+	leetSubstitutions = map[rune]rune{'4': 'a', '@': 'a', '8': 'b', '3': 'e', '6': 'g', '1': 'i', '!': 'i', '0': 'o', '5': 's', '$': 's', '7': 't', '2': 'z'}
+)
+
+// Policy defines the requirements to passwords: the minimum number of characters
+// and the minimum estimated strength in bits.
+type Policy struct {
+	MinLength  int
+	MinEntropy float64
+}
+
+// Check returns the descriptions of the requirements the password does not meet, none if it is
+// acceptable. The user inputs, such as the username, are considered easy to guess.
+func (p Policy) Check(password string, userInputs ...string) []string {
+	var violations []string
+
+	if length := len([]rune(password)); length < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+
+	lower := strings.ToLower(password)
+	if _, common := commonRanks[lower]; common {
+		violations = append(violations, "must not be a commonly used password")
+	}
+	for _, input := range userInputs {
+		if len(input) >= minPatternLength && strings.Contains(lower, strings.ToLower(input)) {
+			violations = append(violations, "must not contain the username")
+			break
+		}
+	}
+
+	if entropy := Entropy(password, userInputs...); entropy < p.MinEntropy {
+		violations = append(violations, fmt.Sprintf("is too easy to guess: estimated strength is %.0f bits, at least %.0f required, "+
+			"use a longer password or more words", entropy, p.MinEntropy))
+	}
+
+	return violations
+}
+
+// Entropy estimates the strength of the password in bits, the base 2 logarithm of the number
+// of guesses an attacker trying the common patterns first needs to find it.
+func Entropy(password string, userInputs ...string) float64 {
+	runes := []rune(password)
+	if len(runes) > maxCheckedLength {
+		runes = runes[:maxCheckedLength]
+	}
+	if len(runes) == 0 {
+		return 0
+	}
+
+	ranks := commonRanks
+	if len(userInputs) > 0 {
+		ranks = make(map[string]int, len(commonRanks)+len(userInputs))
+		for word, rank := range commonRanks {
+			ranks[word] = rank
+		}
+		for _, input := range userInputs {
+			if input != "" {
+				ranks[strings.ToLower(input)] = 1
+			}
+		}
+	}
+
+	bruteForce := math.Log2(float64(cardinality(runes)))
+
+	// best[j] is the cheapest estimate of the first j characters
+	best := make([]float64, len(runes)+1)
+	for j := 1; j <= len(runes); j++ {
+		best[j] = best[j-1] + bruteForce
+		for i := 0; i <= j-minPatternLength; i++ {
+			if cost, ok := patternEntropy(runes[i:j], ranks); ok {
+				// every pattern adds a bit for the attacker not knowing where the patterns start
+				best[j] = math.Min(best[j], best[i]+cost+1)
+			}
+		}
+	}
+
+	return best[len(runes)]
+}
+
+// patternEntropy returns the bits of the guesses needed to find the characters as a single
+// guessable pattern, and whether they form one.
+func patternEntropy(runes []rune, ranks map[string]int) (float64, bool) {
+	costs := []float64{math.Inf(1)}
+
+	if cost, ok := dictionaryEntropy(runes, ranks); ok {
+		costs = append(costs, cost)
+	}
+	if cost, ok := repeatEntropy(runes); ok {
+		costs = append(costs, cost)
+	}
+	if cost, ok := sequenceEntropy(runes); ok {
+		costs = append(costs, cost)
+	}
+	if cost, ok := keyboardEntropy(runes); ok {
+		costs = append(costs, cost)
+	}
+	if cost, ok := yearEntropy(runes); ok {
+		costs = append(costs, cost)
+	}
+
+	minCost := costs[0]
+	for _, cost := range costs[1:] {
+		minCost = math.Min(minCost, cost)
+	}
+
+	return minCost, !math.IsInf(minCost, 1)
+}
+
+// dictionaryEntropy matches the characters against the common passwords and the user inputs,
+// also reversed and with the letters substituted by digits and symbols. Capital letters and
+// substitutions add the bits of guessing where they are.
+func dictionaryEntropy(runes []rune, ranks map[string]int) (float64, bool) {
+	var (
+		word          = make([]rune, len(runes))
+		upper, subs   int
+		letters       int
+		reversedFound bool
+	)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+		lower := unicode.ToLower(r)
+		if letter, ok := leetSubstitutions[lower]; ok {
+			lower = letter
+			subs++
+		}
+		word[i] = lower
+	}
+
+	rank, found := ranks[strings.ToLower(string(runes))]
+	if !found {
+		rank, found = ranks[string(word)]
+	}
+	if !found {
+		rank, found = ranks[reverse(string(word))]
+		reversedFound = found
+	}
+	if !found {
+		return 0, false
+	}
+
+	cost := math.Log2(float64(rank))
+	if upper > 0 && upper < letters {
+		cost += variations(letters, upper)
+	} else if upper > 0 {
+		cost++
+	}
+	if subs > 0 {
+		cost += variations(len(runes), subs)
+	}
+	if reversedFound {
+		cost++
+	}
+
+	return cost, true
+}
+
+// repeatEntropy matches a single character repeated, such as "aaaa".
+func repeatEntropy(runes []rune) (float64, bool) {
+	for _, r := range runes[1:] {
+		if r != runes[0] {
+			return 0, false
+		}
+	}
+
+	return math.Log2(float64(cardinality(runes[:1]))) + math.Log2(float64(len(runes))), true
+}
+
+// sequenceEntropy matches characters following each other in the alphabet or among the digits,
+// in either direction, such as "abcd" or "9876".
+func sequenceEntropy(runes []rune) (float64, bool) {
+	delta := runes[1] - runes[0]
+	if delta != 1 && delta != -1 {
+		return 0, false
+	}
+	for i := 2; i < len(runes); i++ {
+		if runes[i]-runes[i-1] != delta {
+			return 0, false
+		}
+	}
+
+	// the obvious starts are guessed first
+	cost := math.Log2(float64(cardinality(runes[:1])))
+	if strings.ContainsRune("aA1z9", runes[0]) {
+		cost = 1
+	}
+	if delta < 0 {
+		cost++
+	}
+
+	return cost + math.Log2(float64(len(runes))), true
+}
+
+// keyboardEntropy matches a walk along a row of the keyboard, in either direction, such as "qwerty".
+func keyboardEntropy(runes []rune) (float64, bool) {
+	if len(runes) < minPatternLength+1 {
+		return 0, false
+	}
+
+	s := strings.ToLower(string(runes))
+	for _, row := range keyboardRows {
+		if strings.Contains(row, s) || strings.Contains(row, reverse(s)) {
+			keys := 0
+			for _, r := range keyboardRows {
+				keys += len(r)
+			}
+			// a starting key, a direction and the length
+			return math.Log2(float64(keys)) + 1 + math.Log2(float64(len(runes))), true
+		}
+	}
+
+	return 0, false
+}
+
+// yearEntropy matches the recent years, which are often appended to passwords.
+func yearEntropy(runes []rune) (float64, bool) {
+	if len(runes) != 4 {
+		return 0, false
+	}
+
+	year := 0
+	for _, r := range runes {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		year = year*10 + int(r-'0')
+	}
+	if year < 1900 || year > 2099 {
+		return 0, false
+	}
+
+	return math.Log2(200), true
+}
+
+// cardinality returns the size of the smallest character set an attacker brute forcing
+// the characters has to try.
+func cardinality(runes []rune) int {
+	var lower, upper, digits, symbols, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digits = true
+		case r > ' ' && r < 0x7f:
+			symbols = true
+		default:
+			other = true
+		}
+	}
+
+	size := 0
+	for _, set := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digits, 10}, {symbols, 33}, {other, 100}} {
+		if set.used {
+			size += set.size
+		}
+	}
+
+	return max(size, 1)
+}
+
+// variations returns the bits of choosing which k of the n characters are changed.
+func variations(n, k int) float64 {
+	k = min(k, n-k)
+	sum := 0.0
+	for i := 1; i <= k; i++ {
+		sum += binomial(n, i)
+	}
+
+	return math.Log2(math.Max(sum, 2))
+}
+
+// binomial returns the number of ways to choose k of n.
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// reverse returns the string with the characters in the reverse order.
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// parseCommon parses the embedded list of common passwords, one per line, most common first.
+func parseCommon(list string) map[string]int {
+	ranks := map[string]int{}
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if _, found := ranks[line]; line != "" && !found {
+			ranks[line] = len(ranks) + 1
+		}
+	}
+	return ranks
+}
@@ -0,0 +1,66 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		username string
+		want     []string
+	}{
+		{name: "strong password", password: "f7Gq!2pLw9sR", username: "alice"},
+		{name: "passphrase", password: "correct horse battery staple", username: "alice"},
+		{name: "short password", password: "kX9#mq2L", username: "alice", want: []string{"at least 10 characters"}},
+		{name: "common password", password: "Qwertyuiop", username: "alice", want: []string{"commonly used", "too easy to guess"}},
+		{name: "username", password: "Alice-2024-keeper", username: "alice", want: []string{"username", "too easy to guess"}},
+		{name: "sequence", password: "abcdefghij", username: "alice", want: []string{"too easy to guess"}},
+		{name: "repeat", password: "aaaaaaaaaaaa", username: "alice", want: []string{"too easy to guess"}},
+		{name: "common password with a year", password: "iloveyou2023", username: "alice", want: []string{"too easy to guess"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DefaultPolicy.Check(tt.password, tt.username)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Check() = %q, want violations containing %q", got, tt.want)
+			}
+			for i, violation := range got {
+				if !strings.Contains(violation, tt.want[i]) {
+					t.Errorf("Check()[%d] = %q, want it to contain %q", i, violation, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		weaker   string
+	}{
+		{name: "random beats common", password: "kX9#mq2Lz!", weaker: "P@ssw0rd"},
+		{name: "longer beats shorter", password: "kX9#mq2Lz!a", weaker: "kX9#mq2Lz!"},
+		{name: "capitalized common beats common", password: "PaSsWoRd", weaker: "password"},
+		{name: "random beats keyboard row", password: "hq7wzr4kxm", weaker: "qwertyuiop"},
+		{name: "common word beats username", password: "dragon", weaker: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, weaker := Entropy(tt.password, "alice"), Entropy(tt.weaker, "alice")
+			if got <= weaker {
+				t.Errorf("Entropy(%q) = %v, want more than Entropy(%q) = %v", tt.password, got, tt.weaker, weaker)
+			}
+		})
+	}
+
+	if got := Entropy(""); got != 0 {
+		t.Errorf("Entropy(\"\") = %v, want 0", got)
+	}
+	if got := Entropy(strings.Repeat("kX9#mq2Lz!", 100)); got <= DefaultPolicy.MinEntropy {
+		t.Errorf("Entropy() of a long password = %v, want more than %v", got, DefaultPolicy.MinEntropy)
+	}
+}
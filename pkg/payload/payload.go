@@ -0,0 +1,236 @@
+// Package payload defines how the typed payloads of data items (credentials, bank cards,
+// text and binary data) are validated and serialized into the opaque data bytes.
+// The client validates payloads before encrypting them, the server only stores the
+// ciphertexts and reports the invalid fields of requests with its own validation error.
+package payload
+
+import (
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	CredentialsType = "login_password"
+	TextType        = "text"
+	BinaryType      = "binary"
+	BankCardType    = "bank_card"
+)
+
+var (
+	// ErrUnknownType is returned when a data item has a type without a payload schema.
+	ErrUnknownType = errors.New("unknown data item type")
+
+	expiryRegexp = regexp.MustCompile(`^(0[1-9]|1[0-2])/[0-9]{2}$`)
+	cvvRegexp    = regexp.MustCompile(`^[0-9]{3,4}$`)
+)
+
+// FieldViolation describes a single invalid field of a payload.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError is returned when a payload has invalid fields.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+// Error returns the description of all violations.
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	return "invalid payload: " + strings.Join(parts, "; ")
+}
+
+// TypeOf returns the data item type matching the item's payload,
+// or an empty string if the payload is not set.
+func TypeOf(item *pb.DataItem) string {
+	switch item.GetPayload().(type) {
+	case *pb.DataItem_Credentials:
+		return CredentialsType
+	case *pb.DataItem_BankCard:
+		return BankCardType
+	case *pb.DataItem_Text:
+		return TextType
+	case *pb.DataItem_Binary:
+		return BinaryType
+	default:
+		return ""
+	}
+}
+
+// Validate checks the payload of the data item, returning a ValidationError
+// describing every invalid field. Items without a payload are considered valid.
+func Validate(item *pb.DataItem) error {
+	var violations []FieldViolation
+
+	switch p := item.GetPayload().(type) {
+	case *pb.DataItem_Credentials:
+		violations = validateCredentials(p.Credentials)
+	case *pb.DataItem_BankCard:
+		violations = validateBankCard(p.BankCard)
+	case *pb.DataItem_Text:
+		if p.Text.GetContent() == "" {
+			violations = append(violations, FieldViolation{Field: "text.content", Description: "must not be empty"})
+		}
+	case *pb.DataItem_Binary:
+		if len(p.Binary.GetContent()) == 0 {
+			violations = append(violations, FieldViolation{Field: "binary.content", Description: "must not be empty"})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// Marshal serializes the payload of the data item into its data bytes, sets the matching
+// type and clears the payload. Binary content is stored as is, other payloads are
+// serialized as protobuf messages. Items without a payload are left untouched.
+func Marshal(item *pb.DataItem) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch p := item.GetPayload().(type) {
+	case nil:
+		return nil
+	case *pb.DataItem_Credentials:
+		data, err = proto.Marshal(p.Credentials)
+	case *pb.DataItem_BankCard:
+		data, err = proto.Marshal(p.BankCard)
+	case *pb.DataItem_Text:
+		data, err = proto.Marshal(p.Text)
+	case *pb.DataItem_Binary:
+		data = p.Binary.GetContent()
+	}
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	item.Type = TypeOf(item)
+	item.Data = data
+	item.Payload = nil
+
+	return nil
+}
+
+// Unmarshal parses the data bytes of the item into the payload matching its type.
+func Unmarshal(item *pb.DataItem) error {
+	switch item.GetType() {
+	case CredentialsType:
+		credentials := &pb.Credentials{}
+		if err := proto.Unmarshal(item.GetData(), credentials); err != nil {
+			return fmt.Errorf("unmarshal credentials: %w", err)
+		}
+		item.Payload = &pb.DataItem_Credentials{Credentials: credentials}
+	case BankCardType:
+		bankCard := &pb.BankCard{}
+		if err := proto.Unmarshal(item.GetData(), bankCard); err != nil {
+			return fmt.Errorf("unmarshal bank card: %w", err)
+		}
+		item.Payload = &pb.DataItem_BankCard{BankCard: bankCard}
+	case TextType:
+		text := &pb.Text{}
+		if err := proto.Unmarshal(item.GetData(), text); err != nil {
+			return fmt.Errorf("unmarshal text: %w", err)
+		}
+		item.Payload = &pb.DataItem_Text{Text: text}
+	case BinaryType:
+		item.Payload = &pb.DataItem_Binary{Binary: &pb.Binary{Content: item.GetData()}}
+	default:
+		return ErrUnknownType
+	}
+
+	return nil
+}
+
+// IsValidCardNumber checks that the card number consists of 12 to 19 digits,
+// optionally separated by spaces or dashes, and passes the Luhn check.
+func IsValidCardNumber(number string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(number)
+	if len(digits) < 12 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if d < '0' || d > '9' {
+			return false
+		}
+
+		n := int(d - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// IsValidExpiry checks that the card expiry is formatted as MM/YY.
+func IsValidExpiry(expiry string) bool {
+	return expiryRegexp.MatchString(expiry)
+}
+
+// validateCredentials returns the violations of the credentials payload.
+func validateCredentials(c *pb.Credentials) []FieldViolation {
+	var violations []FieldViolation
+
+	if c.GetLogin() == "" {
+		violations = append(violations, FieldViolation{Field: "credentials.login", Description: "must not be empty"})
+	}
+	if c.GetPassword() == "" {
+		violations = append(violations, FieldViolation{Field: "credentials.password", Description: "must not be empty"})
+	}
+	if c.GetUrl() != "" {
+		if u, err := url.ParseRequestURI(c.GetUrl()); err != nil || u.Host == "" {
+			violations = append(violations, FieldViolation{Field: "credentials.url", Description: "must be an absolute URL"})
+		}
+	}
+	if c.GetTotpSecret() != "" {
+		secret := strings.ToUpper(strings.ReplaceAll(c.GetTotpSecret(), " ", ""))
+		if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "=")); err != nil {
+			violations = append(violations, FieldViolation{Field: "credentials.totp_secret", Description: "must be base32 encoded"})
+		}
+	}
+
+	return violations
+}
+
+// validateBankCard returns the violations of the bank card payload.
+func validateBankCard(c *pb.BankCard) []FieldViolation {
+	var violations []FieldViolation
+
+	if !IsValidCardNumber(c.GetNumber()) {
+		violations = append(violations, FieldViolation{Field: "bank_card.number", Description: "must be a valid card number"})
+	}
+	if c.GetHolder() == "" {
+		violations = append(violations, FieldViolation{Field: "bank_card.holder", Description: "must not be empty"})
+	}
+	if !IsValidExpiry(c.GetExpiry()) {
+		violations = append(violations, FieldViolation{Field: "bank_card.expiry", Description: "must be formatted as MM/YY"})
+	}
+	if !cvvRegexp.MatchString(c.GetCvv()) {
+		violations = append(violations, FieldViolation{Field: "bank_card.cvv", Description: "must be 3 or 4 digits"})
+	}
+
+	return violations
+}
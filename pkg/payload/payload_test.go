@@ -0,0 +1,133 @@
+package payload
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"testing"
+)
+
+func TestIsValidCardNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		want   bool
+	}{
+		{name: "valid visa", number: "4111111111111111", want: true},
+		{name: "valid with spaces", number: "4111 1111 1111 1111", want: true},
+		{name: "invalid checksum", number: "4111111111111112", want: false},
+		{name: "too short", number: "411111", want: false},
+		{name: "not digits", number: "4111-1111-1111-111a", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidCardNumber(tt.number); got != tt.want {
+				t.Errorf("IsValidCardNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		expiry string
+		want   bool
+	}{
+		{name: "valid", expiry: "09/27", want: true},
+		{name: "invalid month", expiry: "13/27", want: false},
+		{name: "four digit year", expiry: "09/2027", want: false},
+		{name: "empty", expiry: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidExpiry(tt.expiry); got != tt.want {
+				t.Errorf("IsValidExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		item           *pb.DataItem
+		wantViolations int
+	}{
+		{
+			name: "valid bank card",
+			item: &pb.DataItem{Payload: &pb.DataItem_BankCard{BankCard: &pb.BankCard{
+				Number: "4111111111111111",
+				Holder: "IVAN IVANOV",
+				Expiry: "09/27",
+				Cvv:    "123",
+			}}},
+		},
+		{
+			name: "invalid bank card",
+			item: &pb.DataItem{Payload: &pb.DataItem_BankCard{BankCard: &pb.BankCard{
+				Number: "4111111111111112",
+				Expiry: "9/27",
+				Cvv:    "12",
+			}}},
+			wantViolations: 4,
+		},
+		{
+			name: "credentials without password and with bad url",
+			item: &pb.DataItem{Payload: &pb.DataItem_Credentials{Credentials: &pb.Credentials{
+				Login: "user",
+				Url:   "not a url",
+			}}},
+			wantViolations: 2,
+		},
+		{
+			name: "item without payload",
+			item: &pb.DataItem{Data: []byte("ciphertext")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.item)
+
+			var validationErr *ValidationError
+			if tt.wantViolations == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() error = %v, want ValidationError", err)
+			}
+			if len(validationErr.Violations) != tt.wantViolations {
+				t.Errorf("Validate() violations = %v, want %v", validationErr.Violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	credentials := &pb.Credentials{Login: "user", Password: "secret", Url: "https://example.com"}
+	item := &pb.DataItem{
+		Id:      "1",
+		Payload: &pb.DataItem_Credentials{Credentials: credentials},
+	}
+
+	if err := Marshal(item); err != nil {
+		t.Fatal(err)
+	}
+	if item.Type != CredentialsType || item.Payload != nil || len(item.Data) == 0 {
+		t.Fatalf("Marshal() got = %v", item)
+	}
+
+	if err := Unmarshal(item); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(item.GetCredentials(), credentials) {
+		t.Errorf("Unmarshal() got = %v, want %v", item.GetCredentials(), credentials)
+	}
+
+	if err := Unmarshal(&pb.DataItem{Type: "unknown"}); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnknownType)
+	}
+}
@@ -20,15 +20,43 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	GophKeeperService_Register_FullMethodName   = "/gophkeeper.GophKeeperService/Register"
-	GophKeeperService_Login_FullMethodName      = "/gophkeeper.GophKeeperService/Login"
-	GophKeeperService_GetData_FullMethodName    = "/gophkeeper.GophKeeperService/GetData"
-	GophKeeperService_ListData_FullMethodName   = "/gophkeeper.GophKeeperService/ListData"
-	GophKeeperService_CreateData_FullMethodName = "/gophkeeper.GophKeeperService/CreateData"
-	GophKeeperService_UpdateData_FullMethodName = "/gophkeeper.GophKeeperService/UpdateData"
-	GophKeeperService_DeleteData_FullMethodName = "/gophkeeper.GophKeeperService/DeleteData"
-	GophKeeperService_SyncData_FullMethodName   = "/gophkeeper.GophKeeperService/SyncData"
-	GophKeeperService_Ping_FullMethodName       = "/gophkeeper.GophKeeperService/Ping"
+	GophKeeperService_Register_FullMethodName             = "/gophkeeper.GophKeeperService/Register"
+	GophKeeperService_Login_FullMethodName                = "/gophkeeper.GophKeeperService/Login"
+	GophKeeperService_RegisterSrp_FullMethodName          = "/gophkeeper.GophKeeperService/RegisterSrp"
+	GophKeeperService_LoginSrpStart_FullMethodName        = "/gophkeeper.GophKeeperService/LoginSrpStart"
+	GophKeeperService_LoginSrpFinish_FullMethodName       = "/gophkeeper.GophKeeperService/LoginSrpFinish"
+	GophKeeperService_SetSrpVerifier_FullMethodName       = "/gophkeeper.GophKeeperService/SetSrpVerifier"
+	GophKeeperService_SetKeyCheck_FullMethodName          = "/gophkeeper.GophKeeperService/SetKeyCheck"
+	GophKeeperService_GetPasswordPolicy_FullMethodName    = "/gophkeeper.GophKeeperService/GetPasswordPolicy"
+	GophKeeperService_VerifyLoginTwoFactor_FullMethodName = "/gophkeeper.GophKeeperService/VerifyLoginTwoFactor"
+	GophKeeperService_EnrollTwoFactor_FullMethodName      = "/gophkeeper.GophKeeperService/EnrollTwoFactor"
+	GophKeeperService_ConfirmTwoFactor_FullMethodName     = "/gophkeeper.GophKeeperService/ConfirmTwoFactor"
+	GophKeeperService_DisableTwoFactor_FullMethodName     = "/gophkeeper.GophKeeperService/DisableTwoFactor"
+	GophKeeperService_ChangePassword_FullMethodName       = "/gophkeeper.GophKeeperService/ChangePassword"
+	GophKeeperService_ChangeUsername_FullMethodName       = "/gophkeeper.GophKeeperService/ChangeUsername"
+	GophKeeperService_DeleteAccount_FullMethodName        = "/gophkeeper.GophKeeperService/DeleteAccount"
+	GophKeeperService_GetData_FullMethodName              = "/gophkeeper.GophKeeperService/GetData"
+	GophKeeperService_ListData_FullMethodName             = "/gophkeeper.GophKeeperService/ListData"
+	GophKeeperService_CreateData_FullMethodName           = "/gophkeeper.GophKeeperService/CreateData"
+	GophKeeperService_UpdateData_FullMethodName           = "/gophkeeper.GophKeeperService/UpdateData"
+	GophKeeperService_DeleteData_FullMethodName           = "/gophkeeper.GophKeeperService/DeleteData"
+	GophKeeperService_ListTrash_FullMethodName            = "/gophkeeper.GophKeeperService/ListTrash"
+	GophKeeperService_RestoreData_FullMethodName          = "/gophkeeper.GophKeeperService/RestoreData"
+	GophKeeperService_SyncData_FullMethodName             = "/gophkeeper.GophKeeperService/SyncData"
+	GophKeeperService_UploadBinary_FullMethodName         = "/gophkeeper.GophKeeperService/UploadBinary"
+	GophKeeperService_DownloadBinary_FullMethodName       = "/gophkeeper.GophKeeperService/DownloadBinary"
+	GophKeeperService_RefreshToken_FullMethodName         = "/gophkeeper.GophKeeperService/RefreshToken"
+	GophKeeperService_Logout_FullMethodName               = "/gophkeeper.GophKeeperService/Logout"
+	GophKeeperService_ListSessions_FullMethodName         = "/gophkeeper.GophKeeperService/ListSessions"
+	GophKeeperService_RevokeSession_FullMethodName        = "/gophkeeper.GophKeeperService/RevokeSession"
+	GophKeeperService_ListVersions_FullMethodName         = "/gophkeeper.GophKeeperService/ListVersions"
+	GophKeeperService_GetVersion_FullMethodName           = "/gophkeeper.GophKeeperService/GetVersion"
+	GophKeeperService_RestoreVersion_FullMethodName       = "/gophkeeper.GophKeeperService/RestoreVersion"
+	GophKeeperService_SearchData_FullMethodName           = "/gophkeeper.GophKeeperService/SearchData"
+	GophKeeperService_CreateFolder_FullMethodName         = "/gophkeeper.GophKeeperService/CreateFolder"
+	GophKeeperService_ListFolders_FullMethodName          = "/gophkeeper.GophKeeperService/ListFolders"
+	GophKeeperService_DeleteFolder_FullMethodName         = "/gophkeeper.GophKeeperService/DeleteFolder"
+	GophKeeperService_Ping_FullMethodName                 = "/gophkeeper.GophKeeperService/Ping"
 )
 
 // GophKeeperServiceClient is the client API for GophKeeperService service.
@@ -37,12 +65,40 @@ const (
 type GophKeeperServiceClient interface {
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	RegisterSrp(ctx context.Context, in *RegisterSrpRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	LoginSrpStart(ctx context.Context, in *LoginSrpStartRequest, opts ...grpc.CallOption) (*LoginSrpStartResponse, error)
+	LoginSrpFinish(ctx context.Context, in *LoginSrpFinishRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	SetSrpVerifier(ctx context.Context, in *SetSrpVerifierRequest, opts ...grpc.CallOption) (*SetSrpVerifierResponse, error)
+	SetKeyCheck(ctx context.Context, in *SetKeyCheckRequest, opts ...grpc.CallOption) (*SetKeyCheckResponse, error)
+	GetPasswordPolicy(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetPasswordPolicyResponse, error)
+	VerifyLoginTwoFactor(ctx context.Context, in *VerifyLoginTwoFactorRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	EnrollTwoFactor(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollTwoFactorResponse, error)
+	ConfirmTwoFactor(ctx context.Context, in *ConfirmTwoFactorRequest, opts ...grpc.CallOption) (*ConfirmTwoFactorResponse, error)
+	DisableTwoFactor(ctx context.Context, in *DisableTwoFactorRequest, opts ...grpc.CallOption) (*DisableTwoFactorResponse, error)
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	ChangeUsername(ctx context.Context, in *ChangeUsernameRequest, opts ...grpc.CallOption) (*ChangeUsernameResponse, error)
+	DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*DeleteAccountResponse, error)
 	GetData(ctx context.Context, in *GetDataRequest, opts ...grpc.CallOption) (*GetDataResponse, error)
-	ListData(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListDataResponse, error)
+	ListData(ctx context.Context, in *ListDataRequest, opts ...grpc.CallOption) (*ListDataResponse, error)
 	CreateData(ctx context.Context, in *CreateDataRequest, opts ...grpc.CallOption) (*CreateDataResponse, error)
 	UpdateData(ctx context.Context, in *UpdateDataRequest, opts ...grpc.CallOption) (*UpdateDataResponse, error)
 	DeleteData(ctx context.Context, in *DeleteDataRequest, opts ...grpc.CallOption) (*DeleteDataResponse, error)
+	ListTrash(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListTrashResponse, error)
+	RestoreData(ctx context.Context, in *RestoreDataRequest, opts ...grpc.CallOption) (*RestoreDataResponse, error)
 	SyncData(ctx context.Context, in *SyncDataRequest, opts ...grpc.CallOption) (*SyncDataResponse, error)
+	UploadBinary(ctx context.Context, opts ...grpc.CallOption) (GophKeeperService_UploadBinaryClient, error)
+	DownloadBinary(ctx context.Context, in *DownloadBinaryRequest, opts ...grpc.CallOption) (GophKeeperService_DownloadBinaryClient, error)
+	RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*RefreshTokenResponse, error)
+	Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LogoutResponse, error)
+	ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error)
+	ListVersions(ctx context.Context, in *ListVersionsRequest, opts ...grpc.CallOption) (*ListVersionsResponse, error)
+	GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error)
+	RestoreVersion(ctx context.Context, in *RestoreVersionRequest, opts ...grpc.CallOption) (*RestoreVersionResponse, error)
+	SearchData(ctx context.Context, in *SearchDataRequest, opts ...grpc.CallOption) (*SearchDataResponse, error)
+	CreateFolder(ctx context.Context, in *CreateFolderRequest, opts ...grpc.CallOption) (*CreateFolderResponse, error)
+	ListFolders(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListFoldersResponse, error)
+	DeleteFolder(ctx context.Context, in *DeleteFolderRequest, opts ...grpc.CallOption) (*DeleteFolderResponse, error)
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
@@ -72,6 +128,123 @@ func (c *gophKeeperServiceClient) Login(ctx context.Context, in *LoginRequest, o
 	return out, nil
 }
 
+func (c *gophKeeperServiceClient) RegisterSrp(ctx context.Context, in *RegisterSrpRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_RegisterSrp_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) LoginSrpStart(ctx context.Context, in *LoginSrpStartRequest, opts ...grpc.CallOption) (*LoginSrpStartResponse, error) {
+	out := new(LoginSrpStartResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_LoginSrpStart_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) LoginSrpFinish(ctx context.Context, in *LoginSrpFinishRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_LoginSrpFinish_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) SetSrpVerifier(ctx context.Context, in *SetSrpVerifierRequest, opts ...grpc.CallOption) (*SetSrpVerifierResponse, error) {
+	out := new(SetSrpVerifierResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_SetSrpVerifier_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) SetKeyCheck(ctx context.Context, in *SetKeyCheckRequest, opts ...grpc.CallOption) (*SetKeyCheckResponse, error) {
+	out := new(SetKeyCheckResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_SetKeyCheck_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) GetPasswordPolicy(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetPasswordPolicyResponse, error) {
+	out := new(GetPasswordPolicyResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_GetPasswordPolicy_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) VerifyLoginTwoFactor(ctx context.Context, in *VerifyLoginTwoFactorRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_VerifyLoginTwoFactor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) EnrollTwoFactor(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollTwoFactorResponse, error) {
+	out := new(EnrollTwoFactorResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_EnrollTwoFactor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) ConfirmTwoFactor(ctx context.Context, in *ConfirmTwoFactorRequest, opts ...grpc.CallOption) (*ConfirmTwoFactorResponse, error) {
+	out := new(ConfirmTwoFactorResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_ConfirmTwoFactor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) DisableTwoFactor(ctx context.Context, in *DisableTwoFactorRequest, opts ...grpc.CallOption) (*DisableTwoFactorResponse, error) {
+	out := new(DisableTwoFactorResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_DisableTwoFactor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_ChangePassword_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) ChangeUsername(ctx context.Context, in *ChangeUsernameRequest, opts ...grpc.CallOption) (*ChangeUsernameResponse, error) {
+	out := new(ChangeUsernameResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_ChangeUsername_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*DeleteAccountResponse, error) {
+	out := new(DeleteAccountResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_DeleteAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gophKeeperServiceClient) GetData(ctx context.Context, in *GetDataRequest, opts ...grpc.CallOption) (*GetDataResponse, error) {
 	out := new(GetDataResponse)
 	err := c.cc.Invoke(ctx, GophKeeperService_GetData_FullMethodName, in, out, opts...)
@@ -81,7 +254,7 @@ func (c *gophKeeperServiceClient) GetData(ctx context.Context, in *GetDataReques
 	return out, nil
 }
 
-func (c *gophKeeperServiceClient) ListData(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListDataResponse, error) {
+func (c *gophKeeperServiceClient) ListData(ctx context.Context, in *ListDataRequest, opts ...grpc.CallOption) (*ListDataResponse, error) {
 	out := new(ListDataResponse)
 	err := c.cc.Invoke(ctx, GophKeeperService_ListData_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -117,6 +290,24 @@ func (c *gophKeeperServiceClient) DeleteData(ctx context.Context, in *DeleteData
 	return out, nil
 }
 
+func (c *gophKeeperServiceClient) ListTrash(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListTrashResponse, error) {
+	out := new(ListTrashResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_ListTrash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) RestoreData(ctx context.Context, in *RestoreDataRequest, opts ...grpc.CallOption) (*RestoreDataResponse, error) {
+	out := new(RestoreDataResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_RestoreData_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gophKeeperServiceClient) SyncData(ctx context.Context, in *SyncDataRequest, opts ...grpc.CallOption) (*SyncDataResponse, error) {
 	out := new(SyncDataResponse)
 	err := c.cc.Invoke(ctx, GophKeeperService_SyncData_FullMethodName, in, out, opts...)
@@ -126,6 +317,171 @@ func (c *gophKeeperServiceClient) SyncData(ctx context.Context, in *SyncDataRequ
 	return out, nil
 }
 
+func (c *gophKeeperServiceClient) UploadBinary(ctx context.Context, opts ...grpc.CallOption) (GophKeeperService_UploadBinaryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GophKeeperService_ServiceDesc.Streams[0], GophKeeperService_UploadBinary_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gophKeeperServiceUploadBinaryClient{stream}
+	return x, nil
+}
+
+type GophKeeperService_UploadBinaryClient interface {
+	Send(*UploadBinaryRequest) error
+	CloseAndRecv() (*UploadBinaryResponse, error)
+	grpc.ClientStream
+}
+
+type gophKeeperServiceUploadBinaryClient struct {
+	grpc.ClientStream
+}
+
+func (x *gophKeeperServiceUploadBinaryClient) Send(m *UploadBinaryRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *gophKeeperServiceUploadBinaryClient) CloseAndRecv() (*UploadBinaryResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadBinaryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gophKeeperServiceClient) DownloadBinary(ctx context.Context, in *DownloadBinaryRequest, opts ...grpc.CallOption) (GophKeeperService_DownloadBinaryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GophKeeperService_ServiceDesc.Streams[1], GophKeeperService_DownloadBinary_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gophKeeperServiceDownloadBinaryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GophKeeperService_DownloadBinaryClient interface {
+	Recv() (*DownloadBinaryResponse, error)
+	grpc.ClientStream
+}
+
+type gophKeeperServiceDownloadBinaryClient struct {
+	grpc.ClientStream
+}
+
+func (x *gophKeeperServiceDownloadBinaryClient) Recv() (*DownloadBinaryResponse, error) {
+	m := new(DownloadBinaryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gophKeeperServiceClient) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*RefreshTokenResponse, error) {
+	out := new(RefreshTokenResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_RefreshToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LogoutResponse, error) {
+	out := new(LogoutResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_Logout_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_ListSessions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error) {
+	out := new(RevokeSessionResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_RevokeSession_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) ListVersions(ctx context.Context, in *ListVersionsRequest, opts ...grpc.CallOption) (*ListVersionsResponse, error) {
+	out := new(ListVersionsResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_ListVersions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error) {
+	out := new(GetVersionResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_GetVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) RestoreVersion(ctx context.Context, in *RestoreVersionRequest, opts ...grpc.CallOption) (*RestoreVersionResponse, error) {
+	out := new(RestoreVersionResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_RestoreVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) SearchData(ctx context.Context, in *SearchDataRequest, opts ...grpc.CallOption) (*SearchDataResponse, error) {
+	out := new(SearchDataResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_SearchData_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) CreateFolder(ctx context.Context, in *CreateFolderRequest, opts ...grpc.CallOption) (*CreateFolderResponse, error) {
+	out := new(CreateFolderResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_CreateFolder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) ListFolders(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListFoldersResponse, error) {
+	out := new(ListFoldersResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_ListFolders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperServiceClient) DeleteFolder(ctx context.Context, in *DeleteFolderRequest, opts ...grpc.CallOption) (*DeleteFolderResponse, error) {
+	out := new(DeleteFolderResponse)
+	err := c.cc.Invoke(ctx, GophKeeperService_DeleteFolder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gophKeeperServiceClient) Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, GophKeeperService_Ping_FullMethodName, in, out, opts...)
@@ -141,12 +497,40 @@ func (c *gophKeeperServiceClient) Ping(ctx context.Context, in *emptypb.Empty, o
 type GophKeeperServiceServer interface {
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	RegisterSrp(context.Context, *RegisterSrpRequest) (*RegisterResponse, error)
+	LoginSrpStart(context.Context, *LoginSrpStartRequest) (*LoginSrpStartResponse, error)
+	LoginSrpFinish(context.Context, *LoginSrpFinishRequest) (*LoginResponse, error)
+	SetSrpVerifier(context.Context, *SetSrpVerifierRequest) (*SetSrpVerifierResponse, error)
+	SetKeyCheck(context.Context, *SetKeyCheckRequest) (*SetKeyCheckResponse, error)
+	GetPasswordPolicy(context.Context, *emptypb.Empty) (*GetPasswordPolicyResponse, error)
+	VerifyLoginTwoFactor(context.Context, *VerifyLoginTwoFactorRequest) (*LoginResponse, error)
+	EnrollTwoFactor(context.Context, *emptypb.Empty) (*EnrollTwoFactorResponse, error)
+	ConfirmTwoFactor(context.Context, *ConfirmTwoFactorRequest) (*ConfirmTwoFactorResponse, error)
+	DisableTwoFactor(context.Context, *DisableTwoFactorRequest) (*DisableTwoFactorResponse, error)
+	ChangePassword(context.Context, *ChangePasswordRequest) (*LoginResponse, error)
+	ChangeUsername(context.Context, *ChangeUsernameRequest) (*ChangeUsernameResponse, error)
+	DeleteAccount(context.Context, *DeleteAccountRequest) (*DeleteAccountResponse, error)
 	GetData(context.Context, *GetDataRequest) (*GetDataResponse, error)
-	ListData(context.Context, *emptypb.Empty) (*ListDataResponse, error)
+	ListData(context.Context, *ListDataRequest) (*ListDataResponse, error)
 	CreateData(context.Context, *CreateDataRequest) (*CreateDataResponse, error)
 	UpdateData(context.Context, *UpdateDataRequest) (*UpdateDataResponse, error)
 	DeleteData(context.Context, *DeleteDataRequest) (*DeleteDataResponse, error)
+	ListTrash(context.Context, *emptypb.Empty) (*ListTrashResponse, error)
+	RestoreData(context.Context, *RestoreDataRequest) (*RestoreDataResponse, error)
 	SyncData(context.Context, *SyncDataRequest) (*SyncDataResponse, error)
+	UploadBinary(GophKeeperService_UploadBinaryServer) error
+	DownloadBinary(*DownloadBinaryRequest, GophKeeperService_DownloadBinaryServer) error
+	RefreshToken(context.Context, *RefreshTokenRequest) (*RefreshTokenResponse, error)
+	Logout(context.Context, *emptypb.Empty) (*LogoutResponse, error)
+	ListSessions(context.Context, *emptypb.Empty) (*ListSessionsResponse, error)
+	RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error)
+	ListVersions(context.Context, *ListVersionsRequest) (*ListVersionsResponse, error)
+	GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error)
+	RestoreVersion(context.Context, *RestoreVersionRequest) (*RestoreVersionResponse, error)
+	SearchData(context.Context, *SearchDataRequest) (*SearchDataResponse, error)
+	CreateFolder(context.Context, *CreateFolderRequest) (*CreateFolderResponse, error)
+	ListFolders(context.Context, *emptypb.Empty) (*ListFoldersResponse, error)
+	DeleteFolder(context.Context, *DeleteFolderRequest) (*DeleteFolderResponse, error)
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	mustEmbedUnimplementedGophKeeperServiceServer()
 }
@@ -161,10 +545,49 @@ func (UnimplementedGophKeeperServiceServer) Register(context.Context, *RegisterR
 func (UnimplementedGophKeeperServiceServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
 }
+func (UnimplementedGophKeeperServiceServer) RegisterSrp(context.Context, *RegisterSrpRequest) (*RegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterSrp not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) LoginSrpStart(context.Context, *LoginSrpStartRequest) (*LoginSrpStartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginSrpStart not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) LoginSrpFinish(context.Context, *LoginSrpFinishRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginSrpFinish not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) SetSrpVerifier(context.Context, *SetSrpVerifierRequest) (*SetSrpVerifierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSrpVerifier not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) SetKeyCheck(context.Context, *SetKeyCheckRequest) (*SetKeyCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetKeyCheck not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) GetPasswordPolicy(context.Context, *emptypb.Empty) (*GetPasswordPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPasswordPolicy not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) VerifyLoginTwoFactor(context.Context, *VerifyLoginTwoFactorRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyLoginTwoFactor not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) EnrollTwoFactor(context.Context, *emptypb.Empty) (*EnrollTwoFactorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTwoFactor not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) ConfirmTwoFactor(context.Context, *ConfirmTwoFactorRequest) (*ConfirmTwoFactorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTwoFactor not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) DisableTwoFactor(context.Context, *DisableTwoFactorRequest) (*DisableTwoFactorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTwoFactor not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) ChangePassword(context.Context, *ChangePasswordRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) ChangeUsername(context.Context, *ChangeUsernameRequest) (*ChangeUsernameResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeUsername not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) DeleteAccount(context.Context, *DeleteAccountRequest) (*DeleteAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAccount not implemented")
+}
 func (UnimplementedGophKeeperServiceServer) GetData(context.Context, *GetDataRequest) (*GetDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetData not implemented")
 }
-func (UnimplementedGophKeeperServiceServer) ListData(context.Context, *emptypb.Empty) (*ListDataResponse, error) {
+func (UnimplementedGophKeeperServiceServer) ListData(context.Context, *ListDataRequest) (*ListDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListData not implemented")
 }
 func (UnimplementedGophKeeperServiceServer) CreateData(context.Context, *CreateDataRequest) (*CreateDataResponse, error) {
@@ -176,9 +599,54 @@ func (UnimplementedGophKeeperServiceServer) UpdateData(context.Context, *UpdateD
 func (UnimplementedGophKeeperServiceServer) DeleteData(context.Context, *DeleteDataRequest) (*DeleteDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteData not implemented")
 }
+func (UnimplementedGophKeeperServiceServer) ListTrash(context.Context, *emptypb.Empty) (*ListTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) RestoreData(context.Context, *RestoreDataRequest) (*RestoreDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreData not implemented")
+}
 func (UnimplementedGophKeeperServiceServer) SyncData(context.Context, *SyncDataRequest) (*SyncDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SyncData not implemented")
 }
+func (UnimplementedGophKeeperServiceServer) UploadBinary(GophKeeperService_UploadBinaryServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadBinary not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) DownloadBinary(*DownloadBinaryRequest, GophKeeperService_DownloadBinaryServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadBinary not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) RefreshToken(context.Context, *RefreshTokenRequest) (*RefreshTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) Logout(context.Context, *emptypb.Empty) (*LogoutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) ListSessions(context.Context, *emptypb.Empty) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) ListVersions(context.Context, *ListVersionsRequest) (*ListVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVersions not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) RestoreVersion(context.Context, *RestoreVersionRequest) (*RestoreVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreVersion not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) SearchData(context.Context, *SearchDataRequest) (*SearchDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchData not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) CreateFolder(context.Context, *CreateFolderRequest) (*CreateFolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFolder not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) ListFolders(context.Context, *emptypb.Empty) (*ListFoldersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFolders not implemented")
+}
+func (UnimplementedGophKeeperServiceServer) DeleteFolder(context.Context, *DeleteFolderRequest) (*DeleteFolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFolder not implemented")
+}
 func (UnimplementedGophKeeperServiceServer) Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
 }
@@ -231,125 +699,640 @@ func _GophKeeperService_Login_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
-func _GophKeeperService_GetData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetDataRequest)
+func _GophKeeperService_RegisterSrp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterSrpRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophKeeperServiceServer).GetData(ctx, in)
+		return srv.(GophKeeperServiceServer).RegisterSrp(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: GophKeeperService_GetData_FullMethodName,
+		FullMethod: GophKeeperService_RegisterSrp_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophKeeperServiceServer).GetData(ctx, req.(*GetDataRequest))
+		return srv.(GophKeeperServiceServer).RegisterSrp(ctx, req.(*RegisterSrpRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _GophKeeperService_ListData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+func _GophKeeperService_LoginSrpStart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginSrpStartRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophKeeperServiceServer).ListData(ctx, in)
+		return srv.(GophKeeperServiceServer).LoginSrpStart(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: GophKeeperService_ListData_FullMethodName,
+		FullMethod: GophKeeperService_LoginSrpStart_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophKeeperServiceServer).ListData(ctx, req.(*emptypb.Empty))
+		return srv.(GophKeeperServiceServer).LoginSrpStart(ctx, req.(*LoginSrpStartRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _GophKeeperService_CreateData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateDataRequest)
+func _GophKeeperService_LoginSrpFinish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginSrpFinishRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophKeeperServiceServer).CreateData(ctx, in)
+		return srv.(GophKeeperServiceServer).LoginSrpFinish(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: GophKeeperService_CreateData_FullMethodName,
+		FullMethod: GophKeeperService_LoginSrpFinish_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophKeeperServiceServer).CreateData(ctx, req.(*CreateDataRequest))
+		return srv.(GophKeeperServiceServer).LoginSrpFinish(ctx, req.(*LoginSrpFinishRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _GophKeeperService_UpdateData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateDataRequest)
+func _GophKeeperService_SetSrpVerifier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSrpVerifierRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophKeeperServiceServer).UpdateData(ctx, in)
+		return srv.(GophKeeperServiceServer).SetSrpVerifier(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: GophKeeperService_UpdateData_FullMethodName,
+		FullMethod: GophKeeperService_SetSrpVerifier_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophKeeperServiceServer).UpdateData(ctx, req.(*UpdateDataRequest))
+		return srv.(GophKeeperServiceServer).SetSrpVerifier(ctx, req.(*SetSrpVerifierRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _GophKeeperService_DeleteData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteDataRequest)
+func _GophKeeperService_SetKeyCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetKeyCheckRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophKeeperServiceServer).DeleteData(ctx, in)
+		return srv.(GophKeeperServiceServer).SetKeyCheck(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: GophKeeperService_DeleteData_FullMethodName,
+		FullMethod: GophKeeperService_SetKeyCheck_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophKeeperServiceServer).DeleteData(ctx, req.(*DeleteDataRequest))
+		return srv.(GophKeeperServiceServer).SetKeyCheck(ctx, req.(*SetKeyCheckRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _GophKeeperService_SyncData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SyncDataRequest)
+func _GophKeeperService_GetPasswordPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophKeeperServiceServer).SyncData(ctx, in)
+		return srv.(GophKeeperServiceServer).GetPasswordPolicy(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: GophKeeperService_SyncData_FullMethodName,
+		FullMethod: GophKeeperService_GetPasswordPolicy_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophKeeperServiceServer).SyncData(ctx, req.(*SyncDataRequest))
+		return srv.(GophKeeperServiceServer).GetPasswordPolicy(ctx, req.(*emptypb.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _GophKeeperService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+func _GophKeeperService_VerifyLoginTwoFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyLoginTwoFactorRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophKeeperServiceServer).Ping(ctx, in)
+		return srv.(GophKeeperServiceServer).VerifyLoginTwoFactor(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: GophKeeperService_Ping_FullMethodName,
+		FullMethod: GophKeeperService_VerifyLoginTwoFactor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).VerifyLoginTwoFactor(ctx, req.(*VerifyLoginTwoFactorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_EnrollTwoFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).EnrollTwoFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_EnrollTwoFactor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).EnrollTwoFactor(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_ConfirmTwoFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmTwoFactorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).ConfirmTwoFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_ConfirmTwoFactor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).ConfirmTwoFactor(ctx, req.(*ConfirmTwoFactorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_DisableTwoFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableTwoFactorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).DisableTwoFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_DisableTwoFactor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).DisableTwoFactor(ctx, req.(*DisableTwoFactorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_ChangePassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_ChangeUsername_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeUsernameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).ChangeUsername(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_ChangeUsername_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).ChangeUsername(ctx, req.(*ChangeUsernameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_DeleteAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).DeleteAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_DeleteAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).DeleteAccount(ctx, req.(*DeleteAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_GetData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).GetData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_GetData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).GetData(ctx, req.(*GetDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_ListData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).ListData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_ListData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).ListData(ctx, req.(*ListDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_CreateData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).CreateData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_CreateData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).CreateData(ctx, req.(*CreateDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_UpdateData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).UpdateData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_UpdateData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).UpdateData(ctx, req.(*UpdateDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_DeleteData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).DeleteData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_DeleteData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).DeleteData(ctx, req.(*DeleteDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).ListTrash(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_RestoreData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).RestoreData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_RestoreData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).RestoreData(ctx, req.(*RestoreDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_SyncData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).SyncData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_SyncData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).SyncData(ctx, req.(*SyncDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_UploadBinary_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GophKeeperServiceServer).UploadBinary(&gophKeeperServiceUploadBinaryServer{stream})
+}
+
+type GophKeeperService_UploadBinaryServer interface {
+	SendAndClose(*UploadBinaryResponse) error
+	Recv() (*UploadBinaryRequest, error)
+	grpc.ServerStream
+}
+
+type gophKeeperServiceUploadBinaryServer struct {
+	grpc.ServerStream
+}
+
+func (x *gophKeeperServiceUploadBinaryServer) SendAndClose(m *UploadBinaryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *gophKeeperServiceUploadBinaryServer) Recv() (*UploadBinaryRequest, error) {
+	m := new(UploadBinaryRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _GophKeeperService_DownloadBinary_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadBinaryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophKeeperServiceServer).DownloadBinary(m, &gophKeeperServiceDownloadBinaryServer{stream})
+}
+
+type GophKeeperService_DownloadBinaryServer interface {
+	Send(*DownloadBinaryResponse) error
+	grpc.ServerStream
+}
+
+type gophKeeperServiceDownloadBinaryServer struct {
+	grpc.ServerStream
+}
+
+func (x *gophKeeperServiceDownloadBinaryServer) Send(m *DownloadBinaryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GophKeeperService_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_RefreshToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).RefreshToken(ctx, req.(*RefreshTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_Logout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).Logout(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).ListSessions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_ListVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).ListVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_ListVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).ListVersions(ctx, req.(*ListVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_GetVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).GetVersion(ctx, req.(*GetVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_RestoreVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).RestoreVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_RestoreVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).RestoreVersion(ctx, req.(*RestoreVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_SearchData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).SearchData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_SearchData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).SearchData(ctx, req.(*SearchDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_CreateFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).CreateFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_CreateFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).CreateFolder(ctx, req.(*CreateFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_ListFolders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).ListFolders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_ListFolders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).ListFolders(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_DeleteFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).DeleteFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_DeleteFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServiceServer).DeleteFolder(ctx, req.(*DeleteFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeperService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeperService_Ping_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
 		return srv.(GophKeeperServiceServer).Ping(ctx, req.(*emptypb.Empty))
@@ -372,6 +1355,58 @@ var GophKeeperService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Login",
 			Handler:    _GophKeeperService_Login_Handler,
 		},
+		{
+			MethodName: "RegisterSrp",
+			Handler:    _GophKeeperService_RegisterSrp_Handler,
+		},
+		{
+			MethodName: "LoginSrpStart",
+			Handler:    _GophKeeperService_LoginSrpStart_Handler,
+		},
+		{
+			MethodName: "LoginSrpFinish",
+			Handler:    _GophKeeperService_LoginSrpFinish_Handler,
+		},
+		{
+			MethodName: "SetSrpVerifier",
+			Handler:    _GophKeeperService_SetSrpVerifier_Handler,
+		},
+		{
+			MethodName: "SetKeyCheck",
+			Handler:    _GophKeeperService_SetKeyCheck_Handler,
+		},
+		{
+			MethodName: "GetPasswordPolicy",
+			Handler:    _GophKeeperService_GetPasswordPolicy_Handler,
+		},
+		{
+			MethodName: "VerifyLoginTwoFactor",
+			Handler:    _GophKeeperService_VerifyLoginTwoFactor_Handler,
+		},
+		{
+			MethodName: "EnrollTwoFactor",
+			Handler:    _GophKeeperService_EnrollTwoFactor_Handler,
+		},
+		{
+			MethodName: "ConfirmTwoFactor",
+			Handler:    _GophKeeperService_ConfirmTwoFactor_Handler,
+		},
+		{
+			MethodName: "DisableTwoFactor",
+			Handler:    _GophKeeperService_DisableTwoFactor_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _GophKeeperService_ChangePassword_Handler,
+		},
+		{
+			MethodName: "ChangeUsername",
+			Handler:    _GophKeeperService_ChangeUsername_Handler,
+		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _GophKeeperService_DeleteAccount_Handler,
+		},
 		{
 			MethodName: "GetData",
 			Handler:    _GophKeeperService_GetData_Handler,
@@ -392,15 +1427,78 @@ var GophKeeperService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteData",
 			Handler:    _GophKeeperService_DeleteData_Handler,
 		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _GophKeeperService_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreData",
+			Handler:    _GophKeeperService_RestoreData_Handler,
+		},
 		{
 			MethodName: "SyncData",
 			Handler:    _GophKeeperService_SyncData_Handler,
 		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _GophKeeperService_RefreshToken_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _GophKeeperService_Logout_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _GophKeeperService_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _GophKeeperService_RevokeSession_Handler,
+		},
+		{
+			MethodName: "ListVersions",
+			Handler:    _GophKeeperService_ListVersions_Handler,
+		},
+		{
+			MethodName: "GetVersion",
+			Handler:    _GophKeeperService_GetVersion_Handler,
+		},
+		{
+			MethodName: "RestoreVersion",
+			Handler:    _GophKeeperService_RestoreVersion_Handler,
+		},
+		{
+			MethodName: "SearchData",
+			Handler:    _GophKeeperService_SearchData_Handler,
+		},
+		{
+			MethodName: "CreateFolder",
+			Handler:    _GophKeeperService_CreateFolder_Handler,
+		},
+		{
+			MethodName: "ListFolders",
+			Handler:    _GophKeeperService_ListFolders_Handler,
+		},
+		{
+			MethodName: "DeleteFolder",
+			Handler:    _GophKeeperService_DeleteFolder_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _GophKeeperService_Ping_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadBinary",
+			Handler:       _GophKeeperService_UploadBinary_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadBinary",
+			Handler:       _GophKeeperService_DownloadBinary_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "gophkeeper.proto",
 }
@@ -0,0 +1,267 @@
+// Package srp implements the SRP-6a password-authenticated key exchange (RFC 2945, RFC 5054)
+// shared by the client and the server. The server only stores a salt and a verifier derived
+// from the password, and a login proves the knowledge of the password without sending it.
+//
+// The 2048-bit group of RFC 5054 and SHA-256 are used. Unlike RFC 5054, the private key x
+// is derived with Argon2id and does not depend on the username, so a stolen verifier is
+// expensive to brute force and renaming an account keeps its verifier valid.
+package srp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"math/big"
+)
+
+const (
+	// SaltSize is the size of the salts generated by NewSalt.
+	SaltSize = 16
+	// MinSaltSize and MaxSaltSize bound the size of the salts accepted by the server.
+	MinSaltSize = 16
+	MaxSaltSize = 64
+
+	ephemeralSize = 32
+
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	argon2KeySize = 32
+
+	groupPrime = "AC6BDB41324A9A9BF166DE5E1389582FAF72B6651987EE07FC3192943DB56050A37329CBB4A099ED8193E0757767A13D" +
+		"D52312AB4B03310DCD7F48A9DA04FD50E8083969EDB767B0CF6095179A163AB3661A05FBD5FAAAE82918A9962F0B93B8" +
+		"55F97993EC975EEAA80D740ADBF4FF747359D041D5C33EA71D281E446B14773BCA97B43A23FB801676BD207A436C6481" +
+		"F1D2B9078717461A5B9D32E688F87748544523B524B0D57D5EA77A2775D2ECFA032CFBDBF52FB3786160279004E57AE6" +
+		"AF874E7303CE53299CCC041C7BC308D82A5698F3A8D0C38271AE35F8E9DBFBB694B5C803D89F7AE435DE236D525F5475" +
+		"9B65E372FCD68EF20FA7111F9E4AFF73"
+)
+
+var (
+	// ErrInvalidPublicKey is returned when the public ephemeral value of the other party is zero modulo N.
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrInvalidVerifier is returned when the verifier is not a valid element of the group.
+	ErrInvalidVerifier = errors.New("invalid verifier")
+	// ErrInvalidProof is returned when the proof of the other party does not match.
+	ErrInvalidProof = errors.New("invalid proof")
+
+	n = mustParseHex(groupPrime)
+	g = big.NewInt(2)
+	k = new(big.Int).SetBytes(hash(n.Bytes(), pad(g)))
+
+	nLen = len(n.Bytes())
+)
+
+// NewSalt generates a random salt for a new verifier.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	return salt, nil
+}
+
+// Verifier computes the verifier v = g^x of the password and salt, which the server stores
+// instead of the password.
+func Verifier(password string, salt []byte) []byte {
+	return new(big.Int).Exp(g, privateKey(password, salt), n).Bytes()
+}
+
+// ValidateVerifier checks that the verifier received from a client is an element of the group.
+func ValidateVerifier(verifier []byte) error {
+	v := new(big.Int).SetBytes(verifier)
+	if len(verifier) > nLen || v.Cmp(big.NewInt(1)) <= 0 || v.Cmp(n) >= 0 {
+		return ErrInvalidVerifier
+	}
+	return nil
+}
+
+// ValidatePublicKey checks that the public ephemeral value is not zero modulo N.
+func ValidatePublicKey(public []byte) error {
+	if len(public) > nLen || new(big.Int).Mod(new(big.Int).SetBytes(public), n).Sign() == 0 {
+		return ErrInvalidPublicKey
+	}
+	return nil
+}
+
+// Client holds the state of the client side of a login.
+type Client struct {
+	password string
+	a        *big.Int
+	public   []byte
+
+	serverProof []byte
+	key         []byte
+}
+
+// NewClient starts the client side of a login, generating the ephemeral key pair.
+func NewClient(password string) (*Client, error) {
+	a, err := newEphemeral()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		password: password,
+		a:        a,
+		public:   new(big.Int).Exp(g, a, n).Bytes(),
+	}, nil
+}
+
+// PublicKey returns the public ephemeral value A sent to the server.
+func (c *Client) PublicKey() []byte {
+	return c.public
+}
+
+// Proof computes the client proof M1 from the salt and the public ephemeral value B
+// received from the server.
+func (c *Client) Proof(username string, salt, serverPublic []byte) ([]byte, error) {
+	if err := ValidatePublicKey(serverPublic); err != nil {
+		return nil, err
+	}
+
+	B := new(big.Int).SetBytes(serverPublic)
+	A := new(big.Int).SetBytes(c.public)
+	u := scramble(A, B)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+
+	x := privateKey(c.password, salt)
+
+	// S = (B - k * g^x) ^ (a + u * x) mod N
+	base := new(big.Int).Sub(B, new(big.Int).Mul(k, new(big.Int).Exp(g, x, n)))
+	base.Mod(base, n)
+	exp := new(big.Int).Add(c.a, new(big.Int).Mul(u, x))
+	S := new(big.Int).Exp(base, exp, n)
+
+	c.key = hash(S.Bytes())
+	clientProof := proof(username, salt, A, B, c.key)
+	c.serverProof = hash(pad(A), clientProof, c.key)
+
+	return clientProof, nil
+}
+
+// VerifyServer checks the server proof M2, confirming that the server knows the verifier.
+func (c *Client) VerifyServer(serverProof []byte) error {
+	if c.serverProof == nil || subtle.ConstantTimeCompare(c.serverProof, serverProof) != 1 {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// NewServerKey generates the server ephemeral key pair of a login for the verifier.
+// The secret b has to be kept by the server until the login is finished, the public
+// value B is sent to the client.
+func NewServerKey(verifier []byte) (secret, public []byte, err error) {
+	if err = ValidateVerifier(verifier); err != nil {
+		return nil, nil, err
+	}
+
+	b, err := newEphemeral()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return b.Bytes(), serverPublic(verifier, b).Bytes(), nil
+}
+
+// ServerProofs computes the client proof M1 the server expects for the login and the server
+// proof M2 returned to the client once M1 matches.
+func ServerProofs(username string, salt, verifier, secret, clientPublic []byte) (clientProof, serverProof []byte, err error) {
+	if err = ValidateVerifier(verifier); err != nil {
+		return nil, nil, err
+	}
+
+	if err = ValidatePublicKey(clientPublic); err != nil {
+		return nil, nil, err
+	}
+
+	A := new(big.Int).SetBytes(clientPublic)
+	b := new(big.Int).SetBytes(secret)
+	B := serverPublic(verifier, b)
+	u := scramble(A, B)
+	if u.Sign() == 0 {
+		return nil, nil, ErrInvalidPublicKey
+	}
+
+	// S = (A * v^u) ^ b mod N
+	v := new(big.Int).SetBytes(verifier)
+	base := new(big.Int).Mul(A, new(big.Int).Exp(v, u, n))
+	base.Mod(base, n)
+	S := new(big.Int).Exp(base, b, n)
+
+	key := hash(S.Bytes())
+	clientProof = proof(username, salt, A, B, key)
+
+	return clientProof, hash(pad(A), clientProof, key), nil
+}
+
+// VerifyClient compares the client proof with the expected one in constant time.
+func VerifyClient(expected, clientProof []byte) error {
+	if subtle.ConstantTimeCompare(expected, clientProof) != 1 {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// serverPublic computes B = k * v + g^b mod N.
+func serverPublic(verifier []byte, b *big.Int) *big.Int {
+	v := new(big.Int).SetBytes(verifier)
+	B := new(big.Int).Add(new(big.Int).Mul(k, v), new(big.Int).Exp(g, b, n))
+	return B.Mod(B, n)
+}
+
+// privateKey derives x = H(salt | Argon2id(password, salt)).
+func privateKey(password string, salt []byte) *big.Int {
+	stretched := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeySize)
+	return new(big.Int).SetBytes(hash(salt, stretched))
+}
+
+// scramble computes u = H(PAD(A) | PAD(B)).
+func scramble(A, B *big.Int) *big.Int {
+	return new(big.Int).SetBytes(hash(pad(A), pad(B)))
+}
+
+// proof computes M1 = H(H(N) xor H(g) | H(I) | s | A | B | K).
+func proof(username string, salt []byte, A, B *big.Int, key []byte) []byte {
+	hn := hash(n.Bytes())
+	hg := hash(pad(g))
+	for i := range hn {
+		hn[i] ^= hg[i]
+	}
+	return hash(hn, hash([]byte(username)), salt, A.Bytes(), B.Bytes(), key)
+}
+
+// newEphemeral generates a random private ephemeral value.
+func newEphemeral() (*big.Int, error) {
+	buf := make([]byte, ephemeralSize)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generate ephemeral key: %w", err)
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// pad returns the value left-padded with zeros to the length of N.
+func pad(value *big.Int) []byte {
+	return value.FillBytes(make([]byte, nLen))
+}
+
+// hash returns the SHA-256 hash of the concatenated values.
+func hash(values ...[]byte) []byte {
+	h := sha256.New()
+	h.Write(bytes.Join(values, nil))
+	return h.Sum(nil)
+}
+
+// mustParseHex parses the hexadecimal constant of the group.
+func mustParseHex(s string) *big.Int {
+	value, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("srp: invalid group constant")
+	}
+	return value
+}
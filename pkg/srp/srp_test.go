@@ -0,0 +1,107 @@
+package srp
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestLogin(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatalf("NewSalt() error = %v", err)
+	}
+	verifier := Verifier("correct horse", salt)
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{
+			name:     "valid password",
+			password: "correct horse",
+		},
+		{
+			name:     "wrong password",
+			password: "battery staple",
+			wantErr:  ErrInvalidProof,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.password)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			secret, public, err := NewServerKey(verifier)
+			if err != nil {
+				t.Fatalf("NewServerKey() error = %v", err)
+			}
+
+			clientProof, err := client.Proof("alice", salt, public)
+			if err != nil {
+				t.Fatalf("Proof() error = %v", err)
+			}
+
+			expected, serverProof, err := ServerProofs("alice", salt, verifier, secret, client.PublicKey())
+			if err != nil {
+				t.Fatalf("ServerProofs() error = %v", err)
+			}
+
+			err = VerifyClient(expected, clientProof)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyClient() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if err = client.VerifyServer(serverProof); err != nil {
+				t.Errorf("VerifyServer() error = %v", err)
+			}
+			if err = client.VerifyServer(clientProof); !errors.Is(err, ErrInvalidProof) {
+				t.Errorf("VerifyServer() error = %v, want %v", err, ErrInvalidProof)
+			}
+		})
+	}
+}
+
+func TestInvalidPublicKeys(t *testing.T) {
+	salt, _ := NewSalt()
+	verifier := Verifier("password", salt)
+	secret, _, _ := NewServerKey(verifier)
+
+	for _, public := range [][]byte{{0}, n.Bytes(), new(big.Int).Mul(n, big.NewInt(2)).Bytes()} {
+		if _, _, err := ServerProofs("alice", salt, verifier, secret, public); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("ServerProofs() error = %v, want %v", err, ErrInvalidPublicKey)
+		}
+
+		client, _ := NewClient("password")
+		if _, err := client.Proof("alice", salt, public); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("Proof() error = %v, want %v", err, ErrInvalidPublicKey)
+		}
+	}
+}
+
+func TestValidateVerifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier []byte
+		wantErr  bool
+	}{
+		{name: "valid", verifier: Verifier("password", []byte("0123456789abcdef"))},
+		{name: "empty", verifier: nil, wantErr: true},
+		{name: "one", verifier: []byte{1}, wantErr: true},
+		{name: "modulus", verifier: n.Bytes(), wantErr: true},
+		{name: "too long", verifier: append([]byte{1}, n.Bytes()...), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateVerifier(tt.verifier); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 secret of the test vectors of RFC 6238.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{name: "59", unix: 59, want: "287082"},
+		{name: "1111111109", unix: 1111111109, want: "081804"},
+		{name: "1111111111", unix: 1111111111, want: "050471"},
+		{name: "1234567890", unix: 1234567890, want: "005924"},
+		{name: "2000000000", unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(rfcSecret, Step(time.Unix(tt.unix, 0))); got != tt.want {
+				t.Errorf("Code() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := Step(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current code", code: Code(rfcSecret, step), wantStep: step, wantOK: true},
+		{name: "previous code", code: Code(rfcSecret, step-1), wantStep: step - 1, wantOK: true},
+		{name: "next code", code: Code(rfcSecret, step+1), wantStep: step + 1, wantOK: true},
+		{name: "expired code", code: Code(rfcSecret, step-2)},
+		{name: "used code", code: Code(rfcSecret, step), lastStep: step},
+		{name: "spaces", code: Code(rfcSecret, step)[:3] + " " + Code(rfcSecret, step)[3:], wantStep: step, wantOK: true},
+		{name: "wrong length", code: "12345"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := Validate(rfcSecret, tt.code, now, tt.lastStep)
+			if gotStep != tt.wantStep || gotOK != tt.wantOK {
+				t.Errorf("Validate() = %v, %v, want %v, %v", gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSecret(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatalf("NewSecret() error = %v", err)
+	}
+
+	encoded := EncodeSecret(secret)
+	decoded, err := DecodeSecret(strings.ToLower(encoded[:8]) + " " + encoded[8:])
+	if err != nil || string(decoded) != string(secret) {
+		t.Errorf("DecodeSecret() = %x, %v, want %x", decoded, err, secret)
+	}
+
+	uri := ProvisioningURI("GophKeeper", "alice", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/GophKeeper:alice?") || !strings.Contains(uri, "secret="+encoded) {
+		t.Errorf("ProvisioningURI() = %v", uri)
+	}
+}
@@ -1,8 +1,18 @@
 package main
 
-import "gophKeeper/server/internal/app"
+import (
+	"flag"
+	"gophKeeper/server/internal/app"
+	"os"
+)
 
 func main() {
+	flag.Parse()
+
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrate(flag.Args()[1:]))
+	}
+
 	a := &app.App{}
 
 	a.Init()
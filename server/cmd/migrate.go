@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gophKeeper/server/internal/conf"
+	"gophKeeper/server/internal/migrator"
+	"os"
+	"os/signal"
+	"strconv"
+)
+
+const migrateUsage = `usage: server [flags] migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [N]    roll back the last N migrations, 1 by default
+  version     print the current schema version
+  force V     set the schema version to V without running migrations,
+              used to recover from a failed migration
+`
+
+// runMigrate runs the migrate subcommand with the given arguments and returns the exit code.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	m, err := migrator.New(conf.Conf.PgDsn, conf.Conf.MigrationsLockTimeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	defer m.Close()
+
+	switch command := args[0]; {
+	case command == "up" && len(args) == 1:
+		err = m.Up(ctx)
+	case command == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				fmt.Fprintf(os.Stderr, "migrate: invalid number of migrations %q\n", args[1])
+				return 2
+			}
+		}
+		err = m.Down(ctx, steps)
+	case command == "force" && len(args) == 2:
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < -1 {
+			fmt.Fprintf(os.Stderr, "migrate: invalid version %q\n", args[1])
+			return 2
+		}
+		err = m.Force(ctx, version)
+	case command == "version" && len(args) == 1:
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s: %v\n", args[0], err)
+		return 1
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate version: %v\n", err)
+		return 1
+	}
+
+	if dirty {
+		fmt.Printf("version %d (dirty, fix the schema and run force)\n", version)
+		return 1
+	}
+	fmt.Printf("version %d\n", version)
+
+	return 0
+}
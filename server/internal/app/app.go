@@ -11,19 +11,29 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"gophKeeper/pkg/password"
 	"gophKeeper/pkg/proto/gophkeeper"
 	"gophKeeper/server/internal/conf"
 	authorizerServiceP "gophKeeper/server/internal/domain/auth/service"
 	dataItemsServiceP "gophKeeper/server/internal/domain/dataitems/service"
+	sessionsServiceP "gophKeeper/server/internal/domain/sessions/service"
+	twoFactorServiceP "gophKeeper/server/internal/domain/twofactor/service"
 	usersServiceP "gophKeeper/server/internal/domain/users/service"
 	grpcHandler "gophKeeper/server/internal/handler/grpc"
+	"gophKeeper/server/internal/migrator"
+	"gophKeeper/server/internal/ratelimit"
 	dataItemsUsecaseP "gophKeeper/server/internal/usecase/dataitems"
 	usersUsecaseP "gophKeeper/server/internal/usecase/users"
 	"net"
 	"os/signal"
+	"time"
 
+	dataItemsRepoFsP "gophKeeper/server/internal/domain/dataitems/repo/fs"
+	dataItemsRepoMemP "gophKeeper/server/internal/domain/dataitems/repo/mem"
 	dataItemsRepoPgP "gophKeeper/server/internal/domain/dataitems/repo/pg"
 	dataItemsRepoS3P "gophKeeper/server/internal/domain/dataitems/repo/s3"
+	sessionsRepoPgP "gophKeeper/server/internal/domain/sessions/repo/pg"
+	twoFactorRepoPgP "gophKeeper/server/internal/domain/twofactor/repo/pg"
 	usersRepoPgP "gophKeeper/server/internal/domain/users/repo/pg"
 	"log/slog"
 	"os"
@@ -45,6 +55,10 @@ type App struct {
 	// grpc server
 	grpcServer *grpc.Server
 
+	// trash purger
+	purgerCancel context.CancelFunc
+	purgerDone   chan struct{}
+
 	exitCode int
 }
 
@@ -52,6 +66,12 @@ type App struct {
 func (a *App) Init() {
 	var err error
 
+	// migrations
+	if conf.Conf.MigrateOnStart {
+		err = migrateUp(context.Background())
+		errCheck(err, "migrateUp")
+	}
+
 	// pgpool
 	{
 		a.pgpool, err = pgxpool.New(context.Background(), conf.Conf.PgDsn)
@@ -60,23 +80,31 @@ func (a *App) Init() {
 
 	// auth
 	{
-		a.authorizer = authorizerServiceP.New(conf.Conf.JwtSecret)
+		a.authorizer = authorizerServiceP.New(conf.Conf.JwtSecret, conf.Conf.AccessTokenTTL)
 	}
 
-	// users
+	// data items, the users usecase deletes the files of deleted accounts with their service
+	var dataItemsService *dataItemsServiceP.Service
 	{
-		usersRepo := usersRepoPgP.New(a.pgpool)
-		usersService := usersServiceP.New(usersRepo)
-		a.usersUsecase = usersUsecaseP.New(usersService, a.authorizer)
+		dataItemsPgRepo := dataItemsRepoPgP.New(a.pgpool)
+		dataItemsBlobRepo, err := a.newBlobRepo(context.Background())
+		errCheck(err, "dataItemsBlobRepo")
+		dataItemsService = dataItemsServiceP.New(dataItemsPgRepo, dataItemsBlobRepo)
+		a.dataItemsUsecase = dataItemsUsecaseP.New(dataItemsService)
 	}
 
-	// data items
+	// users
 	{
-		dataItemsPgRepo := dataItemsRepoPgP.New(a.pgpool)
-		dataItemsS3Repo, err := dataItemsRepoS3P.NewS3Repo(context.Background(), conf.Conf.S3Endpoint, conf.Conf.S3AccessKey, conf.Conf.S3SecretKey, conf.Conf.S3Bucket)
-		errCheck(err, "dataItemsS3Repo")
-		dataItemsSerivce := dataItemsServiceP.New(dataItemsPgRepo, dataItemsS3Repo)
-		a.dataItemsUsecase = dataItemsUsecaseP.New(dataItemsSerivce)
+		usersRepo := usersRepoPgP.New(a.pgpool)
+		usersService := usersServiceP.New(usersRepo, conf.Conf.JwtSecret, conf.Conf.LockoutThreshold, conf.Conf.LockoutDuration, password.Policy{
+			MinLength:  conf.Conf.PasswordMinLength,
+			MinEntropy: conf.Conf.PasswordMinEntropy,
+		})
+		sessionsRepo := sessionsRepoPgP.New(a.pgpool)
+		sessionsService := sessionsServiceP.New(sessionsRepo, conf.Conf.RefreshTokenTTL)
+		twoFactorRepo := twoFactorRepoPgP.New(a.pgpool)
+		twoFactorService := twoFactorServiceP.New(twoFactorRepo, conf.Conf.TotpIssuer)
+		a.usersUsecase = usersUsecaseP.New(usersService, a.authorizer, sessionsService, twoFactorService, dataItemsService)
 	}
 
 	// grpc server
@@ -91,11 +119,21 @@ func (a *App) Init() {
 			opts = append(opts, grpc.Creds(tlsConfig))
 		}
 
-		interceptors := make([]grpc.UnaryServerInterceptor, 0, 3)
+		interceptors := make([]grpc.UnaryServerInterceptor, 0, 4)
 
 		interceptors = append(interceptors, grpcHandler.GrpcInterceptorLogger())
+		interceptors = append(interceptors, grpcHandler.GrpcInterceptorErrors())
+		interceptors = append(interceptors, grpcHandler.GrpcInterceptorRateLimit(ratelimit.New(
+			conf.Conf.RateLimitPerMinute, conf.Conf.RateLimitBurst, conf.Conf.LoginBackoffBase, conf.Conf.LoginBackoffMax), a.usersUsecase))
+		interceptors = append(interceptors, grpcHandler.GrpcInterceptorAuth(a.usersUsecase))
+
+		streamInterceptors := []grpc.StreamServerInterceptor{
+			grpcHandler.GrpcStreamInterceptorErrors(),
+			grpcHandler.GrpcStreamInterceptorAuth(a.usersUsecase),
+		}
 
 		opts = append(opts, grpc.ChainUnaryInterceptor(interceptors...))
+		opts = append(opts, grpc.ChainStreamInterceptor(streamInterceptors...))
 
 		a.grpcServer = grpc.NewServer(opts...)
 
@@ -125,6 +163,17 @@ func (a *App) Start() {
 
 		slog.Info("GRPC-server started successfully " + lis.Addr().String())
 	}
+
+	// trash purger, a non-positive interval disables it
+	if conf.Conf.TrashPurgeInterval > 0 {
+		var ctx context.Context
+		ctx, a.purgerCancel = context.WithCancel(context.Background())
+		a.purgerDone = make(chan struct{})
+
+		go a.runTrashPurger(ctx)
+	} else {
+		slog.Info("Trash purger disabled")
+	}
 }
 
 // Listen listens for signals to stop the application
@@ -140,12 +189,43 @@ func (a *App) Listen() {
 func (a *App) Stop() {
 	slog.Info("Shutting down...")
 
+	// trash purger
+	if a.purgerCancel != nil {
+		a.purgerCancel()
+		<-a.purgerDone
+	}
+
 	// grpc server
 	{
 		a.grpcServer.GracefulStop()
 	}
 }
 
+// runTrashPurger periodically purges the data items that have been in the trash longer
+// than the retention period, until the context is canceled.
+func (a *App) runTrashPurger(ctx context.Context) {
+	defer close(a.purgerDone)
+
+	ticker := time.NewTicker(conf.Conf.TrashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := a.dataItemsUsecase.PurgeTrash(ctx, conf.Conf.TrashRetention)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("purge trash", slog.String("error", err.Error()))
+		}
+		if purged > 0 {
+			slog.Info("Trash purged", slog.Int("items", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // Exit gracefully shuts down the application by logging the exit action
 // and then terminating the program with the specified exit code.
 func (a *App) Exit() {
@@ -154,6 +234,46 @@ func (a *App) Exit() {
 	os.Exit(a.exitCode)
 }
 
+// migrateUp applies the pending embedded migrations to the database. Replicas starting
+// at the same time wait for each other, so the schema is migrated once.
+func migrateUp(ctx context.Context) error {
+	m, err := migrator.New(conf.Conf.PgDsn, conf.Conf.MigrationsLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err = m.Up(ctx); err != nil {
+		return err
+	}
+
+	version, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Database schema is up to date", slog.Uint64("version", uint64(version)))
+
+	return nil
+}
+
+// newBlobRepo creates the blob storage of the binary data selected by conf.Conf.BlobStorage.
+func (a *App) newBlobRepo(ctx context.Context) (dataItemsServiceP.RepoBlob, error) {
+	switch conf.Conf.BlobStorage {
+	case "s3":
+		return dataItemsRepoS3P.NewS3Repo(ctx, conf.Conf.S3Endpoint, conf.Conf.S3AccessKey, conf.Conf.S3SecretKey, conf.Conf.S3Bucket)
+	case "fs":
+		return dataItemsRepoFsP.NewFSRepo(conf.Conf.BlobDir)
+	case "pg":
+		return dataItemsRepoPgP.NewBlobRepo(a.pgpool, conf.Conf.BlobInlineMaxSize), nil
+	case "mem":
+		slog.Warn("Binary data is kept in memory and is lost on restart")
+		return dataItemsRepoMemP.NewMemRepo(), nil
+	default:
+		return nil, fmt.Errorf("unknown blob storage %q, expected one of s3, fs, pg, mem", conf.Conf.BlobStorage)
+	}
+}
+
 // loadTLSCredentials loads the TLS credentials for the server, including the server's
 // certificate, private key, and the client's Certificate Authority (CA) certificate for mutual TLS.
 // It returns the configured TransportCredentials and an error if any of the loading steps fail.
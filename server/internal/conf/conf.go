@@ -4,6 +4,7 @@ package conf
 import (
 	"flag"
 	"github.com/caarlos0/env/v9"
+	"time"
 )
 
 // Conf represents the application configuration.
@@ -14,11 +15,47 @@ var Conf = struct {
 	GRPCPort       string `env:"GRPC_PORT"`
 	PgDsn          string `env:"DATABASE_URI"`
 	JwtSecret      string `env:"JWT_SECRET"`
-	S3Endpoint     string `env:"S3_ENDPOINT" envDefault:"localhost:9000"`
-	S3Bucket       string `env:"S3_BUCKET" envDefault:"mybucket"`
-	S3AccessKey    string `env:"S3_ACCESS_KEY" envDefault:"minioadmin"`
-	S3SecretKey    string `env:"S3_SECRET_KEY" envDefault:"minioadmin"`
-	EnableTLS      bool   `env:"ENABLE_TLS" envDefault:"true"`
+	// AccessTokenTTL is the lifetime of the access tokens, RefreshTokenTTL is the lifetime
+	// of an unused session, extended on every token refresh.
+	AccessTokenTTL  time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+	// TrashRetention is how long deleted items stay in the trash before they are purged,
+	// TrashPurgeInterval is how often the trash is checked for such items, zero or a negative
+	// interval disables the purging.
+	TrashRetention     time.Duration `env:"TRASH_RETENTION" envDefault:"720h"`
+	TrashPurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"1h"`
+	S3Endpoint         string        `env:"S3_ENDPOINT" envDefault:"localhost:9000"`
+	S3Bucket           string        `env:"S3_BUCKET" envDefault:"mybucket"`
+	S3AccessKey        string        `env:"S3_ACCESS_KEY" envDefault:"minioadmin"`
+	S3SecretKey        string        `env:"S3_SECRET_KEY" envDefault:"minioadmin"`
+	EnableTLS          bool          `env:"ENABLE_TLS" envDefault:"true"`
+	// MigrateOnStart applies the pending database migrations when the server starts,
+	// waiting up to MigrationsLockTimeout while another replica is migrating.
+	MigrateOnStart        bool          `env:"MIGRATE_ON_START" envDefault:"true"`
+	MigrationsLockTimeout time.Duration `env:"MIGRATIONS_LOCK_TIMEOUT" envDefault:"1m"`
+	// BlobStorage selects where the binary data is stored: "s3" for an S3-compatible storage,
+	// "fs" for the BlobDir directory, "pg" for PostgreSQL itself with files of at most
+	// BlobInlineMaxSize bytes, and "mem" for memory, losing the files on restart.
+	BlobStorage       string `env:"BLOB_STORAGE" envDefault:"s3"`
+	BlobDir           string `env:"BLOB_DIR" envDefault:"data/blobs"`
+	BlobInlineMaxSize int64  `env:"BLOB_INLINE_MAX_SIZE" envDefault:"1048576"`
+	// TotpIssuer names the service in the authenticator apps of users with two-factor authentication.
+	TotpIssuer string `env:"TOTP_ISSUER" envDefault:"GophKeeper"`
+	// RateLimitPerMinute and RateLimitBurst throttle the authentication requests of every client
+	// address and username, LoginBackoffBase doubles up to LoginBackoffMax after every failed attempt.
+	// Accounts are locked for LockoutDuration after LockoutThreshold failed logins in a row.
+	// Zero RateLimitPerMinute, LoginBackoffBase and LockoutThreshold disable the respective protection.
+	RateLimitPerMinute int           `env:"RATE_LIMIT_PER_MINUTE" envDefault:"20"`
+	RateLimitBurst     int           `env:"RATE_LIMIT_BURST" envDefault:"5"`
+	LoginBackoffBase   time.Duration `env:"LOGIN_BACKOFF_BASE" envDefault:"1s"`
+	LoginBackoffMax    time.Duration `env:"LOGIN_BACKOFF_MAX" envDefault:"5m"`
+	LockoutThreshold   int           `env:"LOCKOUT_THRESHOLD" envDefault:"10"`
+	LockoutDuration    time.Duration `env:"LOCKOUT_DURATION" envDefault:"15m"`
+	// PasswordMinLength and PasswordMinEntropy are the minimum number of characters and the minimum
+	// estimated strength in bits of the passwords accounts are registered with. The server checks
+	// the passwords it receives, and publishes the policy to the clients for the SRP passwords.
+	PasswordMinLength  int     `env:"PASSWORD_MIN_LENGTH" envDefault:"10"`
+	PasswordMinEntropy float64 `env:"PASSWORD_MIN_ENTROPY" envDefault:"40"`
 }{}
 
 // init initializes the configuration for the application by setting up command-line flags
@@ -0,0 +1,25 @@
+// Package model defines the authenticated principal of a request
+// and the helpers to carry it in the request context.
+package model
+
+import "context"
+
+// Principal represents the authenticated user on whose behalf a request is executed,
+// along with the session the request was made with.
+type Principal struct {
+	UserID    string
+	SessionID string
+}
+
+type principalCtxKey struct{}
+
+// NewContext returns a copy of the context carrying the principal.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, p)
+}
+
+// FromContext returns the principal stored in the context, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	principal := &Principal{UserID: "999"}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   *Principal
+		wantOk bool
+	}{
+		{
+			name:   "context with principal",
+			ctx:    NewContext(context.Background(), principal),
+			want:   principal,
+			wantOk: true,
+		},
+		{
+			name:   "context without principal",
+			ctx:    context.Background(),
+			want:   nil,
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromContext(tt.ctx)
+			if ok != tt.wantOk {
+				t.Errorf("FromContext() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromContext() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,63 +1,80 @@
 // Package service handles authentication via JWT, including token creation,
-// validation, and user ID extraction from gRPC context metadata.
+// validation, and principal extraction from gRPC context metadata.
 package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v4"
 	"google.golang.org/grpc/metadata"
+	authModel "gophKeeper/server/internal/domain/auth/model"
 	"gophKeeper/server/internal/domain/users/model"
+	"gophKeeper/server/internal/errs"
+	"strings"
 	"time"
 )
 
 const (
-	defaultJWTCookieExpiration = 24 * time.Hour
+	defaultAccessTokenTTL = 15 * time.Minute
+
+	authorizationMetadataKey = "authorization"
+	bearerScheme             = "Bearer"
 )
 
-// Claims represents the custom claims used for JWT tokens, including the user ID (UID)
-// and standard JWT registered claims like expiration time.
+// Claims represents the custom claims used for JWT tokens, including the user ID (UID),
+// the session ID (SID) and standard JWT registered claims like expiration time.
 type Claims struct {
 	jwt.RegisteredClaims
 	UID string
+	SID string
 }
 
 // Auth handles authentication-related operations, such as creating and validating JWT tokens.
 type Auth struct {
-	JwtSecret string
+	JwtSecret      string
+	AccessTokenTTL time.Duration
 }
 
-// New creates a new Auth instance with the given JWT secret.
-func New(jwtSecret string) *Auth {
-	return &Auth{JwtSecret: jwtSecret}
+// New creates a new Auth instance with the given JWT secret and access token lifetime.
+// A non-positive lifetime falls back to the default one.
+func New(jwtSecret string, accessTokenTTL time.Duration) *Auth {
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = defaultAccessTokenTTL
+	}
+
+	return &Auth{
+		JwtSecret:      jwtSecret,
+		AccessTokenTTL: accessTokenTTL,
+	}
 }
 
-// GetUserIDFromContext extracts the user ID from the JWT token found in the incoming gRPC context metadata.
-// It returns the user ID if the token is valid or an error if the token is invalid or missing.
-func (a *Auth) GetUserIDFromContext(ctx context.Context) (string, error) {
+// Authenticate validates the bearer token found in the "authorization" metadata of the incoming
+// gRPC context and returns the principal it was issued to.
+func (a *Auth) Authenticate(ctx context.Context) (*authModel.Principal, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return "", errors.New("missing metadata in context")
-	}
-
-	mdToken := md["token"]
-	if len(mdToken) == 0 {
-		return "", errors.New("missing cookies in metadata")
+		return nil, errs.MissingToken
 	}
 
 	var jwtToken string
-	for _, cookieStr := range mdToken {
-		if cookieStr[:7] == "Bearer " {
-			jwtToken = cookieStr[7:]
+	for _, value := range md.Get(authorizationMetadataKey) {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, bearerScheme) && token != "" {
+			jwtToken = token
 			break
 		}
 	}
 
 	if jwtToken == "" {
-		return "", errors.New("jwt cookie not found")
+		return nil, errs.MissingToken
 	}
 
+	return a.ParseToken(jwtToken)
+}
+
+// ParseToken validates the signature and expiration of the JWT token
+// and returns the principal it was issued to.
+func (a *Auth) ParseToken(jwtToken string) (*authModel.Principal, error) {
 	token, err := jwt.ParseWithClaims(jwtToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -65,33 +82,38 @@ func (a *Auth) GetUserIDFromContext(ctx context.Context) (string, error) {
 		return []byte(a.JwtSecret), nil
 	})
 	if err != nil {
-		return "", errors.New("invalid jwt token")
+		return nil, errs.InvalidToken
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims.UID, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.UID == "" {
+		return nil, errs.InvalidToken
 	}
 
-	return "", errors.New("invalid token")
+	return &authModel.Principal{UserID: claims.UID, SessionID: claims.SID}, nil
 }
 
-// CreateToken generates a signed JWT token for a given user, based on their user ID.
-func (a *Auth) CreateToken(u *model.User) (string, error) {
-	token, err := a.NewToken(u)
+// CreateToken generates a signed short-lived access token for a given user session
+// and returns it along with its expiration time.
+func (a *Auth) CreateToken(u *model.User, sessionID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(a.AccessTokenTTL)
+
+	token, err := a.NewToken(u, sessionID, expiresAt)
 	if err != nil {
-		return "", fmt.Errorf("cannot create auth token: %w", err)
+		return "", time.Time{}, fmt.Errorf("cannot create auth token: %w", err)
 	}
-	return token, nil
+	return token, expiresAt, nil
 }
 
-// NewToken creates a new JWT token with an expiration time and includes the user ID (UID) in the claims.
-// The token is signed using the provided JWT secret.
-func (a *Auth) NewToken(u *model.User) (string, error) {
+// NewToken creates a new JWT token with the given expiration time and includes the user ID (UID)
+// and the session ID (SID) in the claims. The token is signed using the provided JWT secret.
+func (a *Auth) NewToken(u *model.User, sessionID string, expiresAt time.Time) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(defaultJWTCookieExpiration)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
 		UID: u.UserID,
+		SID: sessionID,
 	})
 
 	signedToken, err := token.SignedString([]byte(a.JwtSecret))
@@ -4,7 +4,16 @@
 // structures for handling query parameters and editing operations.
 package model
 
-import "time"
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
 
 const (
 	CredentialsDataType = "login_password"
@@ -13,8 +22,35 @@ const (
 	BankCardDataType    = "bank_card"
 )
 
+const (
+	// DefaultPageSize is the number of items listed on a page when the page size is not set.
+	DefaultPageSize = 100
+	// MaxPageSize is the largest number of items listed on a page, larger page sizes are reduced to it.
+	MaxPageSize = 1000
+)
+
+// Limits of the structured metadata of an item.
+const (
+	MaxNameLength       = 256
+	MaxFolderNameLength = 128
+	MaxTags             = 32
+	MaxTagLength        = 64
+	MaxLabels           = 32
+	MaxLabelKeyLength   = 64
+	MaxLabelValueLength = 256
+)
+
+// ErrInvalidPageToken is returned when the page token can not be decoded.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
 // DataItems represents the core data entity, storing user-specific data,
 // including the type, content, metadata, and associated timestamps.
+// Every change of an item is kept as a version, so the same structure describes the versions
+// of the item too. ObjectKey points to the binary content of the version in the object storage.
+// Deleted items stay in the trash with DeletedAt set until they are purged.
+// Size is the size of the content in bytes, which is known without loading the content.
+// Name, FolderID, Tags and Labels are the structured metadata of the item. Unlike Meta they are
+// stored in plain text, so they can be searched by the server.
 type DataItems struct {
 	ID        string
 	UserID    string
@@ -22,18 +58,31 @@ type DataItems struct {
 	Data      []byte
 	Meta      string
 	URL       string
+	Version   int
+	ObjectKey string
+	Size      int64
+	Name      string
+	FolderID  string
+	Tags      []string
+	Labels    map[string]string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DeletedAt *time.Time
 }
 
 // GetPars defines parameters for querying specific records,
 // allowing filtering by ID, UserID, Type, Meta, or URL.
+// Items in the trash are skipped unless WithDeleted is set.
 type GetPars struct {
-	ID     string
-	UserID string
-	Type   string
-	Meta   string
-	URL    string
+	ID          string
+	UserID      string
+	Type        string
+	Meta        string
+	URL         string
+	WithDeleted bool
+	// ForUpdate locks the item row until the end of the transaction, so concurrent changes
+	// of the item wait for it and see its outcome.
+	ForUpdate bool
 }
 
 // IsValid checks if at least one field in GetPars is populated.
@@ -41,8 +90,22 @@ func (m *GetPars) IsValid() bool {
 	return m.ID != "" || m.UserID != "" || m.Type != "" || m.Meta != "" || m.URL != ""
 }
 
+// VersionPars defines parameters for querying a version of the item of a user.
+type VersionPars struct {
+	ItemID  string
+	UserID  string
+	Version int
+}
+
+// IsValid checks if the item and its owner are set.
+func (m *VersionPars) IsValid() bool {
+	return m.ItemID != "" && m.UserID != ""
+}
+
 // ListPars defines parameters for listing records with optional filters,
 // supporting filtering by IDs, UserIDs, type, metadata, URL, and timestamps.
+// Items in the trash are skipped unless WithDeleted is set, OnlyDeleted or DeletedBefore
+// select the items in the trash only.
 type ListPars struct {
 	ID            *string
 	IDs           *[]string
@@ -55,6 +118,85 @@ type ListPars struct {
 	CreatedAfter  *time.Time
 	UpdatedBefore *time.Time
 	UpdatedAfter  *time.Time
+	DeletedBefore *time.Time
+	WithDeleted   bool
+	OnlyDeleted   bool
+	// WithoutData lists the summaries of the items, leaving the content empty.
+	WithoutData bool
+	// Sort orders the items by the field and the item ID, which makes the order stable
+	// for keyset pagination. Items are not ordered if it is not set.
+	Sort       SortField
+	Descending bool
+	// After lists the items following the cursor in the sort order.
+	After *Cursor
+	Limit uint64
+}
+
+// SortField is the field the listed items are ordered by.
+type SortField string
+
+const (
+	SortByUpdatedAt SortField = "updated_at"
+	SortByCreatedAt SortField = "created_at"
+	SortByID        SortField = "id"
+)
+
+// IsValid checks if the items can be ordered by the field.
+func (f SortField) IsValid() bool {
+	switch f {
+	case SortByUpdatedAt, SortByCreatedAt, SortByID:
+		return true
+	default:
+		return false
+	}
+}
+
+// Cursor points to the last item of a page, the next page starts right after it.
+// It keeps the sort order it was created for, so it can not be reused with another one.
+type Cursor struct {
+	Sort       SortField `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Time       time.Time `json:"t,omitempty"`
+	ID         string    `json:"i"`
+}
+
+// NewCursor returns the cursor pointing to the item in the given sort order.
+func NewCursor(item *DataItems, sort SortField, descending bool) *Cursor {
+	c := &Cursor{
+		Sort:       sort,
+		Descending: descending,
+		ID:         item.ID,
+	}
+
+	switch sort {
+	case SortByUpdatedAt:
+		c.Time = item.UpdatedAt
+	case SortByCreatedAt:
+		c.Time = item.CreatedAt
+	}
+
+	return c
+}
+
+// Encode returns the opaque page token of the cursor.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses the page token returned by Cursor.Encode.
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(data, &c); err != nil || !c.Sort.IsValid() || c.ID == "" {
+		return nil, ErrInvalidPageToken
+	}
+
+	return &c, nil
 }
 
 // Edit represents the editable fields for updating an existing record,
@@ -66,6 +208,109 @@ type Edit struct {
 	Data      *[]byte
 	Meta      *string
 	URL       *string
+	ObjectKey *string
+	Size      *int64
+	Name      *string
+	// FolderID moves the item to the folder, an empty ID moves it out of any folder.
+	FolderID *string
+	// Tags and Labels replace all the tags and labels of the item.
+	Tags      *[]string
+	Labels    *map[string]string
 	CreatedAt *time.Time
 	UpdatedAt *time.Time
 }
+
+// Folder groups the items of a user. Folders form a hierarchy, a folder without ParentID
+// is a top-level one.
+type Folder struct {
+	ID        string
+	UserID    string
+	ParentID  string
+	Name      string
+	CreatedAt time.Time
+}
+
+// SearchPars defines parameters for searching the items of a user by their structured metadata.
+// Every set filter has to match: the item has all the tags and labels, is in the folder
+// (or in one of its subfolders if Recursive is set), and its name, tags or labels contain
+// words starting with every term of the query.
+type SearchPars struct {
+	UserID    string
+	Query     string
+	Tags      []string
+	Labels    map[string]string
+	FolderID  string
+	Recursive bool
+	Type      string
+	Limit     uint64
+}
+
+// SearchTerms splits the search query into lower-cased words, dropping punctuation,
+// so the terms can be safely used in a full-text query.
+func SearchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NewObjectKey returns the key the binary content of the item is stored under in the object storage.
+// Every upload gets its own key, so the content of the previous versions is never overwritten.
+func NewObjectKey(id string, at time.Time) string {
+	return fmt.Sprintf("%s.%d", id, at.UnixNano())
+}
+
+// SyncAction describes what has to be done with a client item during synchronization.
+type SyncAction int
+
+const (
+	SyncActionCreate SyncAction = iota
+	SyncActionApply
+	SyncActionSkip
+	SyncActionStale
+	SyncActionConflict
+)
+
+// SyncConflict represents an item that was changed both on the client and on the server
+// since the version the client change is based on, holding both so the client can resolve it.
+// The server item is a tombstone if the item was moved to the trash meanwhile.
+type SyncConflict struct {
+	Client *DataItems
+	Server *DataItems
+}
+
+// SyncResult holds the outcome of a synchronization, including the server items the client
+// is missing or has stale, the detected conflicts, and the moment the synchronization happened.
+// The client changes applied by the synchronization are among the updates with their new versions,
+// the next changes of the client are based on them.
+type SyncResult struct {
+	Updates   []*DataItems
+	Conflicts []*SyncConflict
+	SyncedAt  time.Time
+}
+
+// ResolveSync decides what to do with a client item, comparing it with the server item
+// (nil if the server does not have it). The version of the client item is the version of the server
+// item the client change is based on. A change based on another version than the current one,
+// or on an item moved to the trash since, conflicts with the server. No timestamps are compared,
+// so the clock of the client does not matter.
+func ResolveSync(client, server *DataItems) SyncAction {
+	switch {
+	case server == nil:
+		return SyncActionCreate
+	case client.SameContent(server):
+		if client.Version == server.Version && server.DeletedAt == nil {
+			return SyncActionSkip
+		}
+		// the client has nothing to send, it only lacks the latest version or the deletion
+		return SyncActionStale
+	case server.DeletedAt != nil, client.Version != server.Version:
+		return SyncActionConflict
+	default:
+		return SyncActionApply
+	}
+}
+
+// SameContent reports whether the item has the same type, data and meta as the other one.
+func (m *DataItems) SameContent(other *DataItems) bool {
+	return m.Type == other.Type && bytes.Equal(m.Data, other.Data) && m.Meta == other.Meta
+}
@@ -1,6 +1,10 @@
 package model
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestGetPars_IsValid(t *testing.T) {
 	type fields struct {
@@ -43,3 +47,139 @@ func TestGetPars_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveSync(t *testing.T) {
+	deletedAt := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		client *DataItems
+		server *DataItems
+	}
+	tests := []struct {
+		name string
+		args args
+		want SyncAction
+	}{
+		{
+			name: "item missing on server",
+			args: args{
+				client: &DataItems{ID: "1", Data: []byte("client")},
+			},
+			want: SyncActionCreate,
+		},
+		{
+			name: "item already in sync",
+			args: args{
+				client: &DataItems{ID: "1", Data: []byte("same"), Version: 2},
+				server: &DataItems{ID: "1", Data: []byte("same"), Version: 2},
+			},
+			want: SyncActionSkip,
+		},
+		{
+			name: "client changed the current version",
+			args: args{
+				client: &DataItems{ID: "1", Data: []byte("client"), Version: 2},
+				server: &DataItems{ID: "1", Data: []byte("server"), Version: 2},
+			},
+			want: SyncActionApply,
+		},
+		{
+			name: "client changed an older version",
+			args: args{
+				client: &DataItems{ID: "1", Data: []byte("client"), Version: 1},
+				server: &DataItems{ID: "1", Data: []byte("server"), Version: 2},
+			},
+			want: SyncActionConflict,
+		},
+		{
+			name: "both sides have the same content of an item known on both without a common version",
+			args: args{
+				client: &DataItems{ID: "1", Data: []byte("same")},
+				server: &DataItems{ID: "1", Data: []byte("same"), Version: 3},
+			},
+			want: SyncActionStale,
+		},
+		{
+			name: "client has an unchanged item deleted on server",
+			args: args{
+				client: &DataItems{ID: "1", Data: []byte("same"), Version: 2},
+				server: &DataItems{ID: "1", Data: []byte("same"), Version: 2, DeletedAt: &deletedAt},
+			},
+			want: SyncActionStale,
+		},
+		{
+			name: "client changed an item deleted on server",
+			args: args{
+				client: &DataItems{ID: "1", Data: []byte("client"), Version: 2},
+				server: &DataItems{ID: "1", Data: []byte("server"), Version: 2, DeletedAt: &deletedAt},
+			},
+			want: SyncActionConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveSync(tt.args.client, tt.args.server); got != tt.want {
+				t.Errorf("ResolveSync() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	updatedAt := time.Date(2024, 8, 1, 12, 0, 0, 123456000, time.UTC)
+	item := &DataItems{ID: "1", UpdatedAt: updatedAt}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    *Cursor
+		wantErr bool
+	}{
+		{
+			name:  "encoded cursor",
+			token: NewCursor(item, SortByUpdatedAt, true).Encode(),
+			want:  &Cursor{Sort: SortByUpdatedAt, Descending: true, Time: updatedAt, ID: "1"},
+		},
+		{
+			name:    "not base64",
+			token:   "not a token!",
+			wantErr: true,
+		},
+		{
+			name:    "unknown sort field",
+			token:   (&Cursor{Sort: "meta", ID: "1"}).Encode(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeCursor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeCursor() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "words", query: "GitHub Account", want: []string{"github", "account"}},
+		{name: "punctuation", query: "e-mail: a&b | !c", want: []string{"e", "mail", "a", "b", "c"}},
+		{name: "unicode", query: "Пароль почты", want: []string{"пароль", "почты"}},
+		{name: "empty", query: " ", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SearchTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchTerms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package fs provides a blob storage keeping the binary data as files in a local directory,
+// so the server can run without an S3-compatible storage service.
+package fs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// FSRepo manages the files of the binary data stored in a local directory, one file per object key.
+type FSRepo struct {
+	Dir string
+}
+
+// NewFSRepo initializes a new FSRepo instance storing the files in the given directory,
+// which is created if it does not exist.
+func NewFSRepo(dir string) (*FSRepo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory %s: %w", dir, err)
+	}
+
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	return &FSRepo{Dir: dir}, nil
+}
+
+// GetFile reads the file stored under the object key held by pars.ID. It returns the file
+// as a byte slice, a boolean indicating if the file exists, and any error encountered.
+func (r *FSRepo) GetFile(_ context.Context, pars *model.GetPars) ([]byte, bool, error) {
+	path, err := r.path(pars.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, true, nil
+}
+
+// UploadFile stores the data under the given object key, returning the URL of the stored file or an error.
+func (r *FSRepo) UploadFile(ctx context.Context, objectKey string, data []byte) (string, error) {
+	return r.Upload(ctx, objectKey, bytes.NewReader(data), int64(len(data)))
+}
+
+// DeleteFile removes the file stored under the object key held by pars.ID.
+// Removing a missing file is not an error.
+func (r *FSRepo) DeleteFile(_ context.Context, pars *model.GetPars) error {
+	path, err := r.path(pars.ID)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// Upload streams the content of the reader to a file under the given object key, returning the URL
+// of the stored file. The content is written to a temporary file first, so a failed upload never
+// leaves a partial file behind. The size is not used, the whole content of the reader is stored.
+func (r *FSRepo) Upload(_ context.Context, objectKey string, reader io.Reader, _ int64) (string, error) {
+	path, err := r.path(objectKey)
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(r.Dir, ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = io.Copy(tmp, reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store file: %w", err)
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(), nil
+}
+
+// Download opens the file stored under the object key for reading, returning the reader, the size
+// of the file, and a boolean indicating if the file exists. The caller must close the reader.
+func (r *FSRepo) Download(_ context.Context, objectKey string) (io.ReadCloser, int64, bool, error) {
+	path, err := r.path(objectKey)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, false, nil
+		}
+		return nil, 0, false, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, 0, false, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	return file, info.Size(), true, nil
+}
+
+// path returns the path of the file stored under the object key. Keys which are not plain
+// file names are rejected, so a key can never point outside of the directory, and neither are
+// the hidden names used for the temporary files of the uploads.
+func (r *FSRepo) path(objectKey string) (string, error) {
+	if objectKey == "" || objectKey[0] == '.' || filepath.Base(objectKey) != objectKey {
+		return "", errs.InvalidInput
+	}
+
+	return filepath.Join(r.Dir, objectKey), nil
+}
@@ -0,0 +1,97 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFSRepo(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := NewFSRepo(filepath.Join(t.TempDir(), "blobs"))
+	if err != nil {
+		t.Fatalf("NewFSRepo() error = %v", err)
+	}
+
+	url, err := r.UploadFile(ctx, "1.100", []byte("binary"))
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	if !strings.HasPrefix(url, "file://") {
+		t.Errorf("UploadFile() url = %v, want a file URL", url)
+	}
+
+	if _, err = r.Upload(ctx, "1.200", strings.NewReader("streamed"), -1); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	data, found, err := r.GetFile(ctx, &model.GetPars{ID: "1.100"})
+	if err != nil || !found || string(data) != "binary" {
+		t.Errorf("GetFile() = %q, %v, %v, want %q", data, found, err, "binary")
+	}
+
+	reader, size, found, err := r.Download(ctx, "1.200")
+	if err != nil || !found {
+		t.Fatalf("Download() found = %v, error = %v", found, err)
+	}
+	data, _ = io.ReadAll(reader)
+	_ = reader.Close()
+	if string(data) != "streamed" || size != int64(len(data)) {
+		t.Errorf("Download() = %q of %d bytes, want %q", data, size, "streamed")
+	}
+
+	if err = r.DeleteFile(ctx, &model.GetPars{ID: "1.100"}); err != nil {
+		t.Fatalf("DeleteFile() error = %v", err)
+	}
+	if err = r.DeleteFile(ctx, &model.GetPars{ID: "1.100"}); err != nil {
+		t.Errorf("DeleteFile() of a missing file error = %v", err)
+	}
+	if _, found, err = r.GetFile(ctx, &model.GetPars{ID: "1.100"}); err != nil || found {
+		t.Errorf("GetFile() found = %v, error = %v, want a missing file", found, err)
+	}
+
+	entries, _ := os.ReadDir(r.Dir)
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want no temporary files left", len(entries))
+	}
+}
+
+func TestFSRepo_InvalidKeys(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := NewFSRepo(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFSRepo() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "empty", key: ""},
+		{name: "parent directory", key: ".."},
+		{name: "path traversal", key: "../outside"},
+		{name: "nested path", key: "a/b"},
+		{name: "hidden file", key: ".upload-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := r.UploadFile(ctx, tt.key, []byte("binary")); !errors.Is(err, errs.InvalidInput) {
+				t.Errorf("UploadFile() error = %v, want %v", err, errs.InvalidInput)
+			}
+			if _, _, err := r.GetFile(ctx, &model.GetPars{ID: tt.key}); !errors.Is(err, errs.InvalidInput) {
+				t.Errorf("GetFile() error = %v, want %v", err, errs.InvalidInput)
+			}
+			if err := r.DeleteFile(ctx, &model.GetPars{ID: tt.key}); !errors.Is(err, errs.InvalidInput) {
+				t.Errorf("DeleteFile() error = %v, want %v", err, errs.InvalidInput)
+			}
+		})
+	}
+}
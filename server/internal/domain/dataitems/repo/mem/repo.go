@@ -0,0 +1,93 @@
+// Package mem provides an in-memory blob storage of the binary data, used in tests
+// and for running the server without any persistent storage of the files.
+package mem
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
+	"io"
+	"sync"
+)
+
+// MemRepo keeps the binary data in memory by the object keys. It is safe for concurrent use.
+type MemRepo struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemRepo initializes a new empty MemRepo instance.
+func NewMemRepo() *MemRepo {
+	return &MemRepo{files: make(map[string][]byte)}
+}
+
+// GetFile retrieves a copy of the file stored under the object key held by pars.ID. It returns the file
+// as a byte slice, a boolean indicating if the file exists, and any error encountered.
+func (r *MemRepo) GetFile(_ context.Context, pars *model.GetPars) ([]byte, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, ok := r.files[pars.ID]
+	if !ok {
+		return nil, false, nil
+	}
+
+	return bytes.Clone(data), true, nil
+}
+
+// UploadFile stores a copy of the data under the given object key, returning the URL of the stored file.
+func (r *MemRepo) UploadFile(_ context.Context, objectKey string, data []byte) (string, error) {
+	if objectKey == "" {
+		return "", errs.InvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.files[objectKey] = bytes.Clone(data)
+
+	return "mem:" + objectKey, nil
+}
+
+// DeleteFile removes the file stored under the object key held by pars.ID.
+// Removing a missing file is not an error.
+func (r *MemRepo) DeleteFile(_ context.Context, pars *model.GetPars) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.files, pars.ID)
+
+	return nil
+}
+
+// Upload reads the whole content of the reader and stores it under the given object key,
+// returning the URL of the stored file. The size is not used.
+func (r *MemRepo) Upload(ctx context.Context, objectKey string, reader io.Reader, _ int64) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return r.UploadFile(ctx, objectKey, data)
+}
+
+// Download opens the file stored under the object key for reading, returning the reader, the size
+// of the file, and a boolean indicating if the file exists.
+func (r *MemRepo) Download(ctx context.Context, objectKey string) (io.ReadCloser, int64, bool, error) {
+	data, found, err := r.GetFile(ctx, &model.GetPars{ID: objectKey})
+	if err != nil || !found {
+		return nil, 0, false, err
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), true, nil
+}
+
+// Len returns the number of the stored files.
+func (r *MemRepo) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.files)
+}
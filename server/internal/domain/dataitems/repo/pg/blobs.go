@@ -0,0 +1,127 @@
+package pg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
+	"io"
+)
+
+// BlobRepo stores the binary data inline in the blobs table of PostgreSQL. It is meant
+// for small files only, larger ones are rejected with a validation error.
+type BlobRepo struct {
+	Con     *pgxpool.Pool
+	MaxSize int64
+}
+
+// NewBlobRepo creates a new instance of BlobRepo with the given PostgreSQL connection pool,
+// accepting files of at most maxSize bytes.
+func NewBlobRepo(con *pgxpool.Pool, maxSize int64) *BlobRepo {
+	return &BlobRepo{
+		Con:     con,
+		MaxSize: maxSize,
+	}
+}
+
+// GetFile retrieves the file stored under the object key held by pars.ID. It returns the file
+// as a byte slice, a boolean indicating if the file exists, and any error encountered.
+func (r *BlobRepo) GetFile(ctx context.Context, pars *model.GetPars) ([]byte, bool, error) {
+	sql, args, err := squirrel.Select("data").
+		From("blobs").
+		Where(squirrel.Eq{"object_key": pars.ID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var data []byte
+	err = r.conn(ctx).QueryRow(ctx, sql, args...).Scan(&data)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// UploadFile stores the data under the given object key, returning the URL of the stored file or an error.
+func (r *BlobRepo) UploadFile(ctx context.Context, objectKey string, data []byte) (string, error) {
+	if objectKey == "" {
+		return "", errs.InvalidInput
+	}
+	if int64(len(data)) > r.MaxSize {
+		return "", r.sizeViolation()
+	}
+
+	sql, args, err := squirrel.Insert("blobs").
+		Columns("object_key", "data").
+		Values(objectKey, data).
+		Suffix("ON CONFLICT (object_key) DO UPDATE SET data = EXCLUDED.data").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.conn(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return "", err
+	}
+
+	return "postgres:blobs/" + objectKey, nil
+}
+
+// DeleteFile removes the file stored under the object key held by pars.ID.
+// Removing a missing file is not an error.
+func (r *BlobRepo) DeleteFile(ctx context.Context, pars *model.GetPars) error {
+	sql, args, err := squirrel.Delete("blobs").
+		Where(squirrel.Eq{"object_key": pars.ID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn(ctx).Exec(ctx, sql, args...)
+	return err
+}
+
+// Upload reads the content of the reader and stores it under the given object key, returning
+// the URL of the stored file. At most MaxSize bytes are read, so a larger file is rejected
+// without being buffered in memory. The size is not used.
+func (r *BlobRepo) Upload(ctx context.Context, objectKey string, reader io.Reader, _ int64) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, r.MaxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return r.UploadFile(ctx, objectKey, data)
+}
+
+// Download opens the file stored under the object key for reading, returning the reader, the size
+// of the file, and a boolean indicating if the file exists.
+func (r *BlobRepo) Download(ctx context.Context, objectKey string) (io.ReadCloser, int64, bool, error) {
+	data, found, err := r.GetFile(ctx, &model.GetPars{ID: objectKey})
+	if err != nil || !found {
+		return nil, 0, false, err
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), true, nil
+}
+
+// sizeViolation returns the validation error of a file exceeding MaxSize.
+func (r *BlobRepo) sizeViolation() error {
+	return &errs.ValidationError{Violations: []errs.FieldViolation{{
+		Field:       "content",
+		Description: fmt.Sprintf("must be at most %d bytes long", r.MaxSize),
+	}}}
+}
@@ -0,0 +1,139 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
+)
+
+// uniqueViolationCode is the PostgreSQL error code reported when a unique constraint is violated.
+const uniqueViolationCode = "23505"
+
+// folderColumns lists the columns of a folder in the order they are scanned by scanFolder.
+var folderColumns = []string{"id", "user_id", "COALESCE(parent_id, '')", "name", "created_at"}
+
+// CreateFolder inserts a new folder of the user. It returns errs.FolderAlreadyExists
+// if the parent folder already has a folder with the same name.
+func (r *Repo) CreateFolder(ctx context.Context, folder *model.Folder) error {
+	sql, args, err := squirrel.Insert("folders").
+		Columns("id", "user_id", "parent_id", "name", "created_at").
+		Values(folder.ID, folder.UserID, folderIDValue(folder.ParentID), folder.Name, folder.CreatedAt).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return errs.FolderAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetFolder retrieves the folder of the user. It returns the folder if found,
+// a boolean indicating its existence, and any error encountered.
+func (r *Repo) GetFolder(ctx context.Context, userID, id string) (*model.Folder, bool, error) {
+	if userID == "" || id == "" {
+		return nil, false, errs.InvalidInput
+	}
+
+	sql, args, err := squirrel.Select(folderColumns...).
+		From("folders").
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var folder model.Folder
+	err = scanFolder(r.conn(ctx).QueryRow(ctx, sql, args...), &folder)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &folder, true, nil
+}
+
+// ListFolders retrieves all the folders of the user ordered by name.
+func (r *Repo) ListFolders(ctx context.Context, userID string) ([]*model.Folder, error) {
+	if userID == "" {
+		return nil, errs.InvalidInput
+	}
+
+	sql, args, err := squirrel.Select(folderColumns...).
+		From("folders").
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("name", "id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var result []*model.Folder
+	for rows.Next() {
+		var folder model.Folder
+		if err = scanFolder(rows, &folder); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &folder)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteFolder removes the folder of the user together with its subfolders.
+// The items of the removed folders are kept outside of any folder.
+// It returns a boolean indicating whether the folder was found.
+func (r *Repo) DeleteFolder(ctx context.Context, userID, id string) (bool, error) {
+	if userID == "" || id == "" {
+		return false, errs.InvalidInput
+	}
+
+	sql, args, err := squirrel.Delete("folders").
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.conn(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// scanFolder scans the row selected with folderColumns into the folder.
+func scanFolder(row pgx.Row, folder *model.Folder) error {
+	return row.Scan(&folder.ID, &folder.UserID, &folder.ParentID, &folder.Name, &folder.CreatedAt)
+}
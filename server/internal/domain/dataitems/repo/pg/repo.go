@@ -10,8 +10,34 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"gophKeeper/server/internal/domain/dataitems/model"
 	"gophKeeper/server/internal/errs"
+	"strings"
+	"time"
 )
 
+// itemColumns lists the columns of an item in the order they are scanned, shared by the items
+// and their versions, so a version is a copy of the item row.
+var itemColumns = []string{"id", "user_id", "type", "data", "COALESCE(meta, '')", "url", "version", "object_key", "size", "name", "COALESCE(folder_id, '')", "created_at", "updated_at"}
+
+// versionColumns lists the columns of the versions table matching itemColumns.
+var versionColumns = []string{"item_id", "user_id", "type", "data", "meta", "url", "version", "object_key", "size", "name", "folder_id", "created_at", "updated_at"}
+
+// selectColumns lists the columns selected for an item, which are scanned by scanItem.
+// The tags and labels are kept in their own tables and are aggregated into the item row.
+var selectColumns = append(itemColumns[:len(itemColumns):len(itemColumns)], "deleted_at", tagsColumn, labelsColumn)
+
+const (
+	tagsColumn   = "ARRAY(SELECT tag FROM data_item_tags WHERE item_id = data_items.id ORDER BY tag)"
+	labelsColumn = "COALESCE((SELECT jsonb_object_agg(key, value) FROM data_item_labels WHERE item_id = data_items.id), '{}'::jsonb)"
+
+	// searchDocument is the text the search query is matched against: the name, the tags and the labels of the item.
+	searchDocument = "to_tsvector('simple', name || ' ' || " +
+		"COALESCE((SELECT string_agg(tag, ' ') FROM data_item_tags WHERE item_id = data_items.id), '') || ' ' || " +
+		"COALESCE((SELECT string_agg(key || ' ' || value, ' ') FROM data_item_labels WHERE item_id = data_items.id), ''))"
+)
+
+// summaryColumns lists the columns selected for an item without its content.
+var summaryColumns = withoutData(selectColumns)
+
 // Repo provides methods to interact with the PostgreSQL database for data item operations.
 // It holds a connection pool to manage database connections.
 type Repo struct {
@@ -34,7 +60,7 @@ func (r *Repo) Get(ctx context.Context, pars *model.GetPars) (*model.DataItems,
 
 	var result model.DataItems
 
-	queryBuilder := squirrel.Select("*").From("data_items")
+	queryBuilder := squirrel.Select(selectColumns...).From("data_items")
 
 	if len(pars.ID) != 0 {
 		queryBuilder = queryBuilder.Where(squirrel.Eq{"id": pars.ID})
@@ -48,14 +74,30 @@ func (r *Repo) Get(ctx context.Context, pars *model.GetPars) (*model.DataItems,
 		queryBuilder = queryBuilder.Where(squirrel.Eq{"type": pars.Type})
 	}
 
+	if len(pars.Meta) != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"meta": pars.Meta})
+	}
+
+	if len(pars.URL) != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"url": pars.URL})
+	}
+
+	if !pars.WithDeleted {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"deleted_at": nil})
+	}
+
 	queryBuilder = queryBuilder.Limit(1)
 
+	if pars.ForUpdate {
+		queryBuilder = queryBuilder.Suffix("FOR UPDATE")
+	}
+
 	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
 	if err != nil {
 		return nil, false, err
 	}
 
-	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.ID, &result.UserID, &result.Type, &result.Data, &result.Meta, &result.URL, &result.CreatedAt, &result.UpdatedAt)
+	err = scanItem(r.conn(ctx).QueryRow(ctx, sql, args...), &result)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, false, nil
@@ -67,11 +109,18 @@ func (r *Repo) Get(ctx context.Context, pars *model.GetPars) (*model.DataItems,
 }
 
 // List retrieves multiple data items based on the provided query parameters,
-// supporting filters like ID, user ID, type, and timestamps. It returns the list
-// of items, the total count, and any error encountered.
+// supporting filters like ID, user ID, type, and timestamps. Sorted lists are paginated
+// with the keyset of the sort field and the item ID, so pages are fetched by an index
+// range scan instead of skipping the previous pages. It returns the list
+// of items, the count of the returned items, and any error encountered.
 func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.DataItems, int64, error) {
+	columns := selectColumns
+	if pars.WithoutData {
+		columns = summaryColumns
+	}
+
 	queryBuilder := squirrel.
-		Select("id", "user_id", "type", "data", "created_at", "updated_at").
+		Select(columns...).
 		From("data_items")
 
 	if pars.ID != nil {
@@ -94,6 +143,14 @@ func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.DataIte
 		queryBuilder = queryBuilder.Where(squirrel.Eq{"type": pars.Type})
 	}
 
+	if pars.Meta != nil {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"meta": pars.Meta})
+	}
+
+	if pars.URL != nil {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"url": pars.URL})
+	}
+
 	if pars.CreatedBefore != nil {
 		queryBuilder = queryBuilder.Where(squirrel.LtOrEq{"created_at": pars.CreatedBefore})
 	}
@@ -110,12 +167,52 @@ func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.DataIte
 		queryBuilder = queryBuilder.Where(squirrel.GtOrEq{"updated_at": pars.UpdatedAfter})
 	}
 
+	if pars.DeletedBefore != nil {
+		queryBuilder = queryBuilder.Where(squirrel.LtOrEq{"deleted_at": pars.DeletedBefore})
+	}
+
+	switch {
+	case pars.OnlyDeleted || pars.DeletedBefore != nil:
+		queryBuilder = queryBuilder.Where(squirrel.NotEq{"deleted_at": nil})
+	case !pars.WithDeleted:
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"deleted_at": nil})
+	}
+
+	if pars.Sort != "" {
+		if !pars.Sort.IsValid() {
+			return nil, 0, errs.InvalidInput
+		}
+
+		column := string(pars.Sort)
+		direction, comparison := "ASC", ">"
+		if pars.Descending {
+			direction, comparison = "DESC", "<"
+		}
+
+		if pars.After != nil {
+			if pars.Sort == model.SortByID {
+				queryBuilder = queryBuilder.Where(squirrel.Expr("id "+comparison+" ?", pars.After.ID))
+			} else {
+				queryBuilder = queryBuilder.Where(squirrel.Expr("("+column+", id) "+comparison+" (?, ?)", pars.After.Time, pars.After.ID))
+			}
+		}
+
+		queryBuilder = queryBuilder.OrderBy(column + " " + direction)
+		if pars.Sort != model.SortByID {
+			queryBuilder = queryBuilder.OrderBy("id " + direction)
+		}
+	}
+
+	if pars.Limit > 0 {
+		queryBuilder = queryBuilder.Limit(pars.Limit)
+	}
+
 	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
 	if err != nil {
 		return nil, 0, err
 	}
 
-	rows, err := r.Con.Query(ctx, sql, args...)
+	rows, err := r.conn(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -125,7 +222,7 @@ func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.DataIte
 	var result []*model.DataItems
 	for rows.Next() {
 		var data model.DataItems
-		err = rows.Scan(&data.ID, &data.UserID, &data.Type, &data.Data, &data.CreatedAt, &data.UpdatedAt)
+		err = scanItem(rows, &data)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -140,29 +237,81 @@ func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.DataIte
 }
 
 // Create inserts a new data item into the database based on the provided Edit object,
-// returning the ID of the newly created item and any error encountered.
-func (r *Repo) Create(ctx context.Context, obj *model.Edit) error {
+// keeping it as the first version of the item, and returns any error encountered.
+// Timestamps are taken from the Edit object when set, so synchronized items keep the client's values.
+// The tags and labels are stored in the same transaction.
+func (r *Repo) Create(ctx context.Context, obj *model.Edit) (err error) {
+	columns := []string{"id", "user_id", "type", "data", "meta"}
+	values := []interface{}{obj.ID, obj.UserID, obj.Type, obj.Data, obj.Meta}
+
+	if obj.URL != nil {
+		columns = append(columns, "url")
+		values = append(values, obj.URL)
+	}
+
+	if obj.ObjectKey != nil {
+		columns = append(columns, "object_key")
+		values = append(values, obj.ObjectKey)
+	}
+
+	if obj.Size != nil {
+		columns = append(columns, "size")
+		values = append(values, obj.Size)
+	}
+
+	if obj.Name != nil {
+		columns = append(columns, "name")
+		values = append(values, obj.Name)
+	}
+
+	if obj.FolderID != nil {
+		columns = append(columns, "folder_id")
+		values = append(values, folderIDValue(*obj.FolderID))
+	}
+
+	if obj.CreatedAt != nil {
+		columns = append(columns, "created_at")
+		values = append(values, obj.CreatedAt)
+	}
+
+	if obj.UpdatedAt != nil {
+		columns = append(columns, "updated_at")
+		values = append(values, obj.UpdatedAt)
+	}
+
 	insert := squirrel.Insert("data_items").
-		Columns("id", "user_id", "type", "data", "meta").
-		Values(obj.ID, obj.UserID, obj.Type, obj.Data, obj.Meta).
-		PlaceholderFormat(squirrel.Dollar)
+		Columns(columns...).
+		Values(values...).
+		Suffix("RETURNING " + strings.Join(itemColumns, ", "))
 
-	query, args, err := insert.ToSql()
+	query, args, err := withVersion(insert).ToSql()
 	if err != nil {
 		return err
 	}
 
-	_, err = r.Con.Exec(ctx, query, args...)
+	tx, err := r.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
-	return nil
+	defer r.HandleTxCompletion(tx, &err)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if err = setTagsAndLabels(ctx, tx, obj.ID, obj); err != nil {
+		return err
+	}
+
+	return versionTagsAndLabels(ctx, tx, &model.GetPars{ID: obj.ID})
 }
 
 // Update modifies an existing data item based on the provided query parameters and Edit object,
-// returning any error encountered during the operation.
-func (r *Repo) Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) error {
-	if !pars.IsValid() {
+// keeping the new state as the next version of the item, and returns any error encountered.
+// The tags and labels are replaced in the same transaction, which requires the item ID.
+func (r *Repo) Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) (err error) {
+	if !pars.IsValid() || ((obj.Tags != nil || obj.Labels != nil) && pars.ID == "") {
 		return errs.InvalidInput
 	}
 
@@ -192,6 +341,24 @@ func (r *Repo) Update(ctx context.Context, pars *model.GetPars, obj *model.Edit)
 		queryBuilder = queryBuilder.Set("url", obj.URL)
 	}
 
+	if obj.ObjectKey != nil {
+		queryBuilder = queryBuilder.Set("object_key", obj.ObjectKey)
+	}
+
+	if obj.Size != nil {
+		queryBuilder = queryBuilder.Set("size", obj.Size)
+	}
+
+	if obj.Name != nil {
+		queryBuilder = queryBuilder.Set("name", obj.Name)
+	}
+
+	if obj.FolderID != nil {
+		queryBuilder = queryBuilder.Set("folder_id", folderIDValue(*obj.FolderID))
+	}
+
+	queryBuilder = queryBuilder.Set("version", squirrel.Expr("version + 1"))
+
 	if len(pars.ID) > 0 {
 		queryBuilder = queryBuilder.Where(squirrel.Eq{"id": pars.ID})
 	}
@@ -200,17 +367,38 @@ func (r *Repo) Update(ctx context.Context, pars *model.GetPars, obj *model.Edit)
 		queryBuilder = queryBuilder.Where(squirrel.Eq{"user_id": pars.UserID})
 	}
 
-	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if !pars.WithDeleted {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"deleted_at": nil})
+	}
+
+	queryBuilder = queryBuilder.Suffix("RETURNING " + strings.Join(itemColumns, ", "))
+
+	sql, args, err := withVersion(queryBuilder).ToSql()
 	if err != nil {
 		return err
 	}
 
-	_, err = r.Con.Exec(ctx, sql, args...)
-	return err
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.HandleTxCompletion(tx, &err)
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil || tag.RowsAffected() == 0 {
+		// the tags of an item that was not updated must not be touched
+		return err
+	}
+
+	if err = setTagsAndLabels(ctx, tx, pars.ID, obj); err != nil {
+		return err
+	}
+
+	return versionTagsAndLabels(ctx, tx, pars)
 }
 
-// Delete removes a data item from the database based on the provided query parameters,
-// returning any error encountered during the operation.
+// Delete permanently removes a data item with its versions from the database based on the provided
+// query parameters, returning any error encountered during the operation.
 func (r *Repo) Delete(ctx context.Context, pars *model.GetPars) error {
 	if !pars.IsValid() {
 		return errs.InvalidInput
@@ -218,18 +406,339 @@ func (r *Repo) Delete(ctx context.Context, pars *model.GetPars) error {
 
 	queryBuilder := squirrel.Delete("data_items")
 
-	queryBuilder = queryBuilder.Where(squirrel.Eq{"id": pars.ID})
+	if len(pars.ID) > 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"id": pars.ID})
+	}
+
+	if len(pars.UserID) > 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"user_id": pars.UserID})
+	}
+
+	if !pars.WithDeleted {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"deleted_at": nil})
+	}
+
+	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn(ctx).Exec(ctx, sql, args...)
+	return err
+}
+
+// SetDeleted moves the data item to the trash or, if deletedAt is nil, restores it from the trash.
+// The change does not create a new version, but bumps updated_at, so clients learn about it on sync.
+// It returns a boolean indicating whether the item was found in the expected state.
+func (r *Repo) SetDeleted(ctx context.Context, pars *model.GetPars, deletedAt *time.Time, updatedAt time.Time) (bool, error) {
+	if pars.ID == "" || pars.UserID == "" {
+		return false, errs.InvalidInput
+	}
+
+	queryBuilder := squirrel.Update("data_items").
+		Set("deleted_at", deletedAt).
+		Set("updated_at", updatedAt).
+		Where(squirrel.Eq{"id": pars.ID}).
+		Where(squirrel.Eq{"user_id": pars.UserID})
+
+	if deletedAt != nil {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"deleted_at": nil})
+	} else {
+		queryBuilder = queryBuilder.Where(squirrel.NotEq{"deleted_at": nil})
+	}
+
+	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.conn(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListVersions retrieves the versions of the item of the user, newest first.
+// The data of the versions is not loaded.
+func (r *Repo) ListVersions(ctx context.Context, pars *model.VersionPars) ([]*model.DataItems, error) {
+	if !pars.IsValid() {
+		return nil, errs.InvalidInput
+	}
+
+	queryBuilder := squirrel.
+		Select("item_id", "user_id", "type", "''::bytea", "COALESCE(meta, '')", "url", "version", "object_key", "size", "name", "folder_id", "created_at", "updated_at", "NULL::timestamptz", "tags", "labels").
+		From("data_item_versions").
+		Where(squirrel.Eq{"item_id": pars.ItemID}).
+		Where(squirrel.Eq{"user_id": pars.UserID}).
+		OrderBy("version DESC")
+
+	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var result []*model.DataItems
+	for rows.Next() {
+		var data model.DataItems
+		err = scanItem(rows, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &data)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetVersion retrieves a single version of the item of the user.
+// It returns the version if found, a boolean indicating its existence, and any error encountered.
+func (r *Repo) GetVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, bool, error) {
+	if !pars.IsValid() || pars.Version <= 0 {
+		return nil, false, errs.InvalidInput
+	}
+
+	var result model.DataItems
+
+	queryBuilder := squirrel.
+		Select("item_id", "user_id", "type", "data", "COALESCE(meta, '')", "url", "version", "object_key", "size", "name", "folder_id", "created_at", "updated_at", "NULL::timestamptz", "tags", "labels").
+		From("data_item_versions").
+		Where(squirrel.Eq{"item_id": pars.ItemID}).
+		Where(squirrel.Eq{"user_id": pars.UserID}).
+		Where(squirrel.Eq{"version": pars.Version})
+
+	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = scanItem(r.conn(ctx).QueryRow(ctx, sql, args...), &result)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &result, true, nil
+}
+
+// Search retrieves the items of the user matching the structured metadata filters,
+// most recently updated first. The data of the items is not loaded.
+func (r *Repo) Search(ctx context.Context, pars *model.SearchPars) ([]*model.DataItems, error) {
+	if pars.UserID == "" {
+		return nil, errs.InvalidInput
+	}
+
+	queryBuilder := squirrel.
+		Select(summaryColumns...).
+		From("data_items").
+		Where(squirrel.Eq{"user_id": pars.UserID}).
+		Where(squirrel.Eq{"deleted_at": nil})
+
+	if pars.Type != "" {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"type": pars.Type})
+	}
+
+	if len(pars.Tags) > 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Expr(
+			"id IN (SELECT item_id FROM data_item_tags WHERE tag = ANY(?) GROUP BY item_id HAVING count(*) = ?)",
+			pars.Tags, len(pars.Tags),
+		))
+	}
+
+	for key, value := range pars.Labels {
+		queryBuilder = queryBuilder.Where(squirrel.Expr(
+			"EXISTS (SELECT 1 FROM data_item_labels WHERE item_id = data_items.id AND key = ? AND value = ?)",
+			key, value,
+		))
+	}
+
+	if pars.FolderID != "" {
+		if pars.Recursive {
+			queryBuilder = queryBuilder.Where(squirrel.Expr(
+				"folder_id IN (WITH RECURSIVE subfolders AS ("+
+					"SELECT id FROM folders WHERE id = ? AND user_id = ? "+
+					"UNION ALL SELECT f.id FROM folders f JOIN subfolders s ON f.parent_id = s.id"+
+					") SELECT id FROM subfolders)",
+				pars.FolderID, pars.UserID,
+			))
+		} else {
+			queryBuilder = queryBuilder.Where(squirrel.Eq{"folder_id": pars.FolderID})
+		}
+	}
+
+	if terms := model.SearchTerms(pars.Query); len(terms) > 0 {
+		// every term matches the words it is a prefix of
+		query := strings.Join(terms, ":* & ") + ":*"
+		queryBuilder = queryBuilder.Where(squirrel.Expr(searchDocument+" @@ to_tsquery('simple', ?)", query))
+	}
+
+	queryBuilder = queryBuilder.OrderBy("updated_at DESC", "id DESC")
+
+	if pars.Limit > 0 {
+		queryBuilder = queryBuilder.Limit(pars.Limit)
+	}
+
+	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var result []*model.DataItems
+	for rows.Next() {
+		var data model.DataItems
+		err = scanItem(rows, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &data)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// setTagsAndLabels replaces the tags and the labels of the item with the ones set in the Edit object
+// within the transaction. Tags or labels that are not set are kept.
+func setTagsAndLabels(ctx context.Context, tx pgx.Tx, itemID string, obj *model.Edit) error {
+	if obj.Tags != nil {
+		if _, err := tx.Exec(ctx, "DELETE FROM data_item_tags WHERE item_id = $1", itemID); err != nil {
+			return err
+		}
+
+		if len(*obj.Tags) > 0 {
+			insert := squirrel.Insert("data_item_tags").Columns("item_id", "tag")
+			for _, tag := range *obj.Tags {
+				insert = insert.Values(itemID, tag)
+			}
+
+			sql, args, err := insert.PlaceholderFormat(squirrel.Dollar).ToSql()
+			if err != nil {
+				return err
+			}
+			if _, err = tx.Exec(ctx, sql, args...); err != nil {
+				return err
+			}
+		}
+	}
+
+	if obj.Labels != nil {
+		if _, err := tx.Exec(ctx, "DELETE FROM data_item_labels WHERE item_id = $1", itemID); err != nil {
+			return err
+		}
+
+		if len(*obj.Labels) > 0 {
+			insert := squirrel.Insert("data_item_labels").Columns("item_id", "key", "value")
+			for key, value := range *obj.Labels {
+				insert = insert.Values(itemID, key, value)
+			}
+
+			sql, args, err := insert.PlaceholderFormat(squirrel.Dollar).ToSql()
+			if err != nil {
+				return err
+			}
+			if _, err = tx.Exec(ctx, sql, args...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// versionTagsAndLabels copies the current tags and labels of the matching items into their current versions
+// within the transaction, as the tags and labels are kept in their own tables and not in the item row.
+func versionTagsAndLabels(ctx context.Context, tx pgx.Tx, pars *model.GetPars) error {
+	queryBuilder := squirrel.Update("data_item_versions").
+		Set("tags", squirrel.Expr(strings.ReplaceAll(tagsColumn, "data_items.id", "data_item_versions.item_id"))).
+		Set("labels", squirrel.Expr(strings.ReplaceAll(labelsColumn, "data_items.id", "data_item_versions.item_id"))).
+		From("data_items").
+		Where("data_items.id = data_item_versions.item_id").
+		Where("data_items.version = data_item_versions.version")
+
+	if len(pars.ID) > 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"data_items.id": pars.ID})
+	}
+
+	if len(pars.UserID) > 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"data_items.user_id": pars.UserID})
+	}
 
 	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
 	if err != nil {
 		return err
 	}
 
-	_, err = r.Con.Exec(ctx, sql, args...)
+	_, err = tx.Exec(ctx, sql, args...)
 	return err
 }
 
+// folderIDValue returns the value stored in the folder_id column, items outside of any folder keep NULL.
+func folderIDValue(folderID string) interface{} {
+	if folderID == "" {
+		return nil
+	}
+	return folderID
+}
+
+// withVersion wraps the statement returning the item row, so the returned row is inserted
+// into the versions table in the same statement.
+func withVersion(statement squirrel.Sqlizer) squirrel.InsertBuilder {
+	return squirrel.Insert("data_item_versions").
+		Columns(versionColumns...).
+		Select(squirrel.Select("*").From("changed")).
+		PrefixExpr(squirrel.Expr("WITH changed AS (?)", statement)).
+		PlaceholderFormat(squirrel.Dollar)
+}
+
+// withoutData returns the columns with the content column replaced by an empty value,
+// so the row is scanned the same way without transferring the content.
+func withoutData(columns []string) []string {
+	result := make([]string, len(columns))
+	for i, column := range columns {
+		if column == "data" {
+			column = "''::bytea"
+		}
+		result[i] = column
+	}
+	return result
+}
+
+// scanItem scans the row selected with selectColumns into the data item.
+func scanItem(row pgx.Row, data *model.DataItems) error {
+	return row.Scan(&data.ID, &data.UserID, &data.Type, &data.Data, &data.Meta, &data.URL, &data.Version, &data.ObjectKey, &data.Size,
+		&data.Name, &data.FolderID, &data.CreatedAt, &data.UpdatedAt, &data.DeletedAt, &data.Tags, &data.Labels)
+}
+
+// BeginTx starts a transaction, a nested one within the transaction of the context, if there is one.
 func (r *Repo) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	if tx, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		return tx.Begin(ctx)
+	}
+
 	tx, err := r.Con.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return nil, err
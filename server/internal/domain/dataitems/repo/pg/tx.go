@@ -0,0 +1,50 @@
+package pg
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// querier is the part of the connection pool and of a transaction the queries are run with.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txCtxKey struct{}
+
+// InTx runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise.
+// The methods of the repository called with the context passed to fn take part in the transaction,
+// the ones running a transaction of their own run it as a nested one.
+func (r *Repo) InTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.HandleTxCompletion(tx, &err)
+
+	return fn(context.WithValue(ctx, txCtxKey{}, tx))
+}
+
+// conn returns the transaction of the context if there is one, the connection pool otherwise.
+func (r *Repo) conn(ctx context.Context) querier {
+	return ctxConn(ctx, r.Con)
+}
+
+// conn returns the transaction of the context if there is one, the connection pool otherwise,
+// so the blobs stored inline are written and removed along with the items in InTx.
+func (r *BlobRepo) conn(ctx context.Context) querier {
+	return ctxConn(ctx, r.Con)
+}
+
+// ctxConn returns the transaction of the context if there is one, the pool otherwise.
+func ctxConn(ctx context.Context, pool *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return pool
+}
@@ -9,9 +9,17 @@ import (
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
 	"io"
-	"log"
-	"path/filepath"
+	"path"
+	"strings"
+)
+
+const (
+	// streamPartSize limits the memory used for each part of a multipart upload of unknown size.
+	streamPartSize = 16 << 20
+	// uploadsPrefix is the prefix of the names of all the objects stored by the repository.
+	uploadsPrefix = "uploads/"
 )
 
 // S3Repo manages interactions with the S3 storage, including file operations
@@ -25,7 +33,8 @@ type S3Repo struct {
 }
 
 // NewS3Repo initializes a new S3Repo instance, setting up the S3 client and bucket.
-// It returns an error if the client creation or bucket setup fails.
+// The bucket is created unless it already exists. It returns an error if the client creation
+// or bucket setup fails.
 func NewS3Repo(ctx context.Context, S3Endpoint, S3AccessKey, S3SecretKey, S3Bucket string) (*S3Repo, error) {
 	client, err := minio.New(S3Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(S3AccessKey, S3SecretKey, ""),
@@ -35,16 +44,15 @@ func NewS3Repo(ctx context.Context, S3Endpoint, S3AccessKey, S3SecretKey, S3Buck
 		return nil, fmt.Errorf("failed to create MinIO client: %v", err)
 	}
 
-	err = client.MakeBucket(ctx, S3Bucket, minio.MakeBucketOptions{Region: "us-east-1"})
+	exists, err := client.BucketExists(ctx, S3Bucket)
 	if err != nil {
-		exists, errBucketExists := client.BucketExists(ctx, S3Bucket)
-		if errBucketExists == nil && exists {
-			log.Printf("Bucket %s already exists\n", S3Bucket)
-		} else {
-			log.Fatalf("failed to create bucket: %v", err)
+		return nil, fmt.Errorf("failed to check bucket %s: %w", S3Bucket, err)
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, S3Bucket, minio.MakeBucketOptions{Region: "us-east-1"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", S3Bucket, err)
 		}
-	} else {
-		log.Printf("Successfully created bucket %s\n", S3Bucket)
 	}
 
 	return &S3Repo{
@@ -54,13 +62,16 @@ func NewS3Repo(ctx context.Context, S3Endpoint, S3AccessKey, S3SecretKey, S3Buck
 		S3SecretKey: S3SecretKey,
 		S3Bucket:    S3Bucket,
 	}, nil
-
 }
 
-// GetFile retrieves a file from the S3 bucket based on the provided parameters.
+// GetFile retrieves a file from the S3 bucket based on the provided parameters, where pars.ID holds the object key.
 // It returns the file as a byte slice, a boolean indicating if the file exists, and any error encountered.
 func (r *S3Repo) GetFile(ctx context.Context, pars *model.GetPars) ([]byte, bool, error) {
-	objectName := filepath.Join("uploads", pars.ID)
+	objectName, err := uploadName(pars.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
 	object, err := r.client.GetObject(ctx, r.S3Bucket, objectName, minio.GetObjectOptions{})
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to get object: %v", err)
@@ -70,16 +81,21 @@ func (r *S3Repo) GetFile(ctx context.Context, pars *model.GetPars) ([]byte, bool
 	buffer := new(bytes.Buffer)
 	_, err = io.Copy(buffer, object)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, false, fmt.Errorf("failed to read object: %v", err)
 	}
 
 	return buffer.Bytes(), true, nil
 }
 
-// UploadFile uploads a file to the S3 bucket, returning the URL of the uploaded file or an error.
-func (r *S3Repo) UploadFile(ctx context.Context, id string, data []byte) (string, error) {
-	objectName := filepath.Join("uploads", id)
-	_, err := r.client.PutObject(ctx, r.S3Bucket, objectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{})
+// UploadFile uploads a file to the S3 bucket under the given object key,
+// returning the URL of the uploaded file or an error.
+func (r *S3Repo) UploadFile(ctx context.Context, objectKey string, data []byte) (string, error) {
+	objectName, err := uploadName(objectKey)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.client.PutObject(ctx, r.S3Bucket, objectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload file to MinIO: %v", err)
 	}
@@ -88,13 +104,75 @@ func (r *S3Repo) UploadFile(ctx context.Context, id string, data []byte) (string
 	return url, nil
 }
 
-// DeleteFile removes a file from the S3 bucket based on the provided parameters.
+// DeleteFile removes a file from the S3 bucket based on the provided parameters, where pars.ID holds the object key.
 // It returns an error if the deletion fails.
 func (r *S3Repo) DeleteFile(ctx context.Context, pars *model.GetPars) error {
-	objectName := filepath.Join("uploads", pars.ID)
-	err := r.client.RemoveObject(ctx, r.S3Bucket, objectName, minio.RemoveObjectOptions{})
+	objectName, err := uploadName(pars.ID)
+	if err != nil {
+		return err
+	}
+
+	err = r.client.RemoveObject(ctx, r.S3Bucket, objectName, minio.RemoveObjectOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to delete object from MinIO: %v", err)
 	}
 	return nil
 }
+
+// Upload streams the content of the reader to the S3 bucket under the given object key without buffering
+// the whole file in memory, returning the URL of the uploaded file. A negative size means the size is unknown.
+func (r *S3Repo) Upload(ctx context.Context, objectKey string, reader io.Reader, size int64) (string, error) {
+	objectName, err := uploadName(objectKey)
+	if err != nil {
+		return "", err
+	}
+
+	opts := minio.PutObjectOptions{}
+	if size < 0 {
+		opts.PartSize = streamPartSize
+	}
+
+	_, err = r.client.PutObject(ctx, r.S3Bucket, objectName, reader, size, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file to MinIO: %v", err)
+	}
+	url := fmt.Sprintf("http://%s/%s/%s", r.client.EndpointURL().Host, r.S3Bucket, objectName)
+
+	return url, nil
+}
+
+// Download opens the file in the S3 bucket for reading, returning the reader, the size of the file,
+// and a boolean indicating if the file exists. The caller must close the reader.
+func (r *S3Repo) Download(ctx context.Context, objectKey string) (io.ReadCloser, int64, bool, error) {
+	objectName, err := uploadName(objectKey)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	object, err := r.client.GetObject(ctx, r.S3Bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to get object: %v", err)
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		_ = object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, 0, false, nil
+		}
+		return nil, 0, false, fmt.Errorf("failed to stat object: %v", err)
+	}
+
+	return object, info.Size, true, nil
+}
+
+// uploadName returns the name of the object stored under the object key. Keys which are not plain
+// names are rejected, so an object name can never point outside of the uploads prefix.
+func uploadName(objectKey string) (string, error) {
+	name := path.Join(uploadsPrefix, objectKey)
+	if objectKey == "" || name != uploadsPrefix+objectKey || strings.Contains(objectKey, "/") {
+		return "", errs.InvalidInput
+	}
+
+	return name, nil
+}
@@ -0,0 +1,34 @@
+package s3
+
+import (
+	"errors"
+	"gophKeeper/server/internal/errs"
+	"testing"
+)
+
+func Test_uploadName(t *testing.T) {
+	tests := []struct {
+		name      string
+		objectKey string
+		want      string
+		wantErr   error
+	}{
+		{name: "plain key", objectKey: "0b4c6f1e-5f0a-4d8e-9d39-0f8e2c6a7b1d.100", want: "uploads/0b4c6f1e-5f0a-4d8e-9d39-0f8e2c6a7b1d.100"},
+		{name: "empty key", objectKey: "", wantErr: errs.InvalidInput},
+		{name: "parent directory", objectKey: "../secret", wantErr: errs.InvalidInput},
+		{name: "nested parent directory", objectKey: "a/../../secret", wantErr: errs.InvalidInput},
+		{name: "subdirectory", objectKey: "a/b", wantErr: errs.InvalidInput},
+		{name: "current directory", objectKey: ".", wantErr: errs.InvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := uploadName(tt.objectKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("uploadName() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("uploadName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Search retrieves the items of the user matching the structured metadata filters, most recently
+// updated first and up to pars.Limit items, model.DefaultPageSize by default. The data of the items
+// is not loaded. Searching in a folder of another user is reported as a missing folder.
+func (s *Service) Search(ctx context.Context, pars *model.SearchPars) ([]*model.DataItems, error) {
+	if pars == nil || pars.UserID == "" {
+		return nil, errs.InvalidInput
+	}
+
+	tags, violations := normalizeTags(pars.Tags)
+	pars.Tags = tags
+
+	switch {
+	case pars.Limit == 0:
+		pars.Limit = model.DefaultPageSize
+	case pars.Limit > model.MaxPageSize:
+		pars.Limit = model.MaxPageSize
+	}
+
+	if len(violations) > 0 {
+		return nil, &errs.ValidationError{Violations: violations}
+	}
+
+	if pars.FolderID != "" {
+		if err := s.checkFolder(ctx, pars.UserID, pars.FolderID); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := s.repoDB.Search(ctx, pars)
+	if err != nil {
+		return nil, fmt.Errorf("search data in PostgreSQL - %w", err)
+	}
+
+	return items, nil
+}
+
+// CreateFolder creates a new folder of the user inside the parent folder, or a top-level one
+// if the parent is not set, and returns it.
+func (s *Service) CreateFolder(ctx context.Context, userID, parentID, name string) (*model.Folder, error) {
+	if userID == "" {
+		return nil, errs.InvalidInput
+	}
+
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return nil, newViolation("name", "must not be empty")
+	case utf8.RuneCountInString(name) > model.MaxFolderNameLength:
+		return nil, newViolation("name", fmt.Sprintf("must be at most %d characters long", model.MaxFolderNameLength))
+	case strings.Contains(name, "/"):
+		return nil, newViolation("name", "must not contain '/'")
+	}
+
+	if parentID != "" {
+		if err := s.checkFolder(ctx, userID, parentID); err != nil {
+			return nil, err
+		}
+	}
+
+	folder := &model.Folder{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		ParentID:  parentID,
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repoDB.CreateFolder(ctx, folder); err != nil {
+		return nil, fmt.Errorf("create folder in PostgreSQL - %w", err)
+	}
+
+	return folder, nil
+}
+
+// ListFolders retrieves all the folders of the user.
+func (s *Service) ListFolders(ctx context.Context, userID string) ([]*model.Folder, error) {
+	if userID == "" {
+		return nil, errs.InvalidInput
+	}
+
+	folders, err := s.repoDB.ListFolders(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list folders from PostgreSQL - %w", err)
+	}
+
+	return folders, nil
+}
+
+// DeleteFolder removes the folder of the user together with its subfolders. The items
+// of the removed folders are kept outside of any folder.
+func (s *Service) DeleteFolder(ctx context.Context, userID, id string) error {
+	if userID == "" || id == "" {
+		return errs.InvalidInput
+	}
+
+	found, err := s.repoDB.DeleteFolder(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("delete folder from PostgreSQL - %w", err)
+	}
+	if !found {
+		return errs.FolderNotFound
+	}
+
+	return nil
+}
+
+// prepareMetadata validates the structured metadata set in the Edit object and normalizes the tags,
+// so they are stored trimmed, lower-cased, sorted and without duplicates. The folder the item
+// is moved to has to belong to the user.
+func (s *Service) prepareMetadata(ctx context.Context, userID string, obj *model.Edit) error {
+	var violations []errs.FieldViolation
+
+	if obj.Name != nil {
+		name := strings.TrimSpace(*obj.Name)
+		if utf8.RuneCountInString(name) > model.MaxNameLength {
+			violations = append(violations, errs.FieldViolation{
+				Field:       "name",
+				Description: fmt.Sprintf("must be at most %d characters long", model.MaxNameLength),
+			})
+		}
+		obj.Name = &name
+	}
+
+	if obj.Tags != nil {
+		tags, tagViolations := normalizeTags(*obj.Tags)
+		violations = append(violations, tagViolations...)
+		obj.Tags = &tags
+	}
+
+	if obj.Labels != nil {
+		violations = append(violations, labelViolations(*obj.Labels)...)
+	}
+
+	if len(violations) > 0 {
+		return &errs.ValidationError{Violations: violations}
+	}
+
+	if obj.FolderID != nil && *obj.FolderID != "" {
+		return s.checkFolder(ctx, userID, *obj.FolderID)
+	}
+
+	return nil
+}
+
+// checkFolder returns errs.FolderNotFound unless the folder belongs to the user.
+func (s *Service) checkFolder(ctx context.Context, userID, folderID string) error {
+	_, found, err := s.repoDB.GetFolder(ctx, userID, folderID)
+	if err != nil {
+		return fmt.Errorf("get folder from PostgreSQL - %w", err)
+	}
+	if !found {
+		return errs.FolderNotFound
+	}
+
+	return nil
+}
+
+// normalizeTags returns the tags trimmed, lower-cased, sorted and without duplicates,
+// along with the violations of the tag limits.
+func normalizeTags(tags []string) ([]string, []errs.FieldViolation) {
+	var violations []errs.FieldViolation
+
+	seen := make(map[string]struct{}, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+
+		switch {
+		case tag == "":
+			violations = append(violations, errs.FieldViolation{Field: "tags", Description: "must not be empty"})
+			continue
+		case utf8.RuneCountInString(tag) > model.MaxTagLength:
+			violations = append(violations, errs.FieldViolation{
+				Field:       "tags",
+				Description: fmt.Sprintf("%q must be at most %d characters long", tag, model.MaxTagLength),
+			})
+			continue
+		}
+
+		result = append(result, tag)
+	}
+
+	if len(result) > model.MaxTags {
+		violations = append(violations, errs.FieldViolation{
+			Field:       "tags",
+			Description: fmt.Sprintf("must have at most %d tags", model.MaxTags),
+		})
+	}
+
+	sort.Strings(result)
+
+	return result, violations
+}
+
+// labelViolations returns the violations of the label limits.
+func labelViolations(labels map[string]string) []errs.FieldViolation {
+	var violations []errs.FieldViolation
+
+	if len(labels) > model.MaxLabels {
+		violations = append(violations, errs.FieldViolation{
+			Field:       "labels",
+			Description: fmt.Sprintf("must have at most %d labels", model.MaxLabels),
+		})
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch {
+		case strings.TrimSpace(key) == "":
+			violations = append(violations, errs.FieldViolation{Field: "labels", Description: "keys must not be empty"})
+		case utf8.RuneCountInString(key) > model.MaxLabelKeyLength:
+			violations = append(violations, errs.FieldViolation{
+				Field:       "labels",
+				Description: fmt.Sprintf("key %q must be at most %d characters long", key, model.MaxLabelKeyLength),
+			})
+		case utf8.RuneCountInString(labels[key]) > model.MaxLabelValueLength:
+			violations = append(violations, errs.FieldViolation{
+				Field:       "labels",
+				Description: fmt.Sprintf("value of %q must be at most %d characters long", key, model.MaxLabelValueLength),
+			})
+		}
+	}
+
+	return violations
+}
+
+// newViolation returns the validation error of a single invalid field.
+func newViolation(field, description string) error {
+	return &errs.ValidationError{Violations: []errs.FieldViolation{{Field: field, Description: description}}}
+}
@@ -1,26 +1,30 @@
 // Package service implements the business logic for managing data items,
-// coordinating between the database and S3 storage repositories.
+// coordinating between the database and the blob storage repositories.
 package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
+	"io"
+	"time"
 )
 
 // Service provides methods to manage data items, handling both database operations
-// and S3 file storage interactions based on the type of data being processed.
+// and blob storage interactions based on the type of data being processed.
 type Service struct {
-	repoDB RepoDBI
-	repoS3 RepoS3
+	repoDB   RepoDBI
+	repoBlob RepoBlob
 }
 
-// New creates a new Service instance with the given database and S3 repositories.
-func New(repoDB RepoDBI, repoS3 RepoS3) *Service {
+// New creates a new Service instance with the given database and blob storage repositories.
+func New(repoDB RepoDBI, repoBlob RepoBlob) *Service {
 	return &Service{
-		repoDB: repoDB,
-		repoS3: repoS3,
+		repoDB:   repoDB,
+		repoBlob: repoBlob,
 	}
 }
 
@@ -32,18 +36,29 @@ type RepoDBI interface {
 	Create(ctx context.Context, obj *model.Edit) error
 	Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) error
 	Delete(ctx context.Context, pars *model.GetPars) error
+	SetDeleted(ctx context.Context, pars *model.GetPars, deletedAt *time.Time, updatedAt time.Time) (bool, error)
+	ListVersions(ctx context.Context, pars *model.VersionPars) ([]*model.DataItems, error)
+	GetVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, bool, error)
+	Search(ctx context.Context, pars *model.SearchPars) ([]*model.DataItems, error)
+	CreateFolder(ctx context.Context, folder *model.Folder) error
+	GetFolder(ctx context.Context, userID, id string) (*model.Folder, bool, error)
+	ListFolders(ctx context.Context, userID string) ([]*model.Folder, error)
+	DeleteFolder(ctx context.Context, userID, id string) (bool, error)
 	BeginTx(ctx context.Context) (pgx.Tx, error)
 	CommitTx(ctx context.Context, tx pgx.Tx) error
 	RollbackTx(ctx context.Context, tx pgx.Tx) error
 	HandleTxCompletion(tx pgx.Tx, err *error)
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
-// RepoS3 defines the methods for interacting with an S3-compatible storage,
-// including operations to get, upload, and delete files.
-type RepoS3 interface {
+// RepoBlob defines the methods for interacting with the blob storage of binary data,
+// including operations to get, upload, and delete files, and to stream them.
+type RepoBlob interface {
 	GetFile(ctx context.Context, pars *model.GetPars) ([]byte, bool, error)
-	UploadFile(ctx context.Context, id string, data []byte) (string, error)
+	UploadFile(ctx context.Context, objectKey string, data []byte) (string, error)
 	DeleteFile(ctx context.Context, pars *model.GetPars) error
+	Upload(ctx context.Context, objectKey string, reader io.Reader, size int64) (string, error)
+	Download(ctx context.Context, objectKey string) (io.ReadCloser, int64, bool, error)
 }
 
 // List retrieves data items based on the provided filtering parameters.
@@ -52,46 +67,106 @@ func (s *Service) List(ctx context.Context, pars *model.ListPars) ([]*model.Data
 	return s.repoDB.List(ctx, pars)
 }
 
-// Create stores a new data item in the database and, if the item is of binary type,
-// uploads the binary data to S3 and updates the database with the file's URL.
-func (s *Service) Create(ctx context.Context, obj *model.Edit) error {
-	tx, err := s.repoDB.BeginTx(ctx)
-	if err != nil {
-		return fmt.Errorf("begin transaction - %w", err)
+// ListPage retrieves a page of data items based on the provided filtering parameters. The page
+// starts after the item the page token points to and holds up to pageSize items in the order
+// of pars.Sort, updated_at by default. It returns the items and the token of the next page,
+// which is empty on the last page.
+func (s *Service) ListPage(ctx context.Context, pars *model.ListPars, pageToken string, pageSize int) ([]*model.DataItems, string, error) {
+	var violations []errs.FieldViolation
+
+	if pars.Sort == "" {
+		pars.Sort = model.SortByUpdatedAt
+	}
+	if !pars.Sort.IsValid() {
+		violations = append(violations, errs.FieldViolation{Field: "sort_by", Description: "unsupported sort field"})
+	}
+
+	switch {
+	case pageSize < 0:
+		violations = append(violations, errs.FieldViolation{Field: "page_size", Description: "must not be negative"})
+	case pageSize == 0:
+		pageSize = model.DefaultPageSize
+	case pageSize > model.MaxPageSize:
+		pageSize = model.MaxPageSize
+	}
+
+	if pageToken != "" {
+		cursor, err := model.DecodeCursor(pageToken)
+		if err != nil || cursor.Sort != pars.Sort || cursor.Descending != pars.Descending {
+			violations = append(violations, errs.FieldViolation{Field: "page_token", Description: "does not match the request"})
+		}
+		pars.After = cursor
+	}
+
+	if len(violations) > 0 {
+		return nil, "", &errs.ValidationError{Violations: violations}
 	}
-	defer s.repoDB.HandleTxCompletion(tx, &err)
 
-	err = s.repoDB.Create(ctx, obj)
+	// one more item tells whether there is a next page
+	pars.Limit = uint64(pageSize) + 1
+
+	items, _, err := s.repoDB.List(ctx, pars)
 	if err != nil {
-		return fmt.Errorf("create data in PostgreSQL - %w", err)
+		return nil, "", fmt.Errorf("list data from PostgreSQL - %w", err)
+	}
+
+	if len(items) <= pageSize {
+		return items, "", nil
+	}
+
+	items = items[:pageSize]
+	nextPageToken := model.NewCursor(items[pageSize-1], pars.Sort, pars.Descending).Encode()
+
+	return items, nextPageToken, nil
+}
+
+// Create stores a new data item in the database as its first version. If the item is of binary type,
+// the binary data is uploaded to the blob storage first and the database keeps the file's URL.
+func (s *Service) Create(ctx context.Context, obj *model.Edit) error {
+	if obj.UserID == nil {
+		return errs.InvalidInput
+	}
+	if err := s.prepareMetadata(ctx, *obj.UserID, obj); err != nil {
+		return err
+	}
+
+	if obj.Data != nil {
+		size := int64(len(*obj.Data))
+		obj.Size = &size
 	}
 
 	if *obj.Type == model.BinaryDataType {
-		var url string
-		url, err = s.repoS3.UploadFile(ctx, obj.ID, *obj.Data)
+		objectKey := model.NewObjectKey(obj.ID, time.Now())
+		url, err := s.repoBlob.UploadFile(ctx, objectKey, *obj.Data)
 		if err != nil {
-			return fmt.Errorf("upload file to MinIO - %w", err)
+			return fmt.Errorf("upload file to blob storage - %w", err)
 		}
 
-		err = s.Update(ctx, &model.GetPars{
-			ID: obj.ID,
-		}, &model.Edit{
-			URL: &url,
-		})
-		if err != nil {
-			_ = s.repoS3.DeleteFile(ctx, &model.GetPars{
-				ID: obj.ID,
+		obj.URL = &url
+		obj.ObjectKey = &objectKey
+	}
+
+	err := s.repoDB.Create(ctx, obj)
+	if err != nil {
+		if obj.ObjectKey != nil {
+			_ = s.repoBlob.DeleteFile(ctx, &model.GetPars{
+				ID: *obj.ObjectKey,
 			})
-			return fmt.Errorf("delete file in MinIO - %w", err)
 		}
+		return fmt.Errorf("create data in PostgreSQL - %w", err)
 	}
 
 	return nil
 }
 
-// Get retrieves a data item from the database and, if it is of binary type,
-// fetches the associated file from S3 and returns it as part of the response.
+// Get retrieves a data item of the user from the database and, if it is of binary type,
+// fetches the associated file from the blob storage and returns it as part of the response.
+// Items owned by other users are reported as not found.
 func (s *Service) Get(ctx context.Context, pars *model.GetPars) (*model.DataItems, bool, error) {
+	if pars == nil || pars.UserID == "" {
+		return nil, false, errs.InvalidInput
+	}
+
 	obj, found, err := s.repoDB.Get(ctx, pars)
 	if err != nil {
 		return nil, false, fmt.Errorf("get data from PostgreSQL - %w", err)
@@ -101,9 +176,9 @@ func (s *Service) Get(ctx context.Context, pars *model.GetPars) (*model.DataItem
 	}
 
 	if obj.Type == model.BinaryDataType {
-		file, found, err := s.repoS3.GetFile(ctx, &model.GetPars{ID: obj.ID})
+		file, found, err := s.repoBlob.GetFile(ctx, &model.GetPars{ID: obj.ObjectKey})
 		if err != nil {
-			return nil, false, fmt.Errorf("get data from MinIO - %w", err)
+			return nil, false, fmt.Errorf("get data from blob storage - %w", err)
 		}
 		if !found {
 			return nil, false, nil
@@ -115,45 +190,635 @@ func (s *Service) Get(ctx context.Context, pars *model.GetPars) (*model.DataItem
 	return obj, found, nil
 }
 
-// Update modifies an existing data item in the database. If the item is of binary type
-// and contains updated data, it uploads the new data to S3 and updates the item's URL.
+// Update modifies an existing data item of the user in the database, keeping the previous state
+// as a version. If the item is of binary type and contains updated data, it uploads the new data
+// to the blob storage under a new key and updates the item's URL, so the file of the previous version is kept.
+// The owner of the item is never changed, and items owned by other users are reported as not found.
 func (s *Service) Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) error {
+	if !isOwnedItemPars(pars) {
+		return errs.InvalidInput
+	}
+
 	existingObj, found, err := s.repoDB.Get(ctx, pars)
 	if err != nil {
 		return fmt.Errorf("get data from PostgreSQL - %w", err)
 	}
 	if !found {
-		return fmt.Errorf("record not found")
+		return errs.NotFound
+	}
+
+	edit := *obj
+	edit.UserID = nil
+
+	if err = s.prepareMetadata(ctx, pars.UserID, &edit); err != nil {
+		return err
+	}
+
+	if obj.Data != nil {
+		size := int64(len(*obj.Data))
+		edit.Size = &size
 	}
 
 	if existingObj.Type == model.BinaryDataType && obj.Data != nil {
-		url, err := s.repoS3.UploadFile(ctx, pars.ID, *obj.Data)
+		objectKey := model.NewObjectKey(existingObj.ID, time.Now())
+		url, err := s.repoBlob.UploadFile(ctx, objectKey, *obj.Data)
 		if err != nil {
-			return fmt.Errorf("upload file to MinIO - %w", err)
+			return fmt.Errorf("upload file to blob storage - %w", err)
 		}
-		obj.URL = &url
+		edit.URL = &url
+		edit.ObjectKey = &objectKey
+	}
+
+	if edit.UpdatedAt == nil {
+		now := time.Now()
+		edit.UpdatedAt = &now
 	}
 
-	return s.repoDB.Update(ctx, pars, obj)
+	return s.repoDB.Update(ctx, pars, &edit)
 }
 
-// Delete removes a data item from the database. If the item is of binary type,
-// it also deletes the associated file from S3.
+// Delete moves a data item of the user to the trash. The item is kept as a tombstone, so clients
+// learn about the deletion on sync, until it is restored or purged.
+// Items owned by other users are reported as not found.
 func (s *Service) Delete(ctx context.Context, pars *model.GetPars) error {
-	existingObj, found, err := s.repoDB.Get(ctx, pars)
+	if !isOwnedItemPars(pars) {
+		return errs.InvalidInput
+	}
+
+	now := time.Now()
+
+	found, err := s.repoDB.SetDeleted(ctx, pars, &now, now)
+	if err != nil {
+		return fmt.Errorf("move data to trash in PostgreSQL - %w", err)
+	}
+	if !found {
+		return errs.NotFound
+	}
+
+	return nil
+}
+
+// Restore moves a data item of the user back from the trash.
+// Items owned by other users or not in the trash are reported as not found.
+func (s *Service) Restore(ctx context.Context, pars *model.GetPars) error {
+	if !isOwnedItemPars(pars) {
+		return errs.InvalidInput
+	}
+
+	found, err := s.repoDB.SetDeleted(ctx, pars, nil, time.Now())
+	if err != nil {
+		return fmt.Errorf("restore data from trash in PostgreSQL - %w", err)
+	}
+	if !found {
+		return errs.NotFound
+	}
+
+	return nil
+}
+
+// ListTrash retrieves the data items of the user that are in the trash.
+func (s *Service) ListTrash(ctx context.Context, userID string) ([]*model.DataItems, error) {
+	if userID == "" {
+		return nil, errs.InvalidInput
+	}
+
+	items, _, err := s.repoDB.List(ctx, &model.ListPars{
+		UserID:      &userID,
+		OnlyDeleted: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list trash from PostgreSQL - %w", err)
+	}
+
+	return items, nil
+}
+
+// Purge permanently removes the data items moved to the trash before the given time,
+// with all their versions and the files of binary items. It returns the number of purged items.
+// The items are removed in a single transaction before their files, a failure leaves orphaned
+// files behind at worst, never items without their content.
+func (s *Service) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	items, _, err := s.repoDB.List(ctx, &model.ListPars{
+		DeletedBefore: &deletedBefore,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list trash from PostgreSQL - %w", err)
+	}
+
+	var objectKeys []string
+	err = s.repoDB.InTx(ctx, func(ctx context.Context) error {
+		for _, item := range items {
+			if item.Type == model.BinaryDataType {
+				keys, err := s.objectKeys(ctx, item)
+				if err != nil {
+					return err
+				}
+				objectKeys = append(objectKeys, keys...)
+			}
+
+			err := s.repoDB.Delete(ctx, &model.GetPars{
+				ID:          item.ID,
+				UserID:      item.UserID,
+				WithDeleted: true,
+			})
+			if err != nil {
+				return fmt.Errorf("purge data in PostgreSQL - %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(items), s.deleteObjects(ctx, objectKeys)
+}
+
+// DeleteUserFiles removes the files of all binary items of the user, including the ones in the trash,
+// from the blob storage. It is called before deleting the account, which removes the items themselves.
+func (s *Service) DeleteUserFiles(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errs.InvalidInput
+	}
+
+	binaryType := model.BinaryDataType
+	items, _, err := s.repoDB.List(ctx, &model.ListPars{
+		UserID:      &userID,
+		Type:        &binaryType,
+		WithDeleted: true,
+		WithoutData: true,
+	})
+	if err != nil {
+		return fmt.Errorf("list data from PostgreSQL - %w", err)
+	}
+
+	for _, item := range items {
+		if err = s.deleteFiles(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteFiles removes the files of all versions of the binary item from the blob storage.
+func (s *Service) deleteFiles(ctx context.Context, obj *model.DataItems) error {
+	objectKeys, err := s.objectKeys(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteObjects(ctx, objectKeys)
+}
+
+// objectKeys returns the keys the content of the binary item and of all its versions is stored under.
+func (s *Service) objectKeys(ctx context.Context, obj *model.DataItems) ([]string, error) {
+	versions, err := s.repoDB.ListVersions(ctx, &model.VersionPars{
+		ItemID: obj.ID,
+		UserID: obj.UserID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list versions from PostgreSQL - %w", err)
+	}
+
+	candidates := []string{obj.ObjectKey}
+	for _, version := range versions {
+		candidates = append(candidates, version.ObjectKey)
+	}
+
+	seen := map[string]struct{}{"": {}}
+	objectKeys := make([]string, 0, len(candidates))
+	for _, objectKey := range candidates {
+		if _, ok := seen[objectKey]; ok {
+			continue
+		}
+		seen[objectKey] = struct{}{}
+		objectKeys = append(objectKeys, objectKey)
+	}
+
+	return objectKeys, nil
+}
+
+// deleteObjects removes the files stored under the object keys from the blob storage.
+func (s *Service) deleteObjects(ctx context.Context, objectKeys []string) error {
+	for _, objectKey := range objectKeys {
+		err := s.repoBlob.DeleteFile(ctx, &model.GetPars{ID: objectKey})
+		if err != nil {
+			return fmt.Errorf("delete file from blob storage - %w", err)
+		}
+	}
+
+	return nil
+}
+
+// isOwnedItemPars reports whether the parameters address a single item of a specific user.
+func isOwnedItemPars(pars *model.GetPars) bool {
+	return pars != nil && pars.ID != "" && pars.UserID != ""
+}
+
+// Upload stores the binary content read from the reader in the blob storage without buffering it in memory.
+// The item is created if it does not exist yet, otherwise its content and meta are replaced
+// by a new version. The content is stored under a new key, so the previous versions keep theirs.
+// A non-negative size has to match the length of the content, a negative one means it is unknown.
+func (s *Service) Upload(ctx context.Context, obj *model.Edit, reader io.Reader, size int64) error {
+	if obj == nil || obj.ID == "" || obj.UserID == nil || *obj.UserID == "" {
+		return errs.InvalidInput
+	}
+
+	existingObj, found, err := s.repoDB.Get(ctx, &model.GetPars{
+		ID:     obj.ID,
+		UserID: *obj.UserID,
+	})
 	if err != nil {
 		return fmt.Errorf("get data from PostgreSQL - %w", err)
 	}
+
+	if found && existingObj.Type != model.BinaryDataType {
+		return errs.InvalidInput
+	}
+
+	now := time.Now()
+	objectKey := model.NewObjectKey(obj.ID, now)
+
+	counter := &countingReader{reader: reader, size: size}
+	url, err := s.repoBlob.Upload(ctx, objectKey, counter, size)
+	if err != nil {
+		if counter.mismatch {
+			return errUploadSizeMismatch
+		}
+		return fmt.Errorf("upload file to blob storage - %w", err)
+	}
+	if !counter.complete() {
+		_ = s.repoBlob.DeleteFile(ctx, &model.GetPars{ID: objectKey})
+		return errUploadSizeMismatch
+	}
+
+	if !found {
+		binaryType := model.BinaryDataType
+		emptyData := make([]byte, 0)
+
+		err = s.repoDB.Create(ctx, &model.Edit{
+			ID:        obj.ID,
+			UserID:    obj.UserID,
+			Type:      &binaryType,
+			Data:      &emptyData,
+			Meta:      obj.Meta,
+			URL:       &url,
+			ObjectKey: &objectKey,
+			Size:      &counter.read,
+		})
+	} else {
+		err = s.repoDB.Update(ctx, &model.GetPars{
+			ID:     obj.ID,
+			UserID: *obj.UserID,
+		}, &model.Edit{
+			Meta:      obj.Meta,
+			URL:       &url,
+			ObjectKey: &objectKey,
+			Size:      &counter.read,
+			UpdatedAt: &now,
+		})
+	}
+	if err != nil {
+		_ = s.repoBlob.DeleteFile(ctx, &model.GetPars{ID: objectKey})
+		return fmt.Errorf("save data in PostgreSQL - %w", err)
+	}
+
+	return nil
+}
+
+// errUploadSizeMismatch is returned when the uploaded content is shorter or longer than its declared size.
+var errUploadSizeMismatch = &errs.ValidationError{Violations: []errs.FieldViolation{
+	{Field: "size", Description: "must match the length of the uploaded content"},
+}}
+
+// countingReader counts the bytes read through it, so the size of a streamed upload is known
+// without trusting the size declared by the client. A non-negative declared size is enforced,
+// reading past it or reaching the end before it fails the read.
+type countingReader struct {
+	reader   io.Reader
+	size     int64
+	read     int64
+	mismatch bool
+}
+
+// Read reads from the underlying reader and adds the number of read bytes to the counter.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	if r.size >= 0 && (r.read > r.size || errors.Is(err, io.EOF) && r.read != r.size) {
+		r.mismatch = true
+		return n, errUploadSizeMismatch
+	}
+	return n, err
+}
+
+// complete reports whether the content read so far matches the declared size. The blob storage
+// may stop reading at the declared size, so the reader is checked for more content.
+func (r *countingReader) complete() bool {
+	if r.size < 0 || r.mismatch {
+		return !r.mismatch
+	}
+	if r.read != r.size {
+		return false
+	}
+
+	n, _ := r.Read(make([]byte, 1))
+	return n == 0 && !r.mismatch
+}
+
+// Download opens the binary content of the item stored in the blob storage for reading. It returns the item,
+// the reader with its content, the content size, and a boolean indicating whether the item exists.
+// The caller must close the reader.
+func (s *Service) Download(ctx context.Context, pars *model.GetPars) (*model.DataItems, io.ReadCloser, int64, bool, error) {
+	if !isOwnedItemPars(pars) {
+		return nil, nil, 0, false, errs.InvalidInput
+	}
+
+	obj, found, err := s.repoDB.Get(ctx, pars)
+	if err != nil {
+		return nil, nil, 0, false, fmt.Errorf("get data from PostgreSQL - %w", err)
+	}
 	if !found {
-		return fmt.Errorf("record not found")
+		return nil, nil, 0, false, nil
+	}
+
+	if obj.Type != model.BinaryDataType {
+		return nil, nil, 0, false, errs.InvalidInput
+	}
+
+	return s.download(ctx, obj)
+}
+
+// ListVersions retrieves the versions of the item of the user, newest first, without their data.
+// Items owned by other users are reported as not found.
+func (s *Service) ListVersions(ctx context.Context, pars *model.VersionPars) ([]*model.DataItems, error) {
+	if !pars.IsValid() {
+		return nil, errs.InvalidInput
+	}
+
+	versions, err := s.repoDB.ListVersions(ctx, pars)
+	if err != nil {
+		return nil, fmt.Errorf("list versions from PostgreSQL - %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, errs.NotFound
+	}
+
+	return versions, nil
+}
+
+// GetVersion retrieves a version of the item of the user. Binary content is not loaded,
+// it is streamed with DownloadVersion instead.
+func (s *Service) GetVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, bool, error) {
+	if !pars.IsValid() {
+		return nil, false, errs.InvalidInput
+	}
+
+	version, found, err := s.repoDB.GetVersion(ctx, pars)
+	if err != nil {
+		return nil, false, fmt.Errorf("get version from PostgreSQL - %w", err)
+	}
+
+	return version, found, nil
+}
+
+// RestoreVersion makes the content of the version current again, along with its name, folder, tags and labels.
+// The restored state becomes a new version, so the history is never rewritten. A folder deleted since
+// the version was kept is not recreated, the item is restored outside of any folder instead.
+// It returns the item as it is after the restore.
+func (s *Service) RestoreVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, error) {
+	version, found, err := s.GetVersion(ctx, pars)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errs.NotFound
+	}
+
+	folderID := version.FolderID
+	if folderID != "" {
+		if err = s.checkFolder(ctx, pars.UserID, folderID); err != nil {
+			if !errors.Is(err, errs.FolderNotFound) {
+				return nil, err
+			}
+			folderID = ""
+		}
+	}
+
+	tags := version.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	labels := version.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	now := time.Now()
+	itemPars := &model.GetPars{
+		ID:     pars.ItemID,
+		UserID: pars.UserID,
 	}
 
-	if existingObj.Type == model.BinaryDataType {
-		err = s.repoS3.DeleteFile(ctx, &model.GetPars{ID: existingObj.ID})
+	err = s.repoDB.Update(ctx, itemPars, &model.Edit{
+		Type:      &version.Type,
+		Data:      &version.Data,
+		Meta:      &version.Meta,
+		URL:       &version.URL,
+		ObjectKey: &version.ObjectKey,
+		Size:      &version.Size,
+		Name:      &version.Name,
+		FolderID:  &folderID,
+		Tags:      &tags,
+		Labels:    &labels,
+		UpdatedAt: &now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("restore version in PostgreSQL - %w", err)
+	}
+
+	obj, found, err := s.repoDB.Get(ctx, itemPars)
+	if err != nil {
+		return nil, fmt.Errorf("get data from PostgreSQL - %w", err)
+	}
+	if !found {
+		return nil, errs.NotFound
+	}
+
+	return obj, nil
+}
+
+// DownloadVersion opens the binary content of the version stored in the blob storage for reading. It returns the version,
+// the reader with its content, the content size, and a boolean indicating whether the version exists.
+// The caller must close the reader.
+func (s *Service) DownloadVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, io.ReadCloser, int64, bool, error) {
+	version, found, err := s.GetVersion(ctx, pars)
+	if err != nil {
+		return nil, nil, 0, false, err
+	}
+	if !found {
+		return nil, nil, 0, false, nil
+	}
+
+	if version.Type != model.BinaryDataType {
+		return nil, nil, 0, false, errs.InvalidInput
+	}
+
+	return s.download(ctx, version)
+}
+
+// download opens the file of the binary item or version stored in the blob storage for reading.
+func (s *Service) download(ctx context.Context, obj *model.DataItems) (*model.DataItems, io.ReadCloser, int64, bool, error) {
+	reader, size, found, err := s.repoBlob.Download(ctx, obj.ObjectKey)
+	if err != nil {
+		return nil, nil, 0, false, fmt.Errorf("get data from blob storage - %w", err)
+	}
+	if !found {
+		return nil, nil, 0, false, nil
+	}
+
+	return obj, reader, size, true, nil
+}
+
+// Sync reconciles the items changed on the client with the server state. The version of a client
+// item is the version of the server item the change is based on. Changes based on the current version
+// are applied, the others and the changes of items moved to the trash since are reported as conflicts
+// instead of being overwritten, and server items the client is missing or has stale are returned
+// along with the applied changes, so the client learns their new versions. The changes are applied
+// in a single transaction, so a failed synchronization leaves no change behind. The item rows are locked
+// before the versions are compared, a concurrent synchronization based on the same version waits
+// for this one and reports a conflict instead of overwriting the change.
+// Items in the trash are returned as tombstones, so clients learn about deletions.
+func (s *Service) Sync(ctx context.Context, userID string, items []*model.DataItems, lastSyncedAt time.Time) (*model.SyncResult, error) {
+	result := &model.SyncResult{
+		SyncedAt: time.Now(),
+	}
+
+	sent := make(map[string]struct{}, len(items))
+	err := s.repoDB.InTx(ctx, func(ctx context.Context) error {
+		for _, item := range items {
+			sent[item.ID] = struct{}{}
+
+			if err := s.syncItem(ctx, userID, item, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changed, _, err := s.List(ctx, &model.ListPars{
+		UserID:       &userID,
+		UpdatedAfter: &lastSyncedAt,
+		WithDeleted:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range changed {
+		if _, ok := sent[item.ID]; ok {
+			continue
+		}
+		result.Updates = append(result.Updates, item)
+	}
+
+	return result, nil
+}
+
+// syncItem reconciles a single client item with the server state, adding the applied and stale items
+// and the conflicts to the result. The item is stored with the time of the synchronization,
+// as the clock of the client can not be relied on.
+func (s *Service) syncItem(ctx context.Context, userID string, item *model.DataItems, result *model.SyncResult) error {
+	serverItem, found, err := s.Get(ctx, &model.GetPars{
+		ID:          item.ID,
+		UserID:      userID,
+		WithDeleted: true,
+		ForUpdate:   true,
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		serverItem = nil
+	}
+
+	syncedAt := result.SyncedAt.Truncate(time.Microsecond)
+
+	switch model.ResolveSync(item, serverItem) {
+	case model.SyncActionCreate:
+		err = s.Create(ctx, &model.Edit{
+			ID:        item.ID,
+			UserID:    &userID,
+			Type:      &item.Type,
+			Data:      &item.Data,
+			Meta:      &item.Meta,
+			CreatedAt: &syncedAt,
+			UpdatedAt: &syncedAt,
+		})
 		if err != nil {
-			return fmt.Errorf("delete file to MinIO - %w", err)
+			return fmt.Errorf("sync create %s - %w", item.ID, err)
 		}
+	case model.SyncActionApply:
+		err = s.Update(ctx, &model.GetPars{
+			ID:     item.ID,
+			UserID: userID,
+		}, &model.Edit{
+			Type:      &item.Type,
+			Data:      &item.Data,
+			Meta:      &item.Meta,
+			UpdatedAt: &syncedAt,
+		})
+		if errors.Is(err, errs.NotFound) {
+			// the item was moved to the trash meanwhile, the client resolves the conflict with the tombstone
+			tombstone, found, err := s.Get(ctx, &model.GetPars{
+				ID:          item.ID,
+				UserID:      userID,
+				WithDeleted: true,
+			})
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("sync update %s - %w", item.ID, errs.NotFound)
+			}
+			result.Conflicts = append(result.Conflicts, &model.SyncConflict{
+				Client: item,
+				Server: tombstone,
+			})
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("sync update %s - %w", item.ID, err)
+		}
+	case model.SyncActionSkip:
+		return nil
+	case model.SyncActionStale:
+		result.Updates = append(result.Updates, serverItem)
+		return nil
+	case model.SyncActionConflict:
+		result.Conflicts = append(result.Conflicts, &model.SyncConflict{
+			Client: item,
+			Server: serverItem,
+		})
+		return nil
 	}
 
-	return s.repoDB.Delete(ctx, pars)
+	applied, found, err := s.Get(ctx, &model.GetPars{
+		ID:     item.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("sync %s - %w", item.ID, errs.NotFound)
+	}
+	result.Updates = append(result.Updates, applied)
+
+	return nil
 }
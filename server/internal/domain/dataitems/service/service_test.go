@@ -1,21 +1,28 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file" // Импортируем драйвер для работы с файлами
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 	"gophKeeper/server/internal/domain/dataitems/model"
+	dataItemsRepoMemP "gophKeeper/server/internal/domain/dataitems/repo/mem"
 	dataItemsRepoPgP "gophKeeper/server/internal/domain/dataitems/repo/pg"
 	dataItemsRepoS3P "gophKeeper/server/internal/domain/dataitems/repo/s3"
+	"gophKeeper/server/internal/errs"
+	"gophKeeper/server/internal/migrator"
+	"io"
 	"log"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func getPgPoolTestContainer() (*pgxpool.Pool, error) {
@@ -78,20 +85,14 @@ func getPgPoolTestContainer() (*pgxpool.Pool, error) {
 }
 
 func migrateUp(dsn string) error {
-	m, err := migrate.New(
-		"file://../../../../migrations",
-		dsn,
-	)
+	m, err := migrator.New(dsn, time.Minute)
 	if err != nil {
-		log.Fatalf("Ошибка при настройке миграций: %v\n", err)
-	}
-
-	// Применение всех миграций
-	if err = m.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatalf("Ошибка при применении миграций: %v\n", err)
+		return err
 	}
+	defer m.Close()
 
-	return err
+	// Применение всех встроенных миграций
+	return m.Up(context.Background())
 }
 
 func setupMinio(ctx context.Context) (string, string, string, string, error) {
@@ -148,8 +149,8 @@ func TestNew(t *testing.T) {
 	}
 
 	type args struct {
-		repoDB RepoDBI
-		repoS3 RepoS3
+		repoDB   RepoDBI
+		repoBlob RepoBlob
 	}
 	tests := []struct {
 		name string
@@ -159,18 +160,18 @@ func TestNew(t *testing.T) {
 		{
 			name: "Create new data items service",
 			args: args{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			want: &Service{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := New(tt.args.repoDB, tt.args.repoS3); !reflect.DeepEqual(got, tt.want) {
+			if got := New(tt.args.repoDB, tt.args.repoBlob); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("New() = %v, want %v", got, tt.want)
 			}
 		})
@@ -191,8 +192,8 @@ func TestService_Create(t *testing.T) {
 	meta := "binary"
 
 	type fields struct {
-		repoDB RepoDBI
-		repoS3 RepoS3
+		repoDB   RepoDBI
+		repoBlob RepoBlob
 	}
 	type args struct {
 		ctx context.Context
@@ -207,8 +208,8 @@ func TestService_Create(t *testing.T) {
 		{
 			name: "Create new data items service - bank card",
 			fields: fields{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			args: args{
 				ctx: context.Background(),
@@ -224,8 +225,8 @@ func TestService_Create(t *testing.T) {
 		{
 			name: "Create new data items service - credentials",
 			fields: fields{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			args: args{
 				ctx: context.Background(),
@@ -241,8 +242,8 @@ func TestService_Create(t *testing.T) {
 		{
 			name: "Create new data items service - binary",
 			fields: fields{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			args: args{
 				ctx: context.Background(),
@@ -259,8 +260,8 @@ func TestService_Create(t *testing.T) {
 		{
 			name: "Create new data items service - binary error",
 			fields: fields{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			args: args{
 				ctx: context.Background(),
@@ -277,8 +278,8 @@ func TestService_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Service{
-				repoDB: tt.fields.repoDB,
-				repoS3: tt.fields.repoS3,
+				repoDB:   tt.fields.repoDB,
+				repoBlob: tt.fields.repoBlob,
 			}
 
 			if err := s.Create(tt.args.ctx, tt.args.obj); (err != nil) != tt.wantErr {
@@ -296,8 +297,8 @@ func TestService_Delete(t *testing.T) {
 
 	testModel := testModelEdit()
 	type fields struct {
-		repoDB RepoDBI
-		repoS3 RepoS3
+		repoDB   RepoDBI
+		repoBlob RepoBlob
 	}
 	type args struct {
 		ctx  context.Context
@@ -313,13 +314,14 @@ func TestService_Delete(t *testing.T) {
 		{
 			name: "Delete new data items service",
 			fields: fields{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			args: args{
 				ctx: context.Background(),
 				pars: &model.GetPars{
-					ID: testModel.ID,
+					ID:     testModel.ID,
+					UserID: *testModel.UserID,
 				},
 				obj: testModel,
 			},
@@ -329,8 +331,8 @@ func TestService_Delete(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Service{
-				repoDB: tt.fields.repoDB,
-				repoS3: tt.fields.repoS3,
+				repoDB:   tt.fields.repoDB,
+				repoBlob: tt.fields.repoBlob,
 			}
 
 			if err := s.Create(tt.args.ctx, tt.args.obj); (err != nil) != tt.wantErr {
@@ -352,8 +354,8 @@ func TestService_Get(t *testing.T) {
 
 	testModel := testModelEdit()
 	type fields struct {
-		repoDB RepoDBI
-		repoS3 RepoS3
+		repoDB   RepoDBI
+		repoBlob RepoBlob
 	}
 	type args struct {
 		ctx  context.Context
@@ -371,13 +373,14 @@ func TestService_Get(t *testing.T) {
 		{
 			name: "Get new data items service",
 			fields: fields{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			args: args{
 				ctx: context.Background(),
 				pars: &model.GetPars{
-					ID: testModel.ID,
+					ID:     testModel.ID,
+					UserID: *testModel.UserID,
 				},
 				obj: testModel,
 			},
@@ -391,8 +394,8 @@ func TestService_Get(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Service{
-				repoDB: tt.fields.repoDB,
-				repoS3: tt.fields.repoS3,
+				repoDB:   tt.fields.repoDB,
+				repoBlob: tt.fields.repoBlob,
 			}
 
 			if err = s.Create(tt.args.ctx, tt.args.obj); (err != nil) != tt.wantErr {
@@ -422,8 +425,8 @@ func TestService_List(t *testing.T) {
 
 	testModel := testModelEdit()
 	type fields struct {
-		repoDB RepoDBI
-		repoS3 RepoS3
+		repoDB   RepoDBI
+		repoBlob RepoBlob
 	}
 	type args struct {
 		ctx  context.Context
@@ -440,8 +443,8 @@ func TestService_List(t *testing.T) {
 		{
 			name: "List new data items service",
 			fields: fields{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			args: args{
 				ctx: context.Background(),
@@ -457,8 +460,8 @@ func TestService_List(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Service{
-				repoDB: tt.fields.repoDB,
-				repoS3: tt.fields.repoS3,
+				repoDB:   tt.fields.repoDB,
+				repoBlob: tt.fields.repoBlob,
 			}
 
 			if err = s.Create(tt.args.ctx, tt.args.obj); (err != nil) != tt.wantErr {
@@ -486,8 +489,8 @@ func TestService_Update(t *testing.T) {
 
 	testModel := testModelEdit()
 	type fields struct {
-		repoDB RepoDBI
-		repoS3 RepoS3
+		repoDB   RepoDBI
+		repoBlob RepoBlob
 	}
 	type args struct {
 		ctx  context.Context
@@ -503,13 +506,14 @@ func TestService_Update(t *testing.T) {
 		{
 			name: "Update new data items service",
 			fields: fields{
-				repoDB: dataItemsPgRepo,
-				repoS3: dataItemsS3Repo,
+				repoDB:   dataItemsPgRepo,
+				repoBlob: dataItemsS3Repo,
 			},
 			args: args{
 				ctx: context.Background(),
 				pars: &model.GetPars{
-					ID: testModel.ID,
+					ID:     testModel.ID,
+					UserID: *testModel.UserID,
 				},
 				obj: testModel,
 			},
@@ -519,8 +523,8 @@ func TestService_Update(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Service{
-				repoDB: tt.fields.repoDB,
-				repoS3: tt.fields.repoS3,
+				repoDB:   tt.fields.repoDB,
+				repoBlob: tt.fields.repoBlob,
 			}
 
 			if err = s.Create(tt.args.ctx, tt.args.obj); (err != nil) != tt.wantErr {
@@ -537,6 +541,89 @@ func TestService_Update(t *testing.T) {
 	}
 }
 
+func TestService_Sync(t *testing.T) {
+	dataItemsPgRepo, dataItemsS3Repo, err := testRepos()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Service{
+		repoDB:   dataItemsPgRepo,
+		repoBlob: dataItemsS3Repo,
+	}
+
+	ctx := context.Background()
+	testUserID := "999"
+	textType := model.TextDataType
+
+	serverModel := testModelEdit()
+	serverModel.Type = &textType
+	if err = s.Create(ctx, serverModel); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	lastSyncedAt := time.Now()
+
+	tests := []struct {
+		name          string
+		prepare       func()
+		items         []*model.DataItems
+		wantConflicts int
+	}{
+		{
+			name: "apply client change of the current version and create missing item",
+			items: []*model.DataItems{
+				{
+					ID:      serverModel.ID,
+					Type:    textType,
+					Data:    []byte("client"),
+					Version: 1,
+				},
+				{
+					ID:   uuid.New().String(),
+					Type: textType,
+					Data: []byte("new"),
+				},
+			},
+			wantConflicts: 0,
+		},
+		{
+			name: "report conflict when both sides changed",
+			prepare: func() {
+				data := []byte("server")
+				if err := s.Update(ctx, &model.GetPars{ID: serverModel.ID, UserID: testUserID}, &model.Edit{Data: &data}); err != nil {
+					t.Fatalf("Update() error = %v", err)
+				}
+			},
+			items: []*model.DataItems{
+				{
+					ID:      serverModel.ID,
+					Type:    textType,
+					Data:    []byte("client again"),
+					Version: 2,
+				},
+			},
+			wantConflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+
+			got, err := s.Sync(ctx, testUserID, tt.items, lastSyncedAt)
+			if err != nil {
+				t.Errorf("Sync() error = %v", err)
+				return
+			}
+			if len(got.Conflicts) != tt.wantConflicts {
+				t.Errorf("Sync() conflicts = %v, want %v", len(got.Conflicts), tt.wantConflicts)
+			}
+		})
+	}
+}
+
 func testRepos() (*dataItemsRepoPgP.Repo, *dataItemsRepoS3P.S3Repo, error) {
 	pgpool, err := getPgPoolTestContainer()
 	if err != nil {
@@ -572,3 +659,1074 @@ func testModelEdit() *model.Edit {
 		Meta:   &meta,
 	}
 }
+
+// repoDBMock is an in-memory database repository applying the same filters as the PostgreSQL one
+// and keeping a version of every change.
+type repoDBMock struct {
+	items    map[string]*model.DataItems
+	versions map[string][]*model.DataItems
+	folders  map[string]*model.Folder
+	updated  []*model.GetPars
+	deleted  []*model.GetPars
+
+	// createErr is returned by Create when set
+	createErr error
+	// deleteErr is returned by Delete when set
+	deleteErr error
+}
+
+func newRepoDBMock(items ...*model.DataItems) *repoDBMock {
+	m := &repoDBMock{
+		items:    make(map[string]*model.DataItems, len(items)),
+		versions: make(map[string][]*model.DataItems, len(items)),
+		folders:  make(map[string]*model.Folder),
+	}
+	for _, item := range items {
+		item.Version = 1
+		m.items[item.ID] = item
+		m.snapshot(item)
+	}
+	return m
+}
+
+func (m *repoDBMock) snapshot(item *model.DataItems) {
+	version := *item
+	m.versions[item.ID] = append(m.versions[item.ID], &version)
+}
+
+func (m *repoDBMock) matches(pars *model.GetPars) (*model.DataItems, bool) {
+	for _, item := range m.items {
+		if (pars.ID == "" || item.ID == pars.ID) && (pars.UserID == "" || item.UserID == pars.UserID) &&
+			(pars.WithDeleted || item.DeletedAt == nil) {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func (m *repoDBMock) Get(_ context.Context, pars *model.GetPars) (*model.DataItems, bool, error) {
+	item, found := m.matches(pars)
+	if !found {
+		return nil, false, nil
+	}
+	obj := *item
+	return &obj, true, nil
+}
+
+func (m *repoDBMock) List(_ context.Context, pars *model.ListPars) ([]*model.DataItems, int64, error) {
+	var result []*model.DataItems
+	for _, item := range m.items {
+		switch {
+		case pars.UserID != nil && item.UserID != *pars.UserID:
+			continue
+		case pars.Type != nil && item.Type != *pars.Type:
+			continue
+		case pars.OnlyDeleted || pars.DeletedBefore != nil:
+			if item.DeletedAt == nil || (pars.DeletedBefore != nil && item.DeletedAt.After(*pars.DeletedBefore)) {
+				continue
+			}
+		case !pars.WithDeleted && item.DeletedAt != nil:
+			continue
+		}
+		obj := *item
+		if pars.WithoutData {
+			obj.Data = nil
+		}
+		result = append(result, &obj)
+	}
+
+	if pars.Sort == model.SortByID {
+		sort.Slice(result, func(i, j int) bool {
+			return (result[i].ID < result[j].ID) != pars.Descending
+		})
+		if pars.After != nil {
+			i := 0
+			for i < len(result) && (result[i].ID == pars.After.ID || (result[i].ID < pars.After.ID) != pars.Descending) {
+				i++
+			}
+			result = result[i:]
+		}
+	}
+	if pars.Limit > 0 && uint64(len(result)) > pars.Limit {
+		result = result[:pars.Limit]
+	}
+
+	return result, int64(len(result)), nil
+}
+
+func (m *repoDBMock) Create(_ context.Context, obj *model.Edit) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	item := &model.DataItems{ID: obj.ID, UserID: *obj.UserID, Type: *obj.Type, Version: 1}
+	if obj.Data != nil {
+		item.Data = *obj.Data
+	}
+	if obj.Size != nil {
+		item.Size = *obj.Size
+	}
+	if obj.ObjectKey != nil {
+		item.ObjectKey = *obj.ObjectKey
+	}
+	if obj.URL != nil {
+		item.URL = *obj.URL
+	}
+	setMetadata(item, obj)
+	m.items[obj.ID] = item
+	m.snapshot(item)
+	return nil
+}
+
+func (m *repoDBMock) Update(_ context.Context, pars *model.GetPars, obj *model.Edit) error {
+	m.updated = append(m.updated, pars)
+	item, found := m.matches(pars)
+	if !found {
+		return nil
+	}
+	if obj.UserID != nil {
+		item.UserID = *obj.UserID
+	}
+	if obj.Type != nil {
+		item.Type = *obj.Type
+	}
+	if obj.Data != nil {
+		item.Data = *obj.Data
+	}
+	if obj.Meta != nil {
+		item.Meta = *obj.Meta
+	}
+	if obj.Size != nil {
+		item.Size = *obj.Size
+	}
+	if obj.ObjectKey != nil {
+		item.ObjectKey = *obj.ObjectKey
+	}
+	if obj.URL != nil {
+		item.URL = *obj.URL
+	}
+	setMetadata(item, obj)
+	item.Version++
+	m.snapshot(item)
+	return nil
+}
+
+func (m *repoDBMock) Delete(_ context.Context, pars *model.GetPars) error {
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+	m.deleted = append(m.deleted, pars)
+	if item, found := m.matches(pars); found {
+		delete(m.items, item.ID)
+		delete(m.versions, item.ID)
+	}
+	return nil
+}
+
+func (m *repoDBMock) SetDeleted(_ context.Context, pars *model.GetPars, deletedAt *time.Time, updatedAt time.Time) (bool, error) {
+	m.deleted = append(m.deleted, pars)
+	item, found := m.matches(&model.GetPars{ID: pars.ID, UserID: pars.UserID, WithDeleted: true})
+	if !found || (item.DeletedAt == nil) == (deletedAt == nil) {
+		return false, nil
+	}
+	item.DeletedAt = deletedAt
+	item.UpdatedAt = updatedAt
+	return true, nil
+}
+
+func (m *repoDBMock) ListVersions(_ context.Context, pars *model.VersionPars) ([]*model.DataItems, error) {
+	var result []*model.DataItems
+	versions := m.versions[pars.ItemID]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].UserID == pars.UserID {
+			result = append(result, versions[i])
+		}
+	}
+	return result, nil
+}
+
+func (m *repoDBMock) GetVersion(_ context.Context, pars *model.VersionPars) (*model.DataItems, bool, error) {
+	for _, version := range m.versions[pars.ItemID] {
+		if version.UserID == pars.UserID && version.Version == pars.Version {
+			obj := *version
+			return &obj, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (m *repoDBMock) Search(_ context.Context, pars *model.SearchPars) ([]*model.DataItems, error) {
+	var result []*model.DataItems
+	for _, item := range m.items {
+		if item.UserID != pars.UserID || item.DeletedAt != nil || (pars.Type != "" && item.Type != pars.Type) {
+			continue
+		}
+		if pars.FolderID != "" && !m.inFolder(item.FolderID, pars.FolderID, pars.Recursive) {
+			continue
+		}
+		if !hasAll(item.Tags, pars.Tags) || !hasLabels(item.Labels, pars.Labels) {
+			continue
+		}
+
+		words := model.SearchTerms(item.Name + " " + strings.Join(item.Tags, " "))
+		for key, value := range item.Labels {
+			words = append(words, model.SearchTerms(key+" "+value)...)
+		}
+		if !matchesTerms(words, model.SearchTerms(pars.Query)) {
+			continue
+		}
+
+		obj := *item
+		obj.Data = nil
+		result = append(result, &obj)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+func (m *repoDBMock) inFolder(folderID, parentID string, recursive bool) bool {
+	for folderID != "" {
+		if folderID == parentID {
+			return true
+		}
+		if !recursive {
+			return false
+		}
+		folderID = m.folders[folderID].ParentID
+	}
+	return false
+}
+
+func (m *repoDBMock) CreateFolder(_ context.Context, folder *model.Folder) error {
+	for _, f := range m.folders {
+		if f.UserID == folder.UserID && f.ParentID == folder.ParentID && f.Name == folder.Name {
+			return errs.FolderAlreadyExists
+		}
+	}
+	m.folders[folder.ID] = folder
+	return nil
+}
+
+func (m *repoDBMock) GetFolder(_ context.Context, userID, id string) (*model.Folder, bool, error) {
+	folder, found := m.folders[id]
+	if !found || folder.UserID != userID {
+		return nil, false, nil
+	}
+	return folder, true, nil
+}
+
+func (m *repoDBMock) ListFolders(_ context.Context, userID string) ([]*model.Folder, error) {
+	var result []*model.Folder
+	for _, folder := range m.folders {
+		if folder.UserID == userID {
+			result = append(result, folder)
+		}
+	}
+	return result, nil
+}
+
+func (m *repoDBMock) DeleteFolder(_ context.Context, userID, id string) (bool, error) {
+	folder, found := m.folders[id]
+	if !found || folder.UserID != userID {
+		return false, nil
+	}
+	delete(m.folders, id)
+	return true, nil
+}
+
+func setMetadata(item *model.DataItems, obj *model.Edit) {
+	if obj.Name != nil {
+		item.Name = *obj.Name
+	}
+	if obj.FolderID != nil {
+		item.FolderID = *obj.FolderID
+	}
+	if obj.Tags != nil {
+		item.Tags = *obj.Tags
+	}
+	if obj.Labels != nil {
+		item.Labels = *obj.Labels
+	}
+}
+
+func hasAll(tags, wanted []string) bool {
+	for _, want := range wanted {
+		found := false
+		for _, tag := range tags {
+			found = found || tag == want
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func hasLabels(labels, wanted map[string]string) bool {
+	for key, value := range wanted {
+		if got, ok := labels[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesTerms(words, terms []string) bool {
+	for _, term := range terms {
+		found := false
+		for _, word := range words {
+			found = found || strings.HasPrefix(word, term)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *repoDBMock) BeginTx(_ context.Context) (pgx.Tx, error)    { return nil, nil }
+func (m *repoDBMock) CommitTx(_ context.Context, _ pgx.Tx) error   { return nil }
+func (m *repoDBMock) RollbackTx(_ context.Context, _ pgx.Tx) error { return nil }
+func (m *repoDBMock) HandleTxCompletion(_ pgx.Tx, _ *error)        {}
+
+// InTx runs fn, restoring the items and their versions if it fails, as a rolled back transaction would.
+func (m *repoDBMock) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	items := make(map[string]*model.DataItems, len(m.items))
+	for id, item := range m.items {
+		obj := *item
+		items[id] = &obj
+	}
+	versions := make(map[string][]*model.DataItems, len(m.versions))
+	for id, itemVersions := range m.versions {
+		versions[id] = itemVersions[:len(itemVersions):len(itemVersions)]
+	}
+
+	if err := fn(ctx); err != nil {
+		m.items = items
+		m.versions = versions
+		return err
+	}
+	return nil
+}
+
+func TestService_SyncVersions(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	textType := model.TextDataType
+
+	repoDB := newRepoDBMock(
+		&model.DataItems{ID: "1", UserID: userID, Type: textType, Data: []byte("server")},
+		&model.DataItems{ID: "3", UserID: userID, Type: textType, Data: []byte("unchanged")},
+	)
+	s := New(repoDB, nil)
+
+	// a client that never synced before sends every item it has, none of them conflicts unless it changed
+	got, err := s.Sync(ctx, userID, []*model.DataItems{
+		{ID: "1", Type: textType, Data: []byte("client"), Version: 1},
+		{ID: "3", Type: textType, Data: []byte("unchanged"), Version: 1},
+	}, time.Time{})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if len(got.Conflicts) != 0 {
+		t.Errorf("Sync() conflicts = %v, want none", got.Conflicts)
+	}
+	// the applied change is returned with its new version, the unchanged item is not
+	if len(got.Updates) != 1 || got.Updates[0].ID != "1" || got.Updates[0].Version != 2 {
+		t.Errorf("Sync() updates = %v, want item 1 at version 2", got.Updates)
+	}
+	item, _, _ := repoDB.Get(ctx, &model.GetPars{ID: "1", UserID: userID})
+	if string(item.Data) != "client" || item.Version != 2 {
+		t.Errorf("item 1 = %s (version %v), want client (version 2)", item.Data, item.Version)
+	}
+	if item, _, _ = repoDB.Get(ctx, &model.GetPars{ID: "3", UserID: userID}); item.Version != 1 {
+		t.Errorf("item 3 version = %v, want unchanged 1", item.Version)
+	}
+
+	// a change based on the replaced version conflicts, whatever its timestamp says
+	got, err = s.Sync(ctx, userID, []*model.DataItems{
+		{ID: "1", Type: textType, Data: []byte("other client"), Version: 1, UpdatedAt: time.Now().Add(time.Hour)},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(got.Conflicts) != 1 || string(got.Conflicts[0].Server.Data) != "client" {
+		t.Errorf("Sync() conflicts = %v, want the server copy of item 1", got.Conflicts)
+	}
+}
+
+func TestService_SyncTrashed(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	textType := model.TextDataType
+
+	repoDB := newRepoDBMock(
+		&model.DataItems{ID: "1", UserID: userID, Type: textType, Data: []byte("trashed")},
+		&model.DataItems{ID: "2", UserID: userID, Type: textType, Data: []byte("server")},
+	)
+	s := New(repoDB, nil)
+
+	if err := s.Delete(ctx, &model.GetPars{ID: "1", UserID: userID}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	got, err := s.Sync(ctx, userID, []*model.DataItems{
+		{ID: "1", Type: textType, Data: []byte("edited in the trash"), Version: 1},
+		{ID: "2", Type: textType, Data: []byte("client"), Version: 1},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if len(got.Conflicts) != 1 || got.Conflicts[0].Server.ID != "1" || got.Conflicts[0].Server.DeletedAt == nil {
+		t.Errorf("Sync() conflicts = %v, want the tombstone of item 1", got.Conflicts)
+	}
+	item, _, _ := repoDB.Get(ctx, &model.GetPars{ID: "2", UserID: userID})
+	if string(item.Data) != "client" {
+		t.Errorf("item 2 = %s, want client", item.Data)
+	}
+}
+
+func TestService_SyncRollback(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	textType := model.TextDataType
+
+	repoDB := newRepoDBMock(&model.DataItems{ID: "1", UserID: userID, Type: textType, Data: []byte("server")})
+	repoDB.createErr = errors.New("connection lost")
+	s := New(repoDB, nil)
+
+	_, err := s.Sync(ctx, userID, []*model.DataItems{
+		{ID: "1", Type: textType, Data: []byte("client"), Version: 1},
+		{ID: "2", Type: textType, Data: []byte("new")},
+	}, time.Time{})
+	if err == nil {
+		t.Fatalf("Sync() error = nil, want the error of the failed create")
+	}
+
+	item, _, _ := repoDB.Get(ctx, &model.GetPars{ID: "1", UserID: userID})
+	if string(item.Data) != "server" || item.Version != 1 {
+		t.Errorf("item 1 = %s (version %v), want the change rolled back", item.Data, item.Version)
+	}
+}
+
+func TestService_Ownership(t *testing.T) {
+	ctx := context.Background()
+	ownerID := "owner"
+	intruderID := "intruder"
+	data := []byte("intruder data")
+
+	tests := []struct {
+		name    string
+		call    func(s *Service) error
+		wantErr error
+	}{
+		{
+			name: "get item of another user",
+			call: func(s *Service) error {
+				_, found, err := s.Get(ctx, &model.GetPars{ID: "1", UserID: intruderID})
+				if err == nil && found {
+					return fmt.Errorf("item of another user was found")
+				}
+				return err
+			},
+		},
+		{
+			name: "get without user",
+			call: func(s *Service) error {
+				_, _, err := s.Get(ctx, &model.GetPars{ID: "1"})
+				return err
+			},
+			wantErr: errs.InvalidInput,
+		},
+		{
+			name: "update item of another user",
+			call: func(s *Service) error {
+				return s.Update(ctx, &model.GetPars{ID: "1", UserID: intruderID}, &model.Edit{Data: &data})
+			},
+			wantErr: errs.NotFound,
+		},
+		{
+			name: "update without user",
+			call: func(s *Service) error {
+				return s.Update(ctx, &model.GetPars{ID: "1"}, &model.Edit{Data: &data})
+			},
+			wantErr: errs.InvalidInput,
+		},
+		{
+			name: "update owner of own item",
+			call: func(s *Service) error {
+				if err := s.Update(ctx, &model.GetPars{ID: "2", UserID: intruderID}, &model.Edit{UserID: &ownerID}); err != nil {
+					return err
+				}
+				if _, found, _ := s.repoDB.Get(ctx, &model.GetPars{ID: "2", UserID: ownerID}); found {
+					return fmt.Errorf("item was moved to another user")
+				}
+				return nil
+			},
+		},
+		{
+			name: "delete item of another user",
+			call: func(s *Service) error {
+				return s.Delete(ctx, &model.GetPars{ID: "1", UserID: intruderID})
+			},
+			wantErr: errs.NotFound,
+		},
+		{
+			name: "delete without user",
+			call: func(s *Service) error {
+				return s.Delete(ctx, &model.GetPars{ID: "1"})
+			},
+			wantErr: errs.InvalidInput,
+		},
+		{
+			name: "list versions of item of another user",
+			call: func(s *Service) error {
+				_, err := s.ListVersions(ctx, &model.VersionPars{ItemID: "1", UserID: intruderID})
+				return err
+			},
+			wantErr: errs.NotFound,
+		},
+		{
+			name: "restore version of item of another user",
+			call: func(s *Service) error {
+				_, err := s.RestoreVersion(ctx, &model.VersionPars{ItemID: "1", UserID: intruderID, Version: 1})
+				return err
+			},
+			wantErr: errs.NotFound,
+		},
+		{
+			name: "download item of another user",
+			call: func(s *Service) error {
+				_, _, _, found, err := s.Download(ctx, &model.GetPars{ID: "1", UserID: intruderID})
+				if err == nil && found {
+					return fmt.Errorf("item of another user was found")
+				}
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDB := newRepoDBMock(
+				&model.DataItems{ID: "1", UserID: ownerID, Type: model.TextDataType, Data: []byte("secret")},
+				&model.DataItems{ID: "2", UserID: intruderID, Type: model.TextDataType},
+			)
+			s := New(repoDB, nil)
+
+			if err := tt.call(s); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			owned := repoDB.items["1"]
+			if owned == nil || owned.UserID != ownerID || string(owned.Data) != "secret" {
+				t.Errorf("item of the owner = %v, want unchanged", owned)
+			}
+			for _, pars := range append(repoDB.updated, repoDB.deleted...) {
+				if pars.UserID == "" {
+					t.Errorf("repository called without user: %v", pars)
+				}
+			}
+		})
+	}
+}
+
+func TestService_RestoreVersion(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	textType := model.TextDataType
+
+	repoDB := newRepoDBMock(&model.DataItems{ID: "1", UserID: userID, Type: textType, Data: []byte("old password")})
+	s := New(repoDB, nil)
+
+	newData := []byte("new password")
+	if err := s.Update(ctx, &model.GetPars{ID: "1", UserID: userID}, &model.Edit{Data: &newData}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := s.RestoreVersion(ctx, &model.VersionPars{ItemID: "1", UserID: userID, Version: 1})
+	if err != nil {
+		t.Fatalf("RestoreVersion() error = %v", err)
+	}
+	if string(got.Data) != "old password" || got.Version != 3 {
+		t.Errorf("RestoreVersion() got = %s (version %v), want old password (version 3)", got.Data, got.Version)
+	}
+
+	versions, err := s.ListVersions(ctx, &model.VersionPars{ItemID: "1", UserID: userID})
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+
+	wantVersions := []string{"old password", "new password", "old password"}
+	if len(versions) != len(wantVersions) {
+		t.Fatalf("ListVersions() got %v versions, want %v", len(versions), len(wantVersions))
+	}
+	for i, version := range versions {
+		want := wantVersions[len(wantVersions)-1-i]
+		if string(version.Data) != want {
+			t.Errorf("ListVersions()[%d] = %s, want %s", i, version.Data, want)
+		}
+	}
+
+	if _, err = s.RestoreVersion(ctx, &model.VersionPars{ItemID: "1", UserID: userID, Version: 10}); !errors.Is(err, errs.NotFound) {
+		t.Errorf("RestoreVersion() error = %v, want %v", err, errs.NotFound)
+	}
+}
+
+func TestService_RestoreVersionMetadata(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	pars := &model.GetPars{ID: "1", UserID: userID}
+
+	repoDB := newRepoDBMock()
+	s := New(repoDB, nil)
+
+	work, err := s.CreateFolder(ctx, userID, "", "work")
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+
+	name, tags, labels := "mail", []string{"personal"}, map[string]string{"env": "prod"}
+	textType, data := model.TextDataType, []byte("secret")
+	err = s.Create(ctx, &model.Edit{
+		ID: pars.ID, UserID: &userID, Type: &textType, Data: &data,
+		Name: &name, FolderID: &work.ID, Tags: &tags, Labels: &labels,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	newName, noFolder, newTags, newLabels := "bank", "", []string{"finance"}, map[string]string{}
+	err = s.Update(ctx, pars, &model.Edit{Name: &newName, FolderID: &noFolder, Tags: &newTags, Labels: &newLabels})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := s.RestoreVersion(ctx, &model.VersionPars{ItemID: "1", UserID: userID, Version: 1})
+	if err != nil {
+		t.Fatalf("RestoreVersion() error = %v", err)
+	}
+	if got.Name != name || got.FolderID != work.ID || !reflect.DeepEqual(got.Tags, tags) || !reflect.DeepEqual(got.Labels, labels) {
+		t.Errorf("RestoreVersion() got = %q in %q with %v %v, want %q in %q with %v %v",
+			got.Name, got.FolderID, got.Tags, got.Labels, name, work.ID, tags, labels)
+	}
+
+	// a deleted folder is not recreated, the item is restored outside of any folder
+	if err = s.DeleteFolder(ctx, userID, work.ID); err != nil {
+		t.Fatalf("DeleteFolder() error = %v", err)
+	}
+
+	got, err = s.RestoreVersion(ctx, &model.VersionPars{ItemID: "1", UserID: userID, Version: 1})
+	if err != nil {
+		t.Fatalf("RestoreVersion() error = %v", err)
+	}
+	if got.Name != name || got.FolderID != "" {
+		t.Errorf("RestoreVersion() got = %q in %q, want %q outside of any folder", got.Name, got.FolderID, name)
+	}
+}
+
+func TestService_Trash(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	pars := &model.GetPars{ID: "1", UserID: userID}
+
+	repoDB := newRepoDBMock(&model.DataItems{ID: "1", UserID: userID, Type: model.TextDataType, Data: []byte("secret")})
+	s := New(repoDB, nil)
+
+	if err := s.Delete(ctx, pars); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, found, _ := s.Get(ctx, pars); found {
+		t.Errorf("Get() found item in the trash")
+	}
+	if err := s.Delete(ctx, pars); !errors.Is(err, errs.NotFound) {
+		t.Errorf("Delete() error = %v, want %v", err, errs.NotFound)
+	}
+
+	trash, err := s.ListTrash(ctx, userID)
+	if err != nil || len(trash) != 1 || trash[0].DeletedAt == nil {
+		t.Fatalf("ListTrash() = %v, %v, want one tombstone", trash, err)
+	}
+
+	if err = s.Restore(ctx, pars); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if _, found, _ := s.Get(ctx, pars); !found {
+		t.Errorf("Get() did not find restored item")
+	}
+	if err = s.Restore(ctx, pars); !errors.Is(err, errs.NotFound) {
+		t.Errorf("Restore() error = %v, want %v", err, errs.NotFound)
+	}
+
+	if err = s.Delete(ctx, pars); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	purged, err := s.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil || purged != 0 {
+		t.Errorf("Purge() = %v, %v, want 0 items within retention", purged, err)
+	}
+
+	purged, err = s.Purge(ctx, time.Now())
+	if err != nil || purged != 1 {
+		t.Errorf("Purge() = %v, %v, want 1 item", purged, err)
+	}
+	if _, found, _ := repoDB.Get(ctx, &model.GetPars{ID: "1", WithDeleted: true}); found {
+		t.Errorf("item was not purged")
+	}
+}
+
+func TestService_ListPage(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+
+	repoDB := newRepoDBMock()
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		repoDB.items[id] = &model.DataItems{ID: id, UserID: userID, Type: model.TextDataType}
+	}
+	s := New(repoDB, nil)
+
+	var (
+		got       []string
+		pageToken string
+		pages     int
+	)
+	for {
+		items, nextPageToken, err := s.ListPage(ctx, &model.ListPars{UserID: &userID, Sort: model.SortByID, Descending: true}, pageToken, 2)
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		for _, item := range items {
+			got = append(got, item.ID)
+		}
+		pages++
+
+		if nextPageToken == "" {
+			break
+		}
+		pageToken = nextPageToken
+	}
+
+	if want := []string{"5", "4", "3", "2", "1"}; !reflect.DeepEqual(got, want) || pages != 3 {
+		t.Errorf("ListPage() got = %v in %d pages, want %v in 3 pages", got, pages, want)
+	}
+
+	tests := []struct {
+		name      string
+		pars      *model.ListPars
+		pageToken string
+		pageSize  int
+	}{
+		{
+			name:      "invalid page token",
+			pars:      &model.ListPars{UserID: &userID},
+			pageToken: "invalid",
+		},
+		{
+			name:      "page token of another sort order",
+			pars:      &model.ListPars{UserID: &userID, Sort: model.SortByCreatedAt},
+			pageToken: model.NewCursor(repoDB.items["1"], model.SortByID, false).Encode(),
+		},
+		{
+			name: "unsupported sort field",
+			pars: &model.ListPars{UserID: &userID, Sort: "meta"},
+		},
+		{
+			name:     "negative page size",
+			pars:     &model.ListPars{UserID: &userID},
+			pageSize: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.ListPage(ctx, tt.pars, tt.pageToken, tt.pageSize)
+			if !errors.Is(err, errs.InvalidInput) {
+				t.Errorf("ListPage() error = %v, want %v", err, errs.InvalidInput)
+			}
+		})
+	}
+}
+
+func TestService_Summaries(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	textType := model.TextDataType
+	pars := &model.GetPars{ID: "1", UserID: userID}
+
+	s := New(newRepoDBMock(), nil)
+
+	data := []byte("secret")
+	if err := s.Create(ctx, &model.Edit{ID: "1", UserID: &userID, Type: &textType, Data: &data}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	newData := []byte("longer secret")
+	if err := s.Update(ctx, pars, &model.Edit{Data: &newData}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	items, _, err := s.ListPage(ctx, &model.ListPars{UserID: &userID, WithoutData: true}, "", 0)
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Data != nil || items[0].Size != int64(len(newData)) {
+		t.Fatalf("ListPage() got = %+v, want a summary of %d bytes without data", items, len(newData))
+	}
+
+	restored, err := s.RestoreVersion(ctx, &model.VersionPars{ItemID: "1", UserID: userID, Version: 1})
+	if err != nil {
+		t.Fatalf("RestoreVersion() error = %v", err)
+	}
+	if restored.Size != int64(len(data)) {
+		t.Errorf("RestoreVersion() size = %d, want %d", restored.Size, len(data))
+	}
+}
+
+func TestService_BlobStorage(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	binaryType := model.BinaryDataType
+	pars := &model.GetPars{ID: "1", UserID: userID}
+
+	repoBlob := dataItemsRepoMemP.NewMemRepo()
+	s := New(newRepoDBMock(), repoBlob)
+
+	data := []byte("binary")
+	if err := s.Create(ctx, &model.Edit{ID: "1", UserID: &userID, Type: &binaryType, Data: &data}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	newData := []byte("new binary")
+	if err := s.Update(ctx, pars, &model.Edit{Data: &newData}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if repoBlob.Len() != 2 {
+		t.Errorf("blob storage has %d files, want the files of both versions", repoBlob.Len())
+	}
+
+	got, found, err := s.Get(ctx, pars)
+	if err != nil || !found || !bytes.Equal(got.Data, newData) {
+		t.Fatalf("Get() = %v, %v, %v, want the data of the latest version", got, found, err)
+	}
+
+	if err = s.Upload(ctx, &model.Edit{ID: "1", UserID: &userID}, bytes.NewReader(data), -1); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	_, reader, size, found, err := s.Download(ctx, pars)
+	if err != nil || !found {
+		t.Fatalf("Download() found = %v, error = %v", found, err)
+	}
+	downloaded, _ := io.ReadAll(reader)
+	_ = reader.Close()
+	if !bytes.Equal(downloaded, data) || size != int64(len(data)) {
+		t.Errorf("Download() got = %q of %d bytes, want %q", downloaded, size, data)
+	}
+
+	if err = s.Delete(ctx, pars); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err = s.Purge(ctx, time.Now()); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if repoBlob.Len() != 0 {
+		t.Errorf("blob storage has %d files after purge, want 0", repoBlob.Len())
+	}
+}
+
+func TestService_Upload(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	data := []byte("binary")
+
+	tests := []struct {
+		name    string
+		obj     *model.Edit
+		size    int64
+		wantErr error
+	}{
+		{name: "declared size", obj: &model.Edit{ID: "1", UserID: &userID}, size: int64(len(data))},
+		{name: "unknown size", obj: &model.Edit{ID: "1", UserID: &userID}, size: -1},
+		{name: "no user", obj: &model.Edit{ID: "1"}, size: int64(len(data)), wantErr: errs.InvalidInput},
+		{name: "content shorter than size", obj: &model.Edit{ID: "1", UserID: &userID}, size: int64(len(data)) + 1, wantErr: errs.InvalidInput},
+		{name: "content longer than size", obj: &model.Edit{ID: "1", UserID: &userID}, size: int64(len(data)) - 1, wantErr: errs.InvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDB := newRepoDBMock()
+			repoBlob := dataItemsRepoMemP.NewMemRepo()
+			s := New(repoDB, repoBlob)
+
+			err := s.Upload(ctx, tt.obj, bytes.NewReader(data), tt.size)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Upload() error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantFiles, wantItems := 1, 1
+			if tt.wantErr != nil {
+				wantFiles, wantItems = 0, 0
+			}
+			if repoBlob.Len() != wantFiles || len(repoDB.items) != wantItems {
+				t.Errorf("Upload() stored %d files and %d items, want %d and %d", repoBlob.Len(), len(repoDB.items), wantFiles, wantItems)
+			}
+		})
+	}
+}
+
+func TestService_PurgeKeepsFiles(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	pars := &model.GetPars{ID: "1", UserID: userID}
+
+	repoDB := newRepoDBMock()
+	repoBlob := dataItemsRepoMemP.NewMemRepo()
+	s := New(repoDB, repoBlob)
+
+	if err := s.Upload(ctx, &model.Edit{ID: "1", UserID: &userID}, strings.NewReader("binary"), -1); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if err := s.Delete(ctx, pars); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	// the files of items which could not be removed are kept
+	repoDB.deleteErr = errors.New("connection lost")
+	if _, err := s.Purge(ctx, time.Now()); err == nil {
+		t.Fatalf("Purge() error = nil, want the error of the failed delete")
+	}
+	if _, found, _ := repoDB.Get(ctx, &model.GetPars{ID: "1", UserID: userID, WithDeleted: true}); !found || repoBlob.Len() != 1 {
+		t.Errorf("Purge() removed the file of the kept item")
+	}
+
+	repoDB.deleteErr = nil
+	purged, err := s.Purge(ctx, time.Now())
+	if err != nil || purged != 1 || repoBlob.Len() != 0 {
+		t.Errorf("Purge() = %v, %v with %d files left, want 1 item and no files", purged, err, repoBlob.Len())
+	}
+}
+
+func TestService_DeleteUserFiles(t *testing.T) {
+	ctx := context.Background()
+	userID, otherUserID := "999", "1000"
+	binaryType := model.BinaryDataType
+
+	repoBlob := dataItemsRepoMemP.NewMemRepo()
+	s := New(newRepoDBMock(), repoBlob)
+
+	data := []byte("binary")
+	for _, edit := range []*model.Edit{
+		{ID: "1", UserID: &userID, Type: &binaryType, Data: &data},
+		{ID: "2", UserID: &userID, Type: &binaryType, Data: &data},
+		{ID: "3", UserID: &otherUserID, Type: &binaryType, Data: &data},
+	} {
+		if err := s.Create(ctx, edit); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	newData := []byte("new binary")
+	if err := s.Update(ctx, &model.GetPars{ID: "1", UserID: userID}, &model.Edit{Data: &newData}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := s.Delete(ctx, &model.GetPars{ID: "2", UserID: userID}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if err := s.DeleteUserFiles(ctx, userID); err != nil {
+		t.Fatalf("DeleteUserFiles() error = %v", err)
+	}
+	if repoBlob.Len() != 1 {
+		t.Errorf("blob storage has %d files, want only the file of the other user", repoBlob.Len())
+	}
+	if err := s.DeleteUserFiles(ctx, ""); !errors.Is(err, errs.InvalidInput) {
+		t.Errorf("DeleteUserFiles() error = %v, want %v", err, errs.InvalidInput)
+	}
+}
+
+func TestService_Search(t *testing.T) {
+	ctx := context.Background()
+	userID := "999"
+	textType := model.TextDataType
+
+	s := New(newRepoDBMock(), nil)
+
+	work, err := s.CreateFolder(ctx, userID, "", "Work")
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	projects, err := s.CreateFolder(ctx, userID, work.ID, "Projects")
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	if _, err = s.CreateFolder(ctx, userID, "", "Work"); !errors.Is(err, errs.FolderAlreadyExists) {
+		t.Errorf("CreateFolder() error = %v, want %v", err, errs.FolderAlreadyExists)
+	}
+
+	create := func(id, name, folderID string, tags []string, labels map[string]string) {
+		data := []byte("secret")
+		err := s.Create(ctx, &model.Edit{
+			ID: id, UserID: &userID, Type: &textType, Data: &data,
+			Name: &name, FolderID: &folderID, Tags: &tags, Labels: &labels,
+		})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	create("1", "GitHub account", work.ID, []string{" Dev ", "dev", "Personal"}, map[string]string{"env": "prod"})
+	create("2", "Gitlab token", projects.ID, []string{"dev"}, map[string]string{"env": "staging"})
+	create("3", "Bank", "", nil, nil)
+
+	if got := s.repoDB.(*repoDBMock).items["1"].Tags; !reflect.DeepEqual(got, []string{"dev", "personal"}) {
+		t.Errorf("Create() tags = %v, want normalized tags", got)
+	}
+
+	tests := []struct {
+		name string
+		pars *model.SearchPars
+		want []string
+	}{
+		{name: "prefix query", pars: &model.SearchPars{Query: "git"}, want: []string{"1", "2"}},
+		{name: "query of several words", pars: &model.SearchPars{Query: "git acc"}, want: []string{"1"}},
+		{name: "query matching labels", pars: &model.SearchPars{Query: "stag"}, want: []string{"2"}},
+		{name: "tags", pars: &model.SearchPars{Tags: []string{"DEV", "personal"}}, want: []string{"1"}},
+		{name: "labels", pars: &model.SearchPars{Labels: map[string]string{"env": "staging"}}, want: []string{"2"}},
+		{name: "folder", pars: &model.SearchPars{FolderID: work.ID}, want: []string{"1"}},
+		{name: "folder with subfolders", pars: &model.SearchPars{FolderID: work.ID, Recursive: true}, want: []string{"1", "2"}},
+		{name: "everything", pars: &model.SearchPars{}, want: []string{"1", "2", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.pars.UserID = userID
+			items, err := s.Search(ctx, tt.pars)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			var got []string
+			for _, item := range items {
+				got = append(got, item.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	otherUserID := "1000"
+	if _, err = s.Search(ctx, &model.SearchPars{UserID: otherUserID, FolderID: work.ID}); !errors.Is(err, errs.FolderNotFound) {
+		t.Errorf("Search() in a folder of another user error = %v, want %v", err, errs.FolderNotFound)
+	}
+
+	folderID := work.ID
+	err = s.Update(ctx, &model.GetPars{ID: "3", UserID: otherUserID}, &model.Edit{FolderID: &folderID})
+	if !errors.Is(err, errs.NotFound) {
+		t.Errorf("Update() of another user error = %v, want %v", err, errs.NotFound)
+	}
+
+	longTag := []string{strings.Repeat("a", model.MaxTagLength+1)}
+	err = s.Update(ctx, &model.GetPars{ID: "3", UserID: userID}, &model.Edit{Tags: &longTag})
+	if !errors.Is(err, errs.InvalidInput) {
+		t.Errorf("Update() with a long tag error = %v, want %v", err, errs.InvalidInput)
+	}
+}
@@ -0,0 +1,54 @@
+// Package model defines the data structures for managing user sessions,
+// including session information, query parameters, and edit operations.
+package model
+
+import "time"
+
+// Session represents a login of the user. It holds the hash of the current refresh token,
+// the client's user agent, and timestamps for creation, last use, expiration and revocation.
+type Session struct {
+	ID               string
+	UserID           string
+	RefreshTokenHash string
+	UserAgent        string
+	CreatedAt        time.Time
+	LastUsedAt       time.Time
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+}
+
+// IsActive reports whether the session is neither revoked nor expired at the given time.
+func (m *Session) IsActive(now time.Time) bool {
+	return m.RevokedAt == nil && now.Before(m.ExpiresAt)
+}
+
+// GetPars defines parameters for querying a specific session, optionally restricted
+// to the sessions of a user or to the session holding the given refresh token hash.
+type GetPars struct {
+	ID               string
+	UserID           string
+	RefreshTokenHash string
+}
+
+// IsValid checks if the session ID is populated.
+func (m *GetPars) IsValid() bool {
+	return m.ID != ""
+}
+
+// ListPars defines parameters for listing sessions,
+// supporting filtering by user and by the time the sessions are active at.
+type ListPars struct {
+	UserID   *string
+	ActiveAt *time.Time
+}
+
+// Edit represents the editable fields of a session, allowing partial updates.
+type Edit struct {
+	ID               string
+	UserID           string
+	RefreshTokenHash *string
+	UserAgent        *string
+	LastUsedAt       *time.Time
+	ExpiresAt        *time.Time
+	RevokedAt        *time.Time
+}
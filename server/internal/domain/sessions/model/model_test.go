@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSession_IsActive(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		session *Session
+		want    bool
+	}{
+		{
+			name:    "active session",
+			session: &Session{ExpiresAt: now.Add(time.Hour)},
+			want:    true,
+		},
+		{
+			name:    "expired session",
+			session: &Session{ExpiresAt: now.Add(-time.Hour)},
+			want:    false,
+		},
+		{
+			name:    "revoked session",
+			session: &Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.IsActive(now); got != tt.want {
+				t.Errorf("IsActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Package pg provides a PostgreSQL-based implementation for managing user sessions,
+// including operations for retrieving, listing, creating, updating and revoking sessions.
+package pg
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gophKeeper/server/internal/domain/sessions/model"
+	"gophKeeper/server/internal/errs"
+	"time"
+)
+
+// Repo provides methods to interact with the PostgreSQL database for session operations.
+// It holds a connection pool to manage database interactions.
+type Repo struct {
+	Con *pgxpool.Pool
+}
+
+// New creates a new Repo instance with the given PostgreSQL connection pool.
+func New(con *pgxpool.Pool) *Repo {
+	return &Repo{
+		con,
+	}
+}
+
+var sessionColumns = []string{"id", "user_id", "refresh_token_hash", "user_agent", "created_at", "last_used_at", "expires_at", "revoked_at"}
+
+// Get retrieves a session based on the provided query parameters. It returns the session if found,
+// a boolean indicating the session's existence, and any error encountered.
+func (r *Repo) Get(ctx context.Context, pars *model.GetPars) (*model.Session, bool, error) {
+	if !pars.IsValid() {
+		return nil, false, errs.InvalidInput
+	}
+
+	var result model.Session
+
+	queryBuilder := squirrel.
+		Select(sessionColumns...).
+		From("sessions").
+		Where(squirrel.Eq{"id": pars.ID})
+
+	if len(pars.UserID) != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"user_id": pars.UserID})
+	}
+
+	if len(pars.RefreshTokenHash) != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"refresh_token_hash": pars.RefreshTokenHash})
+	}
+
+	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.ID, &result.UserID, &result.RefreshTokenHash, &result.UserAgent,
+		&result.CreatedAt, &result.LastUsedAt, &result.ExpiresAt, &result.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &result, true, nil
+}
+
+// List retrieves sessions based on the provided filtering parameters, ordered from the most recently used.
+func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.Session, error) {
+	queryBuilder := squirrel.
+		Select(sessionColumns...).
+		From("sessions").
+		Where(squirrel.Eq{"true": true})
+
+	if pars.UserID != nil {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"user_id": pars.UserID})
+	}
+
+	if pars.ActiveAt != nil {
+		queryBuilder = queryBuilder.
+			Where(squirrel.Eq{"revoked_at": nil}).
+			Where(squirrel.Gt{"expires_at": pars.ActiveAt})
+	}
+
+	queryBuilder = queryBuilder.OrderBy("last_used_at DESC")
+
+	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Con.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var result []*model.Session
+	for rows.Next() {
+		var session model.Session
+		err = rows.Scan(&session.ID, &session.UserID, &session.RefreshTokenHash, &session.UserAgent,
+			&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &session.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Create inserts a new session into the database based on the provided Edit object,
+// returning any error encountered during the operation.
+func (r *Repo) Create(ctx context.Context, obj *model.Edit) error {
+	insert := squirrel.Insert("sessions").
+		Columns("id", "user_id", "refresh_token_hash", "user_agent", "expires_at").
+		Values(obj.ID, obj.UserID, obj.RefreshTokenHash, obj.UserAgent, obj.ExpiresAt)
+
+	query, args, err := insert.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Con.Exec(ctx, query, args...)
+	return err
+}
+
+// Update modifies an existing session based on the provided query parameters and Edit object.
+// It returns whether a session was updated and any error encountered during the operation.
+func (r *Repo) Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) (bool, error) {
+	if !pars.IsValid() {
+		return false, errs.InvalidInput
+	}
+
+	queryBuilder := squirrel.Update("sessions")
+
+	if obj.RefreshTokenHash != nil {
+		queryBuilder = queryBuilder.Set("refresh_token_hash", obj.RefreshTokenHash)
+	}
+
+	if obj.UserAgent != nil {
+		queryBuilder = queryBuilder.Set("user_agent", obj.UserAgent)
+	}
+
+	if obj.LastUsedAt != nil {
+		queryBuilder = queryBuilder.Set("last_used_at", obj.LastUsedAt)
+	}
+
+	if obj.ExpiresAt != nil {
+		queryBuilder = queryBuilder.Set("expires_at", obj.ExpiresAt)
+	}
+
+	if obj.RevokedAt != nil {
+		queryBuilder = queryBuilder.Set("revoked_at", obj.RevokedAt)
+	}
+
+	queryBuilder = queryBuilder.Where(squirrel.Eq{"id": pars.ID})
+
+	if len(pars.UserID) != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"user_id": pars.UserID})
+	}
+
+	if len(pars.RefreshTokenHash) != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"refresh_token_hash": pars.RefreshTokenHash})
+	}
+
+	sql, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.Con.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokeAll revokes all the sessions of the user that are not revoked yet at the given time,
+// except the session with the exceptSessionID if it is not empty.
+func (r *Repo) RevokeAll(ctx context.Context, userID string, exceptSessionID string, revokedAt time.Time) error {
+	query := squirrel.Update("sessions").
+		Set("revoked_at", revokedAt).
+		Where(squirrel.Eq{"user_id": userID, "revoked_at": nil})
+	if exceptSessionID != "" {
+		query = query.Where(squirrel.NotEq{"id": exceptSessionID})
+	}
+
+	sql, args, err := query.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Con.Exec(ctx, sql, args...)
+	return err
+}
@@ -0,0 +1,220 @@
+// Package service implements the business logic for managing user sessions,
+// including issuing and rotating refresh tokens and revoking sessions.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"gophKeeper/server/internal/domain/sessions/model"
+	"gophKeeper/server/internal/errs"
+	"strings"
+	"time"
+)
+
+const (
+	refreshTokenSecretSize = 32
+	refreshTokenSeparator  = "."
+)
+
+// Service provides methods to manage user sessions. Every session holds the hash
+// of its current refresh token, the token itself is only returned to the client.
+type Service struct {
+	repoDB          RepoDBI
+	refreshTokenTTL time.Duration
+}
+
+// New creates a new Service instance with the given database repository and refresh token lifetime.
+func New(repoDB RepoDBI, refreshTokenTTL time.Duration) *Service {
+	return &Service{
+		repoDB:          repoDB,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// RepoDBI defines the interface for database interactions related to sessions.
+type RepoDBI interface {
+	Get(ctx context.Context, pars *model.GetPars) (*model.Session, bool, error)
+	List(ctx context.Context, pars *model.ListPars) ([]*model.Session, error)
+	Create(ctx context.Context, obj *model.Edit) error
+	Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) (bool, error)
+	RevokeAll(ctx context.Context, userID string, exceptSessionID string, revokedAt time.Time) error
+}
+
+// Create starts a new session of the user and returns it along with its refresh token.
+func (s *Service) Create(ctx context.Context, userID, userAgent string) (*model.Session, string, error) {
+	secret, err := newRefreshTokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := &model.Session{
+		ID:               uuid.New().String(),
+		UserID:           userID,
+		RefreshTokenHash: hashRefreshTokenSecret(secret),
+		UserAgent:        userAgent,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(s.refreshTokenTTL),
+	}
+
+	err = s.repoDB.Create(ctx, &model.Edit{
+		ID:               session.ID,
+		UserID:           session.UserID,
+		RefreshTokenHash: &session.RefreshTokenHash,
+		UserAgent:        &session.UserAgent,
+		ExpiresAt:        &session.ExpiresAt,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("create session in PostgreSQL - %w", err)
+	}
+
+	return session, formatRefreshToken(session.ID, secret), nil
+}
+
+// Rotate exchanges the refresh token for a new one, extending the session's lifetime.
+// Presenting a refresh token that was already rotated means it has leaked,
+// so the whole session is revoked and errs.InvalidToken is returned.
+func (s *Service) Rotate(ctx context.Context, refreshToken string) (*model.Session, string, error) {
+	sessionID, secret, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return nil, "", errs.InvalidToken
+	}
+
+	session, found, err := s.repoDB.Get(ctx, &model.GetPars{ID: sessionID})
+	if err != nil {
+		return nil, "", fmt.Errorf("get session from PostgreSQL - %w", err)
+	}
+
+	now := time.Now()
+	if !found || !session.IsActive(now) {
+		return nil, "", errs.InvalidToken
+	}
+
+	currentHash := hashRefreshTokenSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(currentHash), []byte(session.RefreshTokenHash)) != 1 {
+		if _, err = s.Revoke(ctx, &model.GetPars{ID: session.ID}); err != nil {
+			return nil, "", err
+		}
+		return nil, "", errs.InvalidToken
+	}
+
+	newSecret, err := newRefreshTokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	session.RefreshTokenHash = hashRefreshTokenSecret(newSecret)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.refreshTokenTTL)
+
+	// the current hash is part of the filter, so only one of concurrent rotations succeeds
+	updated, err := s.repoDB.Update(ctx, &model.GetPars{
+		ID:               session.ID,
+		RefreshTokenHash: currentHash,
+	}, &model.Edit{
+		RefreshTokenHash: &session.RefreshTokenHash,
+		LastUsedAt:       &session.LastUsedAt,
+		ExpiresAt:        &session.ExpiresAt,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("update session in PostgreSQL - %w", err)
+	}
+	if !updated {
+		return nil, "", errs.InvalidToken
+	}
+
+	return session, formatRefreshToken(session.ID, newSecret), nil
+}
+
+// IsActive checks whether the session exists and is neither revoked nor expired.
+func (s *Service) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	session, found, err := s.repoDB.Get(ctx, &model.GetPars{ID: sessionID})
+	if err != nil {
+		return false, fmt.Errorf("get session from PostgreSQL - %w", err)
+	}
+
+	return found && session.IsActive(time.Now()), nil
+}
+
+// List retrieves the active sessions of the user.
+func (s *Service) List(ctx context.Context, userID string) ([]*model.Session, error) {
+	now := time.Now()
+
+	sessions, err := s.repoDB.List(ctx, &model.ListPars{
+		UserID:   &userID,
+		ActiveAt: &now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list sessions from PostgreSQL - %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Revoke revokes the session matching the provided parameters, invalidating its tokens.
+// It returns whether a session was found.
+func (s *Service) Revoke(ctx context.Context, pars *model.GetPars) (bool, error) {
+	now := time.Now()
+
+	found, err := s.repoDB.Update(ctx, pars, &model.Edit{
+		RevokedAt: &now,
+	})
+	if err != nil {
+		return false, fmt.Errorf("revoke session in PostgreSQL - %w", err)
+	}
+
+	return found, nil
+}
+
+// RevokeAll revokes all the sessions of the user but the excepted one, invalidating their tokens.
+// An empty exceptSessionID revokes every session.
+func (s *Service) RevokeAll(ctx context.Context, userID string, exceptSessionID string) error {
+	if userID == "" {
+		return errs.InvalidInput
+	}
+
+	err := s.repoDB.RevokeAll(ctx, userID, exceptSessionID, time.Now())
+	if err != nil {
+		return fmt.Errorf("revoke sessions in PostgreSQL - %w", err)
+	}
+
+	return nil
+}
+
+// newRefreshTokenSecret generates the random secret part of a refresh token.
+func newRefreshTokenSecret() (string, error) {
+	secret := make([]byte, refreshTokenSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("can not generate refresh token - %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashRefreshTokenSecret returns the hash of the refresh token secret stored in the database.
+func hashRefreshTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatRefreshToken builds the refresh token from the session ID and the secret.
+func formatRefreshToken(sessionID, secret string) string {
+	return sessionID + refreshTokenSeparator + secret
+}
+
+// parseRefreshToken splits the refresh token into the session ID and the secret.
+func parseRefreshToken(refreshToken string) (string, string, bool) {
+	sessionID, secret, found := strings.Cut(refreshToken, refreshTokenSeparator)
+	if !found || sessionID == "" || secret == "" {
+		return "", "", false
+	}
+
+	return sessionID, secret, true
+}
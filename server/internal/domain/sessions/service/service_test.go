@@ -0,0 +1,68 @@
+package service
+
+import "testing"
+
+func Test_parseRefreshToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		refreshToken  string
+		wantSessionID string
+		wantSecret    string
+		wantOk        bool
+	}{
+		{
+			name:          "valid token",
+			refreshToken:  formatRefreshToken("session", "secret"),
+			wantSessionID: "session",
+			wantSecret:    "secret",
+			wantOk:        true,
+		},
+		{
+			name:         "token without separator",
+			refreshToken: "sessionsecret",
+			wantOk:       false,
+		},
+		{
+			name:         "token without secret",
+			refreshToken: "session.",
+			wantOk:       false,
+		},
+		{
+			name:         "empty token",
+			refreshToken: "",
+			wantOk:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSessionID, gotSecret, gotOk := parseRefreshToken(tt.refreshToken)
+			if gotOk != tt.wantOk {
+				t.Errorf("parseRefreshToken() ok = %v, want %v", gotOk, tt.wantOk)
+			}
+			if gotSessionID != tt.wantSessionID {
+				t.Errorf("parseRefreshToken() sessionID = %v, want %v", gotSessionID, tt.wantSessionID)
+			}
+			if gotSecret != tt.wantSecret {
+				t.Errorf("parseRefreshToken() secret = %v, want %v", gotSecret, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func Test_newRefreshTokenSecret(t *testing.T) {
+	first, err := newRefreshTokenSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newRefreshTokenSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Errorf("newRefreshTokenSecret() produced the same secret twice")
+	}
+	if hashRefreshTokenSecret(first) == hashRefreshTokenSecret(second) {
+		t.Errorf("hashRefreshTokenSecret() produced the same hash for different secrets")
+	}
+}
@@ -0,0 +1,33 @@
+// Package model defines the data structures for two-factor authentication,
+// including the TOTP secrets of users, enrollments and pending login challenges.
+package model
+
+import "time"
+
+// Totp represents the TOTP secret of a user. The secret is pending until the user
+// confirms it with a code, the last accepted time step prevents replaying a code.
+type Totp struct {
+	UserID      string
+	Secret      []byte
+	Enabled     bool
+	LastStep    int64
+	CreatedAt   time.Time
+	ConfirmedAt *time.Time
+}
+
+// Enrollment holds what the user needs to add a new TOTP secret to an authenticator app:
+// the base32 encoded secret, the otpauth provisioning URI and the URI rendered as a QR code PNG.
+type Enrollment struct {
+	Secret          string
+	ProvisioningURI string
+	QRCode          []byte
+}
+
+// Challenge is the pending second step of a login of a user with two-factor authentication.
+// Only the hash of the challenge token is stored, the token itself is returned to the client.
+type Challenge struct {
+	ID        string
+	UserID    string
+	Attempts  int
+	ExpiresAt time.Time
+}
@@ -0,0 +1,314 @@
+// Package pg provides a PostgreSQL-based implementation for two-factor authentication,
+// storing the TOTP secrets, the hashes of the recovery codes and the pending login challenges.
+package pg
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gophKeeper/server/internal/domain/twofactor/model"
+	"time"
+)
+
+// Repo provides methods to interact with the PostgreSQL database for two-factor authentication.
+// It holds a connection pool to manage database interactions.
+type Repo struct {
+	Con *pgxpool.Pool
+}
+
+// New creates a new Repo instance with the given PostgreSQL connection pool.
+func New(con *pgxpool.Pool) *Repo {
+	return &Repo{
+		con,
+	}
+}
+
+// GetTotp retrieves the TOTP secret of the user. It returns the secret if found,
+// a boolean indicating its existence, and any error encountered.
+func (r *Repo) GetTotp(ctx context.Context, userID string) (*model.Totp, bool, error) {
+	sql, args, err := squirrel.
+		Select("user_id", "secret", "enabled", "last_step", "created_at", "confirmed_at").
+		From("user_totp").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result model.Totp
+	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.UserID, &result.Secret, &result.Enabled, &result.LastStep,
+		&result.CreatedAt, &result.ConfirmedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &result, true, nil
+}
+
+// SaveTotp stores a pending TOTP secret of the user, replacing the previous pending one.
+// An enabled secret is never replaced, the returned boolean reports whether the secret was stored.
+func (r *Repo) SaveTotp(ctx context.Context, obj *model.Totp) (bool, error) {
+	sql, args, err := squirrel.Insert("user_totp").
+		Columns("user_id", "secret", "enabled", "last_step", "created_at").
+		Values(obj.UserID, obj.Secret, false, 0, obj.CreatedAt).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_step = 0, " +
+			"created_at = EXCLUDED.created_at, confirmed_at = NULL WHERE user_totp.enabled = FALSE").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.Con.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// EnableTotp enables the pending TOTP secret of the user, remembering the time step of the code
+// it was confirmed with, and replaces the recovery codes of the user with the given hashes.
+// It returns false if the user has no pending secret.
+func (r *Repo) EnableTotp(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) (found bool, err error) {
+	tx, err := r.Con.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer r.handleTxCompletion(tx, &err)
+
+	sql, args, err := squirrel.Update("user_totp").
+		Set("enabled", true).
+		Set("last_step", step).
+		Set("confirmed_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID, "enabled": false}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil || tag.RowsAffected() == 0 {
+		return false, err
+	}
+
+	if err = replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UpdateTotpLastStep moves the last accepted time step of the enabled TOTP secret forward.
+// It returns false if the step was already used, so every code is accepted only once
+// even by concurrent requests.
+func (r *Repo) UpdateTotpLastStep(ctx context.Context, userID string, step int64) (bool, error) {
+	sql, args, err := squirrel.Update("user_totp").
+		Set("last_step", step).
+		Where(squirrel.Eq{"user_id": userID, "enabled": true}).
+		Where(squirrel.Lt{"last_step": step}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.Con.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteTotp removes the TOTP secret and the recovery codes of the user.
+func (r *Repo) DeleteTotp(ctx context.Context, userID string) (err error) {
+	tx, err := r.Con.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer r.handleTxCompletion(tx, &err)
+
+	sql, args, err := squirrel.Delete("user_totp").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return replaceRecoveryCodes(ctx, tx, userID, nil)
+}
+
+// UseRecoveryCode marks the unused recovery code of the user with the given hash as used.
+// It returns false if there is no such unused code.
+func (r *Repo) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	sql, args, err := squirrel.Update("recovery_codes").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.Con.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// CreateChallenge stores a pending login challenge, removing the expired ones.
+func (r *Repo) CreateChallenge(ctx context.Context, obj *model.Challenge) error {
+	sql, args, err := squirrel.Delete("login_challenges").
+		Where(squirrel.Lt{"expires_at": time.Now()}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.Con.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	sql, args, err = squirrel.Insert("login_challenges").
+		Columns("id", "user_id", "attempts", "expires_at").
+		Values(obj.ID, obj.UserID, obj.Attempts, obj.ExpiresAt).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Con.Exec(ctx, sql, args...)
+	return err
+}
+
+// GetChallenge retrieves the unexpired login challenge. It returns the challenge if found,
+// a boolean indicating its existence, and any error encountered.
+func (r *Repo) GetChallenge(ctx context.Context, id string) (*model.Challenge, bool, error) {
+	sql, args, err := squirrel.Select("id", "user_id", "attempts", "expires_at").
+		From("login_challenges").
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Gt{"expires_at": time.Now()}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result model.Challenge
+	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.ID, &result.UserID, &result.Attempts, &result.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &result, true, nil
+}
+
+// AddChallengeAttempt counts a verification attempt of the unexpired login challenge
+// and returns the challenge with the updated number of attempts. It returns the challenge
+// if found, a boolean indicating its existence, and any error encountered.
+func (r *Repo) AddChallengeAttempt(ctx context.Context, id string) (*model.Challenge, bool, error) {
+	sql, args, err := squirrel.Update("login_challenges").
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Gt{"expires_at": time.Now()}).
+		Suffix("RETURNING id, user_id, attempts, expires_at").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result model.Challenge
+	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.ID, &result.UserID, &result.Attempts, &result.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &result, true, nil
+}
+
+// DeleteChallenge removes the login challenge. It returns false if the challenge
+// was already removed, so every challenge can be completed only once.
+func (r *Repo) DeleteChallenge(ctx context.Context, id string) (bool, error) {
+	sql, args, err := squirrel.Delete("login_challenges").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.Con.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// replaceRecoveryCodes removes the recovery codes of the user and inserts the given hashes instead.
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, codeHashes []string) error {
+	sql, args, err := squirrel.Delete("recovery_codes").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	insert := squirrel.Insert("recovery_codes").Columns("user_id", "code_hash")
+	for _, codeHash := range codeHashes {
+		insert = insert.Values(userID, codeHash)
+	}
+
+	sql, args, err = insert.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// handleTxCompletion commits the transaction if the function succeeded and rolls it back otherwise.
+func (r *Repo) handleTxCompletion(tx pgx.Tx, err *error) {
+	if p := recover(); p != nil {
+		_ = tx.Rollback(context.Background())
+		panic(p)
+	} else if *err != nil {
+		_ = tx.Rollback(context.Background())
+	} else {
+		*err = tx.Commit(context.Background())
+	}
+}
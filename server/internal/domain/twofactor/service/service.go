@@ -0,0 +1,320 @@
+// Package service implements the business logic of two-factor authentication: enrolling
+// and confirming TOTP secrets, one-time recovery codes and the second step of logins.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"gophKeeper/pkg/totp"
+	"gophKeeper/server/internal/domain/twofactor/model"
+	"gophKeeper/server/internal/errs"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+	challengeTokenSize   = 32
+	challengeTTL         = 5 * time.Minute
+	maxChallengeAttempts = 5
+	qrCodeSize           = 256
+)
+
+// Service provides methods to manage the two-factor authentication of users. Only the hashes
+// of the recovery codes and of the challenge tokens are stored, the codes and tokens themselves
+// are only returned to the client.
+type Service struct {
+	repoDB RepoDBI
+	issuer string
+}
+
+// New creates a new Service instance with the given database repository. The issuer names
+// the service in the authenticator apps.
+func New(repoDB RepoDBI, issuer string) *Service {
+	return &Service{
+		repoDB: repoDB,
+		issuer: issuer,
+	}
+}
+
+// RepoDBI defines the interface for database interactions related to two-factor authentication.
+type RepoDBI interface {
+	GetTotp(ctx context.Context, userID string) (*model.Totp, bool, error)
+	SaveTotp(ctx context.Context, obj *model.Totp) (bool, error)
+	EnableTotp(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) (bool, error)
+	UpdateTotpLastStep(ctx context.Context, userID string, step int64) (bool, error)
+	DeleteTotp(ctx context.Context, userID string) error
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+	CreateChallenge(ctx context.Context, obj *model.Challenge) error
+	GetChallenge(ctx context.Context, id string) (*model.Challenge, bool, error)
+	AddChallengeAttempt(ctx context.Context, id string) (*model.Challenge, bool, error)
+	DeleteChallenge(ctx context.Context, id string) (bool, error)
+}
+
+// Enroll generates a new pending TOTP secret of the user, replacing the previous pending one.
+// Two-factor authentication is enabled only once the secret is confirmed with a code.
+// The account names the secret in the authenticator apps.
+func (s *Service) Enroll(ctx context.Context, userID string, account string) (*model.Enrollment, error) {
+	current, found, err := s.repoDB.GetTotp(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get totp from PostgreSQL - %w", err)
+	}
+	if found && current.Enabled {
+		return nil, errs.TwoFactorEnabled
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.repoDB.SaveTotp(ctx, &model.Totp{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save totp in PostgreSQL - %w", err)
+	}
+	if !saved {
+		return nil, errs.TwoFactorEnabled
+	}
+
+	uri := totp.ProvisioningURI(s.issuer, account, secret)
+
+	png, err := qrcode.Encode(uri, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("can not encode provisioning uri as qr code - %w", err)
+	}
+
+	return &model.Enrollment{
+		Secret:          totp.EncodeSecret(secret),
+		ProvisioningURI: uri,
+		QRCode:          png,
+	}, nil
+}
+
+// Confirm enables the pending TOTP secret of the user once the code generated from it is valid,
+// and returns a new set of one-time recovery codes.
+func (s *Service) Confirm(ctx context.Context, userID string, code string) ([]string, error) {
+	current, found, err := s.repoDB.GetTotp(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get totp from PostgreSQL - %w", err)
+	}
+	if !found {
+		return nil, errs.TwoFactorNotEnrolled
+	}
+	if current.Enabled {
+		return nil, errs.TwoFactorEnabled
+	}
+
+	step, ok := totp.Validate(current.Secret, code, time.Now(), current.LastStep)
+	if !ok {
+		return nil, errs.InvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.repoDB.EnableTotp(ctx, userID, step, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("enable totp in PostgreSQL - %w", err)
+	}
+	if !enabled {
+		return nil, errs.TwoFactorEnabled
+	}
+
+	return codes, nil
+}
+
+// Disable turns off the two-factor authentication of the user, removing the TOTP secret
+// and the recovery codes. It requires a valid TOTP or unused recovery code.
+func (s *Service) Disable(ctx context.Context, userID string, code string) error {
+	current, found, err := s.repoDB.GetTotp(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get totp from PostgreSQL - %w", err)
+	}
+	if !found || !current.Enabled {
+		return errs.TwoFactorNotEnabled
+	}
+
+	valid, err := s.verifyCode(ctx, current, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errs.InvalidTwoFactorCode
+	}
+
+	if err = s.repoDB.DeleteTotp(ctx, userID); err != nil {
+		return fmt.Errorf("delete totp from PostgreSQL - %w", err)
+	}
+
+	return nil
+}
+
+// IsEnabled reports whether the user has confirmed a TOTP secret.
+func (s *Service) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	current, found, err := s.repoDB.GetTotp(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("get totp from PostgreSQL - %w", err)
+	}
+
+	return found && current.Enabled, nil
+}
+
+// CreateChallenge starts the second step of a login of the user. It returns the challenge token
+// the client sends back along with the code, and the time the challenge expires at.
+func (s *Service) CreateChallenge(ctx context.Context, userID string) (string, time.Time, error) {
+	secret := make([]byte, challengeTokenSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", time.Time{}, fmt.Errorf("can not generate challenge token - %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	challenge := &model.Challenge{
+		ID:        hash(token),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(challengeTTL),
+	}
+
+	if err := s.repoDB.CreateChallenge(ctx, challenge); err != nil {
+		return "", time.Time{}, fmt.Errorf("create challenge in PostgreSQL - %w", err)
+	}
+
+	return token, challenge.ExpiresAt, nil
+}
+
+// ChallengeUserID returns the ID of the user the login challenge belongs to, a boolean indicating
+// whether the challenge is pending, and any error encountered.
+func (s *Service) ChallengeUserID(ctx context.Context, token string) (string, bool, error) {
+	challenge, found, err := s.repoDB.GetChallenge(ctx, hash(token))
+	if err != nil {
+		return "", false, fmt.Errorf("get challenge from PostgreSQL - %w", err)
+	}
+	if !found {
+		return "", false, nil
+	}
+
+	return challenge.UserID, true, nil
+}
+
+// VerifyChallenge checks the TOTP or recovery code sent for the login challenge and returns the ID
+// of the user logging in. A challenge is completed only once and is dropped after too many wrong
+// codes, errs.ChallengeNotFound is returned for unknown, expired or dropped challenges.
+func (s *Service) VerifyChallenge(ctx context.Context, token string, code string) (string, error) {
+	id := hash(token)
+
+	challenge, found, err := s.repoDB.AddChallengeAttempt(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("update challenge in PostgreSQL - %w", err)
+	}
+	if !found {
+		return "", errs.ChallengeNotFound
+	}
+
+	current, found, err := s.repoDB.GetTotp(ctx, challenge.UserID)
+	if err != nil {
+		return "", fmt.Errorf("get totp from PostgreSQL - %w", err)
+	}
+
+	if challenge.Attempts > maxChallengeAttempts || !found || !current.Enabled {
+		if _, err = s.repoDB.DeleteChallenge(ctx, id); err != nil {
+			return "", fmt.Errorf("delete challenge from PostgreSQL - %w", err)
+		}
+		return "", errs.ChallengeNotFound
+	}
+
+	valid, err := s.verifyCode(ctx, current, code)
+	if err != nil {
+		return "", err
+	}
+	if !valid {
+		return "", errs.InvalidTwoFactorCode
+	}
+
+	// only one of concurrent verifications removes the challenge
+	deleted, err := s.repoDB.DeleteChallenge(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("delete challenge from PostgreSQL - %w", err)
+	}
+	if !deleted {
+		return "", errs.ChallengeNotFound
+	}
+
+	return challenge.UserID, nil
+}
+
+// verifyCode checks the code against the enabled TOTP secret, or against the unused recovery codes
+// if it is not a TOTP code. Accepted TOTP codes and recovery codes can not be used again.
+func (s *Service) verifyCode(ctx context.Context, current *model.Totp, code string) (bool, error) {
+	code = normalizeCode(code)
+
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(current.Secret, code, time.Now(), current.LastStep)
+		if !ok {
+			return false, nil
+		}
+
+		updated, err := s.repoDB.UpdateTotpLastStep(ctx, current.UserID, step)
+		if err != nil {
+			return false, fmt.Errorf("update totp in PostgreSQL - %w", err)
+		}
+		return updated, nil
+	}
+
+	if len(code) != recoveryCodeLength {
+		return false, nil
+	}
+
+	used, err := s.repoDB.UseRecoveryCode(ctx, current.UserID, hash(code))
+	if err != nil {
+		return false, fmt.Errorf("use recovery code in PostgreSQL - %w", err)
+	}
+
+	return used, nil
+}
+
+// newRecoveryCodes generates the recovery codes shown to the user and the hashes stored instead of them.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		random := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, fmt.Errorf("can not generate recovery code - %w", err)
+		}
+
+		// the alphabet has 32 letters, so every byte maps to a letter uniformly
+		code := make([]byte, recoveryCodeLength)
+		for j, b := range random {
+			code[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+
+		codes[i] = string(code[:recoveryCodeLength/2]) + "-" + string(code[recoveryCodeLength/2:])
+		hashes[i] = hash(string(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeCode removes the separators and spaces users type along with the codes.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// hash returns the hex encoded SHA-256 hash stored instead of a recovery code or challenge token.
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
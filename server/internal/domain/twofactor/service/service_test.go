@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gophKeeper/pkg/totp"
+	"gophKeeper/server/internal/domain/twofactor/model"
+	"gophKeeper/server/internal/errs"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_Enroll(t *testing.T) {
+	ctx := context.Background()
+	s := New(newRepoDBMock(), "GophKeeper")
+
+	enrollment, err := s.Enroll(ctx, "1", "alice")
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	if !strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/GophKeeper:alice?") {
+		t.Errorf("Enroll() uri = %v", enrollment.ProvisioningURI)
+	}
+	if !strings.HasPrefix(string(enrollment.QRCode), "\x89PNG") {
+		t.Errorf("Enroll() qr code is not a PNG")
+	}
+
+	secret, err := totp.DecodeSecret(enrollment.Secret)
+	if err != nil {
+		t.Fatalf("DecodeSecret() error = %v", err)
+	}
+
+	if enabled, _ := s.IsEnabled(ctx, "1"); enabled {
+		t.Errorf("IsEnabled() = true before the confirmation")
+	}
+
+	if _, err = s.Confirm(ctx, "1", "abcdef"); !errors.Is(err, errs.InvalidTwoFactorCode) {
+		t.Errorf("Confirm() error = %v, want %v", err, errs.InvalidTwoFactorCode)
+	}
+
+	codes, err := s.Confirm(ctx, "1", totp.Code(secret, totp.Step(time.Now())))
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("Confirm() returned %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	if enabled, _ := s.IsEnabled(ctx, "1"); !enabled {
+		t.Errorf("IsEnabled() = false after the confirmation")
+	}
+	if _, err = s.Enroll(ctx, "1", "alice"); !errors.Is(err, errs.TwoFactorEnabled) {
+		t.Errorf("Enroll() error = %v, want %v", err, errs.TwoFactorEnabled)
+	}
+	if _, err = s.Confirm(ctx, "2", "123456"); !errors.Is(err, errs.TwoFactorNotEnrolled) {
+		t.Errorf("Confirm() error = %v, want %v", err, errs.TwoFactorNotEnrolled)
+	}
+}
+
+func TestService_VerifyChallenge(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("12345678901234567890")
+	step := totp.Step(time.Now())
+
+	repo := newRepoDBMock()
+	s := New(repo, "GophKeeper")
+
+	repo.totp["1"] = &model.Totp{UserID: "1", Secret: secret, Enabled: true, LastStep: step - 2}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatalf("newRecoveryCodes() error = %v", err)
+	}
+	for _, h := range hashes {
+		repo.recoveryCodes[h] = false
+	}
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{name: "valid totp code", code: totp.Code(secret, step)},
+		{name: "replayed totp code", code: totp.Code(secret, step), wantErr: errs.InvalidTwoFactorCode},
+		{name: "older totp code", code: totp.Code(secret, step-1), wantErr: errs.InvalidTwoFactorCode},
+		{name: "recovery code", code: strings.ToUpper(codes[0])},
+		{name: "used recovery code", code: codes[0], wantErr: errs.InvalidTwoFactorCode},
+		{name: "recovery code without separator", code: strings.ReplaceAll(codes[1], "-", "")},
+		{name: "unknown recovery code", code: "aaaaa-aaaaa", wantErr: errs.InvalidTwoFactorCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := s.CreateChallenge(ctx, "1")
+			if err != nil {
+				t.Fatalf("CreateChallenge() error = %v", err)
+			}
+
+			userID, err := s.VerifyChallenge(ctx, token, tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyChallenge() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if userID != "1" {
+				t.Errorf("VerifyChallenge() userID = %v, want 1", userID)
+			}
+			if _, err = s.VerifyChallenge(ctx, token, tt.code); !errors.Is(err, errs.ChallengeNotFound) {
+				t.Errorf("VerifyChallenge() of a completed challenge error = %v, want %v", err, errs.ChallengeNotFound)
+			}
+		})
+	}
+
+	t.Run("too many attempts", func(t *testing.T) {
+		token, _, _ := s.CreateChallenge(ctx, "1")
+		for i := 0; i < maxChallengeAttempts; i++ {
+			if _, err := s.VerifyChallenge(ctx, token, "00000a"); !errors.Is(err, errs.InvalidTwoFactorCode) {
+				t.Fatalf("VerifyChallenge() error = %v, want %v", err, errs.InvalidTwoFactorCode)
+			}
+		}
+		if _, err := s.VerifyChallenge(ctx, token, totp.Code(secret, step+1)); !errors.Is(err, errs.ChallengeNotFound) {
+			t.Errorf("VerifyChallenge() error = %v, want %v", err, errs.ChallengeNotFound)
+		}
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		if err := s.Disable(ctx, "1", codes[2]); err != nil {
+			t.Fatalf("Disable() error = %v", err)
+		}
+		if enabled, _ := s.IsEnabled(ctx, "1"); enabled {
+			t.Errorf("IsEnabled() = true after Disable()")
+		}
+		if err := s.Disable(ctx, "1", codes[3]); !errors.Is(err, errs.TwoFactorNotEnabled) {
+			t.Errorf("Disable() error = %v, want %v", err, errs.TwoFactorNotEnabled)
+		}
+	})
+}
+
+type repoDBMock struct {
+	totp          map[string]*model.Totp
+	recoveryCodes map[string]bool
+	challenges    map[string]*model.Challenge
+}
+
+func newRepoDBMock() *repoDBMock {
+	return &repoDBMock{
+		totp:          map[string]*model.Totp{},
+		recoveryCodes: map[string]bool{},
+		challenges:    map[string]*model.Challenge{},
+	}
+}
+
+func (m *repoDBMock) GetTotp(_ context.Context, userID string) (*model.Totp, bool, error) {
+	obj, found := m.totp[userID]
+	return obj, found, nil
+}
+
+func (m *repoDBMock) SaveTotp(_ context.Context, obj *model.Totp) (bool, error) {
+	if current, found := m.totp[obj.UserID]; found && current.Enabled {
+		return false, nil
+	}
+	m.totp[obj.UserID] = obj
+	return true, nil
+}
+
+func (m *repoDBMock) EnableTotp(_ context.Context, userID string, step int64, recoveryCodeHashes []string) (bool, error) {
+	current, found := m.totp[userID]
+	if !found || current.Enabled {
+		return false, nil
+	}
+	current.Enabled = true
+	current.LastStep = step
+	m.recoveryCodes = map[string]bool{}
+	for _, h := range recoveryCodeHashes {
+		m.recoveryCodes[h] = false
+	}
+	return true, nil
+}
+
+func (m *repoDBMock) UpdateTotpLastStep(_ context.Context, userID string, step int64) (bool, error) {
+	current, found := m.totp[userID]
+	if !found || !current.Enabled || current.LastStep >= step {
+		return false, nil
+	}
+	current.LastStep = step
+	return true, nil
+}
+
+func (m *repoDBMock) DeleteTotp(_ context.Context, userID string) error {
+	delete(m.totp, userID)
+	m.recoveryCodes = map[string]bool{}
+	return nil
+}
+
+func (m *repoDBMock) UseRecoveryCode(_ context.Context, _ string, codeHash string) (bool, error) {
+	used, found := m.recoveryCodes[codeHash]
+	if !found || used {
+		return false, nil
+	}
+	m.recoveryCodes[codeHash] = true
+	return true, nil
+}
+
+func (m *repoDBMock) CreateChallenge(_ context.Context, obj *model.Challenge) error {
+	m.challenges[obj.ID] = obj
+	return nil
+}
+
+func (m *repoDBMock) GetChallenge(_ context.Context, id string) (*model.Challenge, bool, error) {
+	challenge, found := m.challenges[id]
+	if !found || !challenge.ExpiresAt.After(time.Now()) {
+		return nil, false, nil
+	}
+	return challenge, true, nil
+}
+
+func (m *repoDBMock) AddChallengeAttempt(_ context.Context, id string) (*model.Challenge, bool, error) {
+	challenge, found := m.challenges[id]
+	if !found || !challenge.ExpiresAt.After(time.Now()) {
+		return nil, false, nil
+	}
+	challenge.Attempts++
+	return challenge, true, nil
+}
+
+func (m *repoDBMock) DeleteChallenge(_ context.Context, id string) (bool, error) {
+	_, found := m.challenges[id]
+	delete(m.challenges, id)
+	return found, nil
+}
@@ -5,15 +5,38 @@ package model
 import "time"
 
 // User represents the core user entity, storing user identification details,
-// username, password hash, and timestamps for record creation and updates.
+// username, password hash, the salt used by clients to derive their vault key,
+// the SRP salt and verifier, the key check the clients verify their vault key with,
+// the failed logins since the last successful one, the time the account is locked until,
+// and timestamps for record creation and updates. Accounts using SRP have no password hash.
 type User struct {
 	UserID       string
 	Username     string
 	PasswordHash string
+	KdfSalt      []byte
+	SrpSalt      []byte
+	SrpVerifier  []byte
+	KeyCheck     []byte
+	FailedLogins int
+	LockedUntil  *time.Time
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
 
+// UsesSrp reports whether the user logs in with SRP instead of sending the password.
+func (u *User) UsesSrp() bool {
+	return len(u.SrpVerifier) > 0
+}
+
+// LockedFor returns how long the account stays locked after too many failed logins,
+// or zero if it is not locked at the given time.
+func (u *User) LockedFor(now time.Time) time.Duration {
+	if u.LockedUntil == nil || !now.Before(*u.LockedUntil) {
+		return 0
+	}
+	return u.LockedUntil.Sub(now)
+}
+
 // GetPars defines parameters for querying specific user records,
 // allowing filtering by UserID or Username.
 type GetPars struct {
@@ -40,10 +63,65 @@ type ListPars struct {
 
 // Edit represents the editable fields for updating an existing user record,
 // allowing partial updates to fields like Username, PasswordHash, and timestamps.
+// An empty PasswordHash removes the password hash.
 type Edit struct {
 	UserID       string
 	Username     *string
 	PasswordHash *string
+	KdfSalt      *[]byte
+	SrpSalt      *[]byte
+	SrpVerifier  *[]byte
 	CreatedAt    *time.Time
 	UpdatedAt    *time.Time
 }
+
+// Tokens represents the token pair issued for a session: a short-lived access token
+// with its expiration time and a refresh token used to obtain the next pair.
+type Tokens struct {
+	Token        string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// LoginResult represents the outcome of a successful login, holding the issued tokens,
+// the salt the client uses to derive the vault encryption key and the key check sealed
+// with that key, empty until the first client stores it. SRP logins also
+// hold the server proof M2, which the client checks to authenticate the server.
+// For users with two-factor authentication only the challenge token of the second step
+// and its expiration are set, the tokens are issued once the challenge is completed.
+type LoginResult struct {
+	Tokens
+	KdfSalt            []byte
+	KeyCheck           []byte
+	ServerProof        []byte
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
+}
+
+// PasswordProof re-verifies the password of a logged in user before sensitive changes of the account.
+// Accounts using SRP prove it with the ID and the client proof of a pending SRP login,
+// accounts registered with a password send the password itself.
+type PasswordProof struct {
+	LoginID     string
+	ClientProof []byte
+	Password    string
+}
+
+// SrpChallenge is the server's answer to the first step of an SRP login: the ID of the pending
+// handshake, the SRP salt of the user and the public ephemeral value B. Accounts registered with
+// a password have no SRP verifier to start a handshake with, they get PasswordLogin set instead.
+type SrpChallenge struct {
+	LoginID       string
+	Salt          []byte
+	ServerPublic  []byte
+	PasswordLogin bool
+}
+
+// SrpHandshake is the state of a pending SRP login kept by the server between its two steps.
+type SrpHandshake struct {
+	ID           string
+	UserID       string
+	ServerSecret []byte
+	ClientPublic []byte
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,53 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// RecordFailedLogin counts a failed login of the user. Once the count reaches the threshold,
+// the account is locked until lockedUntil and the count starts over. The count is updated
+// in a single statement, so concurrent failures are all counted. It returns the time
+// the account is locked until, nil if it is not locked.
+func (r *Repo) RecordFailedLogin(ctx context.Context, userID string, threshold int, lockedUntil time.Time) (*time.Time, error) {
+	sql, args, err := squirrel.Update("users").
+		Set("locked_until", squirrel.Expr("CASE WHEN failed_logins + 1 >= ? THEN ?::timestamptz ELSE locked_until END", threshold, lockedUntil)).
+		Set("failed_logins", squirrel.Expr("CASE WHEN failed_logins + 1 >= ? THEN 0 ELSE failed_logins + 1 END", threshold)).
+		Where(squirrel.Eq{"id": userID}).
+		Suffix("RETURNING locked_until").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *time.Time
+	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ResetFailedLogins forgets the failed logins of the user and lifts the lock of the account.
+func (r *Repo) ResetFailedLogins(ctx context.Context, userID string) error {
+	sql, args, err := squirrel.Update("users").
+		Set("failed_logins", 0).
+		Set("locked_until", nil).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Con.Exec(ctx, sql, args...)
+	return err
+}
@@ -25,6 +25,10 @@ func New(con *pgxpool.Pool) *Repo {
 	}
 }
 
+// userColumns lists the columns of a user in the order they are scanned.
+var userColumns = []string{"id", "username", "COALESCE(password_hash, '')", "kdf_salt", "srp_salt", "srp_verifier", "key_check", "failed_logins", "locked_until",
+	"created_at", "updated_at"}
+
 // Get retrieves a user based on the provided query parameters. It returns the user if found,
 // a boolean indicating the user's existence, and any error encountered.
 func (r *Repo) Get(ctx context.Context, pars *model.GetPars) (*model.User, bool, error) {
@@ -34,7 +38,9 @@ func (r *Repo) Get(ctx context.Context, pars *model.GetPars) (*model.User, bool,
 
 	var result model.User
 
-	queryBuilder := squirrel.Select("*").From("users")
+	queryBuilder := squirrel.
+		Select(userColumns...).
+		From("users")
 
 	if len(pars.UserID) != 0 {
 		queryBuilder = queryBuilder.Where(squirrel.Eq{"id": pars.UserID})
@@ -51,7 +57,8 @@ func (r *Repo) Get(ctx context.Context, pars *model.GetPars) (*model.User, bool,
 		return nil, false, err
 	}
 
-	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.UserID, &result.Username, &result.PasswordHash, &result.CreatedAt, &result.UpdatedAt)
+	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.UserID, &result.Username, &result.PasswordHash, &result.KdfSalt,
+		&result.SrpSalt, &result.SrpVerifier, &result.KeyCheck, &result.FailedLogins, &result.LockedUntil, &result.CreatedAt, &result.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, false, nil
@@ -67,7 +74,7 @@ func (r *Repo) Get(ctx context.Context, pars *model.GetPars) (*model.User, bool,
 // of users, the total count, and any error encountered.
 func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.User, int64, error) {
 	queryBuilder := squirrel.
-		Select("id", "username", "password_hash", "created_at", "updated_at").
+		Select(userColumns...).
 		From("users").
 		Where(squirrel.Eq{"true": true})
 
@@ -114,7 +121,8 @@ func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.User, i
 	var result []*model.User
 	for rows.Next() {
 		var user model.User
-		err = rows.Scan(&user.UserID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+		err = rows.Scan(&user.UserID, &user.Username, &user.PasswordHash, &user.KdfSalt,
+			&user.SrpSalt, &user.SrpVerifier, &user.KeyCheck, &user.FailedLogins, &user.LockedUntil, &user.CreatedAt, &user.UpdatedAt)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -132,8 +140,8 @@ func (r *Repo) List(ctx context.Context, pars *model.ListPars) ([]*model.User, i
 // returning any error encountered during the operation.
 func (r *Repo) Create(ctx context.Context, obj *model.Edit) error {
 	insert := squirrel.Insert("users").
-		Columns("username", "password_hash").
-		Values(obj.Username, obj.PasswordHash)
+		Columns("username", "password_hash", "kdf_salt", "srp_salt", "srp_verifier").
+		Values(obj.Username, passwordHashValue(obj.PasswordHash), obj.KdfSalt, obj.SrpSalt, obj.SrpVerifier)
 
 	query, args, err := insert.PlaceholderFormat(squirrel.Dollar).ToSql()
 	if err != nil {
@@ -158,7 +166,19 @@ func (r *Repo) Update(ctx context.Context, pars *model.GetPars, obj *model.Edit)
 	}
 
 	if obj.PasswordHash != nil {
-		queryBuilder = queryBuilder.Set("password_hash", obj.PasswordHash)
+		queryBuilder = queryBuilder.Set("password_hash", passwordHashValue(obj.PasswordHash))
+	}
+
+	if obj.KdfSalt != nil {
+		queryBuilder = queryBuilder.Set("kdf_salt", obj.KdfSalt)
+	}
+
+	if obj.SrpSalt != nil {
+		queryBuilder = queryBuilder.Set("srp_salt", obj.SrpSalt)
+	}
+
+	if obj.SrpVerifier != nil {
+		queryBuilder = queryBuilder.Set("srp_verifier", obj.SrpVerifier)
 	}
 
 	if obj.UpdatedAt != nil {
@@ -176,6 +196,28 @@ func (r *Repo) Update(ctx context.Context, pars *model.GetPars, obj *model.Edit)
 	return err
 }
 
+// SetKeyCheck stores the key check of the user unless one is stored already, it reports whether
+// the key check was stored. The condition is checked in the same statement, so of two clients
+// logging in for the first time at once only one sets it.
+func (r *Repo) SetKeyCheck(ctx context.Context, userID string, keyCheck []byte) (bool, error) {
+	sql, args, err := squirrel.Update("users").
+		Set("key_check", keyCheck).
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Eq{"key_check": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.Con.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
 // Delete removes a user from the database based on the provided query parameters,
 // returning any error encountered during the operation.
 func (r *Repo) Delete(ctx context.Context, pars *model.GetPars) error {
@@ -213,3 +255,11 @@ func (r *Repo) Exists(ctx context.Context, pars *model.GetPars) (bool, error) {
 
 	return exist, nil
 }
+
+// passwordHashValue returns the value stored in the password_hash column, NULL for an empty hash.
+func passwordHashValue(passwordHash *string) interface{} {
+	if passwordHash == nil || *passwordHash == "" {
+		return nil
+	}
+	return *passwordHash
+}
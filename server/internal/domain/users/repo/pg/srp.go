@@ -0,0 +1,88 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"gophKeeper/server/internal/domain/users/model"
+	"time"
+)
+
+// CreateSrpHandshake stores the state of a pending SRP login, removing the expired ones.
+func (r *Repo) CreateSrpHandshake(ctx context.Context, obj *model.SrpHandshake) error {
+	sql, args, err := squirrel.Delete("srp_handshakes").
+		Where(squirrel.Lt{"expires_at": time.Now()}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.Con.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	sql, args, err = squirrel.Insert("srp_handshakes").
+		Columns("id", "user_id", "server_secret", "client_public", "expires_at").
+		Values(obj.ID, obj.UserID, obj.ServerSecret, obj.ClientPublic, obj.ExpiresAt).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Con.Exec(ctx, sql, args...)
+	return err
+}
+
+// GetSrpHandshake retrieves the unexpired pending SRP login without finishing it. It returns
+// the handshake if found, a boolean indicating its existence, and any error encountered.
+func (r *Repo) GetSrpHandshake(ctx context.Context, id string) (*model.SrpHandshake, bool, error) {
+	sql, args, err := squirrel.Select("id", "user_id", "server_secret", "client_public", "expires_at").
+		From("srp_handshakes").
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Gt{"expires_at": time.Now()}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result model.SrpHandshake
+	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.ID, &result.UserID, &result.ServerSecret, &result.ClientPublic, &result.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &result, true, nil
+}
+
+// TakeSrpHandshake removes the unexpired pending SRP login and returns it, so every handshake
+// can be finished only once. It returns the handshake if found, a boolean indicating its existence,
+// and any error encountered.
+func (r *Repo) TakeSrpHandshake(ctx context.Context, id string) (*model.SrpHandshake, bool, error) {
+	sql, args, err := squirrel.Delete("srp_handshakes").
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Gt{"expires_at": time.Now()}).
+		Suffix("RETURNING id, user_id, server_secret, client_public, expires_at").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result model.SrpHandshake
+	err = r.Con.QueryRow(ctx, sql, args...).Scan(&result.ID, &result.UserID, &result.ServerSecret, &result.ClientPublic, &result.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &result, true, nil
+}
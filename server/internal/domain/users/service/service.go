@@ -5,21 +5,42 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"gophKeeper/pkg/password"
 	"gophKeeper/server/internal/domain/users/model"
+	"gophKeeper/server/internal/errs"
+	"time"
+)
+
+const (
+	kdfSaltSize = 16
+	// maxKeyCheckSize limits the key check sealed by the clients, which is a short constant value.
+	maxKeyCheckSize = 256
 )
 
 // Service provides methods to manage user accounts, handling password operations,
 // user validation, and CRUD operations through the repository interface.
 type Service struct {
-	repoDB RepoDBI
+	repoDB           RepoDBI
+	fakeSrpKey       []byte
+	lockoutThreshold int
+	lockoutDuration  time.Duration
+	passwordPolicy   password.Policy
 }
 
-// New creates a new Service instance with the given database repository.
-func New(repoDB RepoDBI) *Service {
+// New creates a new Service instance with the given database repository. The fake SRP challenges
+// answered for unknown usernames are keyed with a key derived from the secret. Accounts are locked
+// for lockoutDuration after lockoutThreshold failed logins in a row, a zero threshold
+// disables the lockout. New passwords have to meet the password policy.
+func New(repoDB RepoDBI, secret string, lockoutThreshold int, lockoutDuration time.Duration, passwordPolicy password.Policy) *Service {
 	return &Service{
-		repoDB: repoDB,
+		repoDB:           repoDB,
+		fakeSrpKey:       deriveFakeSrpKey(secret),
+		lockoutThreshold: lockoutThreshold,
+		lockoutDuration:  lockoutDuration,
+		passwordPolicy:   passwordPolicy,
 	}
 }
 
@@ -33,6 +54,12 @@ type RepoDBI interface {
 	Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) error
 	Delete(ctx context.Context, pars *model.GetPars) error
 	Exists(ctx context.Context, pars *model.GetPars) (bool, error)
+	CreateSrpHandshake(ctx context.Context, obj *model.SrpHandshake) error
+	GetSrpHandshake(ctx context.Context, id string) (*model.SrpHandshake, bool, error)
+	TakeSrpHandshake(ctx context.Context, id string) (*model.SrpHandshake, bool, error)
+	RecordFailedLogin(ctx context.Context, userID string, threshold int, lockedUntil time.Time) (*time.Time, error)
+	ResetFailedLogins(ctx context.Context, userID string) error
+	SetKeyCheck(ctx context.Context, userID string, keyCheck []byte) (bool, error)
 }
 
 // IsValidPassword compares a hashed password with a plain password to verify a match.
@@ -42,6 +69,27 @@ func (s *Service) IsValidPassword(password string, plainPassword string) bool {
 	return err == nil
 }
 
+// PasswordPolicy returns the policy new passwords are checked against.
+func (s *Service) PasswordPolicy() password.Policy {
+	return s.passwordPolicy
+}
+
+// ValidatePassword checks that a new password of the user meets the password policy,
+// returning the violated requirements as violations of the password field.
+func (s *Service) ValidatePassword(username string, plainPassword string) error {
+	descriptions := s.passwordPolicy.Check(plainPassword, username)
+	if len(descriptions) == 0 {
+		return nil
+	}
+
+	violations := make([]errs.FieldViolation, 0, len(descriptions))
+	for _, description := range descriptions {
+		violations = append(violations, errs.FieldViolation{Field: "password", Description: description})
+	}
+
+	return &errs.ValidationError{Violations: violations}
+}
+
 // HashPassword hashes a plain password using bcrypt before storing it in the database.
 func (s *Service) HashPassword(password string) (string, error) {
 	// Хэшируем пароль перед сохранением в базу данных
@@ -53,6 +101,17 @@ func (s *Service) HashPassword(password string) (string, error) {
 	return string(hashedPassword), nil
 }
 
+// NewKdfSalt generates a random salt that clients use to derive the vault encryption key
+// from the master password. The salt is not secret and is returned to the client on login.
+func (s *Service) NewKdfSalt() ([]byte, error) {
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("can not generate kdf salt - %w", err)
+	}
+
+	return salt, nil
+}
+
 // IsLoginTaken checks if a username is already taken by querying the database.
 func (s *Service) IsLoginTaken(ctx context.Context, username string) (bool, error) {
 	return s.Exists(ctx, &model.GetPars{Username: username})
@@ -88,3 +147,64 @@ func (s *Service) Delete(ctx context.Context, pars *model.GetPars) error {
 func (s *Service) Exists(ctx context.Context, pars *model.GetPars) (bool, error) {
 	return s.repoDB.Exists(ctx, pars)
 }
+
+// CheckLocked returns errs.AccountLocked with the delay to retry after
+// if the account is locked after too many failed logins.
+func (s *Service) CheckLocked(user *model.User) error {
+	if lockedFor := user.LockedFor(time.Now()); lockedFor > 0 {
+		return &errs.RetryError{Reason: errs.AccountLocked, RetryAfter: lockedFor}
+	}
+	return nil
+}
+
+// RecordFailedLogin counts a failed login of the user, locking the account
+// once the lockout threshold is reached.
+func (s *Service) RecordFailedLogin(ctx context.Context, user *model.User) error {
+	if s.lockoutThreshold <= 0 {
+		return nil
+	}
+
+	lockedUntil, err := s.repoDB.RecordFailedLogin(ctx, user.UserID, s.lockoutThreshold, time.Now().Add(s.lockoutDuration))
+	if err != nil {
+		return fmt.Errorf("record failed login in PostgreSQL - %w", err)
+	}
+	user.LockedUntil = lockedUntil
+
+	return nil
+}
+
+// ResetFailedLogins forgets the failed logins of the user after a successful one.
+func (s *Service) ResetFailedLogins(ctx context.Context, user *model.User) error {
+	if user.FailedLogins == 0 && user.LockedUntil == nil {
+		return nil
+	}
+
+	if err := s.repoDB.ResetFailedLogins(ctx, user.UserID); err != nil {
+		return fmt.Errorf("reset failed logins in PostgreSQL - %w", err)
+	}
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+
+	return nil
+}
+
+// SetKeyCheck stores the key check the clients of the user verify the vault key with.
+// It returns errs.KeyCheckAlreadySet if the user has one, so it is never replaced.
+func (s *Service) SetKeyCheck(ctx context.Context, userID string, keyCheck []byte) error {
+	if len(keyCheck) == 0 || len(keyCheck) > maxKeyCheckSize {
+		return &errs.ValidationError{Violations: []errs.FieldViolation{{
+			Field:       "key_check",
+			Description: fmt.Sprintf("must be from 1 to %d bytes long", maxKeyCheckSize),
+		}}}
+	}
+
+	set, err := s.repoDB.SetKeyCheck(ctx, userID, keyCheck)
+	if err != nil {
+		return fmt.Errorf("set key check in PostgreSQL - %w", err)
+	}
+	if !set {
+		return errs.KeyCheckAlreadySet
+	}
+
+	return nil
+}
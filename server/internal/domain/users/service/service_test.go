@@ -1,6 +1,16 @@
 package service
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"gophKeeper/pkg/password"
+	"gophKeeper/pkg/srp"
+	"gophKeeper/server/internal/domain/users/model"
+	"gophKeeper/server/internal/errs"
+	"testing"
+	"time"
+)
 
 func TestService_HashPassword(t *testing.T) {
 
@@ -91,3 +101,281 @@ func TestService_IsValidPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestService_NewKdfSalt(t *testing.T) {
+	s := &Service{
+		repoDB: nil,
+	}
+
+	got, err := s.NewKdfSalt()
+	if err != nil {
+		t.Errorf("NewKdfSalt() error = %v", err)
+		return
+	}
+	if len(got) != kdfSaltSize {
+		t.Errorf("NewKdfSalt() len = %v, want %v", len(got), kdfSaltSize)
+	}
+
+	got1, err := s.NewKdfSalt()
+	if err != nil {
+		t.Errorf("NewKdfSalt() error = %v", err)
+		return
+	}
+	if string(got) == string(got1) {
+		t.Errorf("expected different salts, but got the same")
+	}
+}
+
+func TestService_SrpLogin(t *testing.T) {
+	ctx := context.Background()
+
+	salt, _ := srp.NewSalt()
+	user := &model.User{UserID: "1", Username: "alice", SrpSalt: salt, SrpVerifier: srp.Verifier("password", salt)}
+	s := New(newRepoDBMock(user), "secret", 3, time.Minute, password.DefaultPolicy)
+
+	login := func(password string) (*model.User, []byte, *srp.Client, error) {
+		client, err := srp.NewClient(password)
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+
+		challenge, err := s.StartSrpLogin(ctx, user, client.PublicKey())
+		if err != nil {
+			t.Fatalf("StartSrpLogin() error = %v", err)
+		}
+
+		clientProof, err := client.Proof(user.Username, challenge.Salt, challenge.ServerPublic)
+		if err != nil {
+			t.Fatalf("Proof() error = %v", err)
+		}
+
+		got, serverProof, err := s.FinishSrpLogin(ctx, challenge.LoginID, clientProof)
+		if err == nil {
+			// a finished handshake can not be replayed
+			if _, _, replayErr := s.FinishSrpLogin(ctx, challenge.LoginID, clientProof); !errors.Is(replayErr, errs.InvalidCredentials) {
+				t.Errorf("FinishSrpLogin() replay error = %v, want %v", replayErr, errs.InvalidCredentials)
+			}
+		}
+		return got, serverProof, client, err
+	}
+
+	got, serverProof, client, err := login("password")
+	if err != nil || got.UserID != user.UserID {
+		t.Fatalf("FinishSrpLogin() = %v, %v, want user %v", got, err, user.UserID)
+	}
+	if err = client.VerifyServer(serverProof); err != nil {
+		t.Errorf("VerifyServer() error = %v", err)
+	}
+
+	if _, _, _, err = login("wrong password"); !errors.Is(err, errs.InvalidCredentials) {
+		t.Errorf("FinishSrpLogin() error = %v, want %v", err, errs.InvalidCredentials)
+	}
+
+	if _, err = s.StartSrpLogin(ctx, user, []byte{0}); !errors.Is(err, errs.InvalidInput) {
+		t.Errorf("StartSrpLogin() error = %v, want %v", err, errs.InvalidInput)
+	}
+
+	// the third failed login in a row locks the account
+	for i := 0; i < 2; i++ {
+		_, _, _, _ = login("wrong password")
+	}
+	// the lockout is reported only for a valid proof
+	if _, _, _, err = login("wrong password"); !errors.Is(err, errs.InvalidCredentials) {
+		t.Errorf("FinishSrpLogin() of a locked account with a wrong password error = %v, want %v", err, errs.InvalidCredentials)
+	}
+	var retryErr *errs.RetryError
+	if _, _, _, err = login("password"); !errors.As(err, &retryErr) || retryErr.Reason != errs.AccountLocked {
+		t.Errorf("FinishSrpLogin() error = %v, want %v", err, errs.AccountLocked)
+	}
+
+	if err = s.ResetFailedLogins(ctx, user); err != nil || user.LockedFor(time.Now()) != 0 {
+		t.Errorf("ResetFailedLogins() error = %v, locked for %v", err, user.LockedFor(time.Now()))
+	}
+}
+
+func TestService_FakeSrpChallenge(t *testing.T) {
+	s := New(nil, "secret", 0, 0, password.DefaultPolicy)
+
+	first, err := s.FakeSrpChallenge("bob")
+	if err != nil {
+		t.Fatalf("FakeSrpChallenge() error = %v", err)
+	}
+	second, _ := s.FakeSrpChallenge("bob")
+	other, _ := s.FakeSrpChallenge("carol")
+
+	if len(first.Salt) != srp.SaltSize || !bytes.Equal(first.Salt, second.Salt) || bytes.Equal(first.Salt, other.Salt) {
+		t.Errorf("FakeSrpChallenge() salts = %x, %x, %x, want a stable salt per username", first.Salt, second.Salt, other.Salt)
+	}
+	if bytes.Equal(first.ServerPublic, second.ServerPublic) || first.LoginID == second.LoginID {
+		t.Errorf("FakeSrpChallenge() reused the ephemeral key or the login ID")
+	}
+}
+
+func TestService_ValidatePassword(t *testing.T) {
+	s := New(nil, "secret", 0, 0, password.Policy{MinLength: 10, MinEntropy: 40})
+
+	tests := []struct {
+		name           string
+		password       string
+		wantViolations int
+	}{
+		{name: "strong password", password: "f7Gq!2pLw9sR"},
+		{name: "short password", password: "kX9#mq2L", wantViolations: 1},
+		{name: "common password", password: "password", wantViolations: 3},
+		{name: "username", password: "alice-alice-alice", wantViolations: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidatePassword("alice", tt.password)
+
+			var validationErr *errs.ValidationError
+			if tt.wantViolations == 0 {
+				if err != nil {
+					t.Errorf("ValidatePassword() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.As(err, &validationErr) || len(validationErr.Violations) != tt.wantViolations {
+				t.Fatalf("ValidatePassword() error = %v, want %d violations", err, tt.wantViolations)
+			}
+			for _, v := range validationErr.Violations {
+				if v.Field != "password" {
+					t.Errorf("ValidatePassword() violation field = %v, want password", v.Field)
+				}
+			}
+		})
+	}
+}
+
+func TestService_ValidateSrpVerifier(t *testing.T) {
+	s := New(nil, "secret", 0, 0, password.DefaultPolicy)
+	salt, _ := srp.NewSalt()
+
+	tests := []struct {
+		name     string
+		salt     []byte
+		verifier []byte
+		wantErr  bool
+	}{
+		{name: "valid", salt: salt, verifier: srp.Verifier("password", salt)},
+		{name: "short salt", salt: salt[:4], verifier: srp.Verifier("password", salt), wantErr: true},
+		{name: "invalid verifier", salt: salt, verifier: []byte{1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.ValidateSrpVerifier(tt.salt, tt.verifier); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSrpVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_SetKeyCheck(t *testing.T) {
+	ctx := context.Background()
+
+	user := &model.User{UserID: "1", Username: "alice"}
+	s := New(newRepoDBMock(user), "secret", 0, 0, password.DefaultPolicy)
+
+	var validationErr *errs.ValidationError
+	if err := s.SetKeyCheck(ctx, user.UserID, nil); !errors.As(err, &validationErr) {
+		t.Errorf("SetKeyCheck() of an empty key check error = %v, want a validation error", err)
+	}
+	if err := s.SetKeyCheck(ctx, user.UserID, make([]byte, maxKeyCheckSize+1)); !errors.As(err, &validationErr) {
+		t.Errorf("SetKeyCheck() of a long key check error = %v, want a validation error", err)
+	}
+
+	if err := s.SetKeyCheck(ctx, user.UserID, []byte("first")); err != nil {
+		t.Fatalf("SetKeyCheck() error = %v", err)
+	}
+	// the key check of the first client is never replaced
+	if err := s.SetKeyCheck(ctx, user.UserID, []byte("second")); !errors.Is(err, errs.KeyCheckAlreadySet) {
+		t.Errorf("SetKeyCheck() error = %v, want %v", err, errs.KeyCheckAlreadySet)
+	}
+	if string(user.KeyCheck) != "first" {
+		t.Errorf("KeyCheck = %q, want %q", user.KeyCheck, "first")
+	}
+}
+
+type repoDBMock struct {
+	users      map[string]*model.User
+	handshakes map[string]*model.SrpHandshake
+}
+
+func newRepoDBMock(users ...*model.User) *repoDBMock {
+	m := &repoDBMock{users: map[string]*model.User{}, handshakes: map[string]*model.SrpHandshake{}}
+	for _, user := range users {
+		m.users[user.UserID] = user
+	}
+	return m
+}
+
+func (m *repoDBMock) Get(_ context.Context, pars *model.GetPars) (*model.User, bool, error) {
+	for _, user := range m.users {
+		if (pars.UserID == "" || user.UserID == pars.UserID) && (pars.Username == "" || user.Username == pars.Username) {
+			return user, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (m *repoDBMock) List(_ context.Context, _ *model.ListPars) ([]*model.User, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *repoDBMock) Create(_ context.Context, _ *model.Edit) error {
+	return nil
+}
+
+func (m *repoDBMock) Update(_ context.Context, _ *model.GetPars, _ *model.Edit) error {
+	return nil
+}
+
+func (m *repoDBMock) Delete(_ context.Context, _ *model.GetPars) error {
+	return nil
+}
+
+func (m *repoDBMock) Exists(ctx context.Context, pars *model.GetPars) (bool, error) {
+	_, found, err := m.Get(ctx, pars)
+	return found, err
+}
+
+func (m *repoDBMock) CreateSrpHandshake(_ context.Context, obj *model.SrpHandshake) error {
+	m.handshakes[obj.ID] = obj
+	return nil
+}
+
+func (m *repoDBMock) RecordFailedLogin(_ context.Context, userID string, threshold int, lockedUntil time.Time) (*time.Time, error) {
+	user := m.users[userID]
+	user.FailedLogins++
+	if user.FailedLogins >= threshold {
+		user.FailedLogins = 0
+		user.LockedUntil = &lockedUntil
+	}
+	return user.LockedUntil, nil
+}
+
+func (m *repoDBMock) ResetFailedLogins(_ context.Context, userID string) error {
+	m.users[userID].FailedLogins = 0
+	m.users[userID].LockedUntil = nil
+	return nil
+}
+
+func (m *repoDBMock) SetKeyCheck(_ context.Context, userID string, keyCheck []byte) (bool, error) {
+	user := m.users[userID]
+	if user.KeyCheck != nil {
+		return false, nil
+	}
+	user.KeyCheck = keyCheck
+	return true, nil
+}
+
+func (m *repoDBMock) GetSrpHandshake(_ context.Context, id string) (*model.SrpHandshake, bool, error) {
+	handshake, found := m.handshakes[id]
+	return handshake, found, nil
+}
+
+func (m *repoDBMock) TakeSrpHandshake(_ context.Context, id string) (*model.SrpHandshake, bool, error) {
+	handshake, found := m.handshakes[id]
+	delete(m.handshakes, id)
+	return handshake, found, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/hkdf"
+	"gophKeeper/pkg/srp"
+	"gophKeeper/server/internal/domain/users/model"
+	"gophKeeper/server/internal/errs"
+	"io"
+	"time"
+)
+
+const (
+	// srpHandshakeTTL is how long the second step of an SRP login can be sent after the first one.
+	srpHandshakeTTL = time.Minute
+	// fakeSrpKeyInfo separates the key of the fake SRP challenges from the other keys
+	// derived from the same secret.
+	fakeSrpKeyInfo = "gophkeeper srp fake challenge"
+)
+
+// ValidateSrpVerifier checks the SRP salt and verifier computed by a client.
+func (s *Service) ValidateSrpVerifier(salt, verifier []byte) error {
+	var violations []errs.FieldViolation
+
+	if len(salt) < srp.MinSaltSize || len(salt) > srp.MaxSaltSize {
+		violations = append(violations, errs.FieldViolation{
+			Field:       "salt",
+			Description: fmt.Sprintf("must be from %d to %d bytes long", srp.MinSaltSize, srp.MaxSaltSize),
+		})
+	}
+	if srp.ValidateVerifier(verifier) != nil {
+		violations = append(violations, errs.FieldViolation{Field: "verifier", Description: "must be an element of the SRP group"})
+	}
+
+	if len(violations) > 0 {
+		return &errs.ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// StartSrpLogin starts an SRP login of the user with the client's public ephemeral value A.
+// The state of the handshake is kept in the database until FinishSrpLogin, so the login
+// can be finished by any server replica.
+func (s *Service) StartSrpLogin(ctx context.Context, user *model.User, clientPublic []byte) (*model.SrpChallenge, error) {
+	if srp.ValidatePublicKey(clientPublic) != nil {
+		return nil, &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "client_public", Description: "must be a valid SRP public key"}}}
+	}
+
+	secret, public, err := srp.NewServerKey(user.SrpVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("can not generate srp key - %w", err)
+	}
+
+	handshake := &model.SrpHandshake{
+		ID:           uuid.New().String(),
+		UserID:       user.UserID,
+		ServerSecret: secret,
+		ClientPublic: clientPublic,
+		ExpiresAt:    time.Now().Add(srpHandshakeTTL),
+	}
+
+	err = s.repoDB.CreateSrpHandshake(ctx, handshake)
+	if err != nil {
+		return nil, fmt.Errorf("create srp handshake in PostgreSQL - %w", err)
+	}
+
+	return &model.SrpChallenge{
+		LoginID:      handshake.ID,
+		Salt:         user.SrpSalt,
+		ServerPublic: public,
+	}, nil
+}
+
+// FakeSrpChallenge returns a challenge for an unknown username, indistinguishable
+// from the challenge of an SRP account. The salt is derived from the username, so repeated logins
+// get the same one. The challenge is never stored, so the login fails on the second step.
+func (s *Service) FakeSrpChallenge(username string) (*model.SrpChallenge, error) {
+	_, public, err := srp.NewServerKey(s.fakeSrpValue("verifier", username))
+	if err != nil {
+		return nil, fmt.Errorf("can not generate srp key - %w", err)
+	}
+
+	return &model.SrpChallenge{
+		LoginID:      uuid.New().String(),
+		Salt:         s.fakeSrpValue("salt", username)[:srp.SaltSize],
+		ServerPublic: public,
+	}, nil
+}
+
+// SrpLoginUserID returns the ID of the user the pending SRP login belongs to, a boolean indicating
+// whether the login is pending, and any error encountered. Fake challenges are never pending.
+func (s *Service) SrpLoginUserID(ctx context.Context, loginID string) (string, bool, error) {
+	handshake, found, err := s.repoDB.GetSrpHandshake(ctx, loginID)
+	if err != nil {
+		return "", false, fmt.Errorf("get srp handshake from PostgreSQL - %w", err)
+	}
+	if !found {
+		return "", false, nil
+	}
+
+	return handshake.UserID, true, nil
+}
+
+// FinishSrpLogin checks the client proof M1 of the pending SRP login and returns the logged in user
+// along with the server proof M2. Every handshake can be finished once, any failure is reported
+// as errs.InvalidCredentials. Wrong proofs count as failed logins. Locked accounts are rejected
+// only after a valid proof, so the lockout does not reveal the account to anyone else.
+func (s *Service) FinishSrpLogin(ctx context.Context, loginID string, clientProof []byte) (*model.User, []byte, error) {
+	handshake, found, err := s.repoDB.TakeSrpHandshake(ctx, loginID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("take srp handshake from PostgreSQL - %w", err)
+	}
+	if !found {
+		return nil, nil, errs.InvalidCredentials
+	}
+
+	user, found, err := s.repoDB.Get(ctx, &model.GetPars{UserID: handshake.UserID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get user from PostgreSQL - %w", err)
+	}
+	if !found || !user.UsesSrp() {
+		return nil, nil, errs.InvalidCredentials
+	}
+
+	expected, serverProof, err := srp.ServerProofs(user.Username, user.SrpSalt, user.SrpVerifier, handshake.ServerSecret, handshake.ClientPublic)
+	if err == nil {
+		err = srp.VerifyClient(expected, clientProof)
+	}
+	if err != nil {
+		if err = s.RecordFailedLogin(ctx, user); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, errs.InvalidCredentials
+	}
+
+	if err = s.CheckLocked(user); err != nil {
+		return nil, nil, err
+	}
+
+	return user, serverProof, nil
+}
+
+// deriveFakeSrpKey derives the key of the fake SRP challenges from the secret with HKDF,
+// so the challenges reveal nothing about the secret, which signs the access tokens as well.
+func deriveFakeSrpKey(secret string) []byte {
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(fakeSrpKeyInfo)), key); err != nil {
+		// HKDF-SHA256 fails only when asked for more than 255 hashes of output
+		panic(err)
+	}
+	return key
+}
+
+// fakeSrpValue derives a value of the fake challenge of the username from the fake SRP key.
+func (s *Service) fakeSrpValue(label, username string) []byte {
+	mac := hmac.New(sha256.New, s.fakeSrpKey)
+	mac.Write([]byte("srp-fake-" + label + ":" + username))
+	return mac.Sum(nil)
+}
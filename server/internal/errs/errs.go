@@ -3,6 +3,12 @@
 // missing data, and service unavailability.
 package errs
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // Err represents a custom error type that implements the error interface.
 // It allows for defining string constants as specific errors.
 type Err string
@@ -20,4 +26,63 @@ const (
 	UserNotFound          = Err("user_not_found")
 	UsernameAlreadyExists = Err("username_already_exists")
 	InvalidPassword       = Err("invalid_password")
+	InvalidCredentials    = Err("invalid_credentials")
+	MissingToken          = Err("missing_token")
+	InvalidToken          = Err("invalid_token")
+	SessionNotFound       = Err("session_not_found")
+	NotFound              = Err("not_found")
+	FolderNotFound        = Err("folder_not_found")
+	FolderAlreadyExists   = Err("folder_already_exists")
+	SrpAlreadyEnabled     = Err("srp_already_enabled")
+	TwoFactorEnabled      = Err("two_factor_enabled")
+	TwoFactorNotEnabled   = Err("two_factor_not_enabled")
+	TwoFactorNotEnrolled  = Err("two_factor_not_enrolled")
+	InvalidTwoFactorCode  = Err("invalid_two_factor_code")
+	ChallengeNotFound     = Err("challenge_not_found")
+	TooManyRequests       = Err("too_many_requests")
+	AccountLocked         = Err("account_locked")
+	KeyCheckAlreadySet    = Err("key_check_already_set")
 )
+
+// FieldViolation describes a single invalid field of a request.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError is returned when a request has invalid fields.
+// It matches InvalidInput when checked with errors.Is.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+// Error returns the description of all violations.
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	return string(InvalidInput) + ": " + strings.Join(parts, "; ")
+}
+
+// Is reports whether the target is InvalidInput.
+func (e *ValidationError) Is(target error) bool {
+	return target == InvalidInput
+}
+
+// RetryError is returned when a request is rejected until the RetryAfter delay passes.
+// It unwraps to its Reason, so it matches the reason when checked with errors.Is.
+type RetryError struct {
+	Reason     Err
+	RetryAfter time.Duration
+}
+
+// Error returns the reason along with the delay.
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", e.Reason, e.RetryAfter)
+}
+
+// Unwrap returns the reason of the rejection.
+func (e *RetryError) Unwrap() error {
+	return e.Reason
+}
@@ -0,0 +1,138 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"gophKeeper/server/internal/errs"
+	"log/slog"
+)
+
+// errorDomain is the domain reported in the ErrorInfo details of translated errors.
+const errorDomain = "gophkeeper"
+
+// errorMapping describes how a domain error is reported to clients.
+type errorMapping struct {
+	code    codes.Code
+	message string
+}
+
+// errorMappings maps the domain errors to gRPC status codes and client-facing messages.
+var errorMappings = map[errs.Err]errorMapping{
+	errs.InvalidInput:          {codes.InvalidArgument, "invalid input"},
+	errs.NoRows:                {codes.NotFound, "not found"},
+	errs.NotFound:              {codes.NotFound, "data item not found"},
+	errs.UserNotFound:          {codes.NotFound, "user not found"},
+	errs.SessionNotFound:       {codes.NotFound, "session not found"},
+	errs.FolderNotFound:        {codes.NotFound, "folder not found"},
+	errs.FolderAlreadyExists:   {codes.AlreadyExists, "folder with this name already exists"},
+	errs.UsernameAlreadyExists: {codes.AlreadyExists, "username is already taken"},
+	errs.InvalidCredentials:    {codes.Unauthenticated, "invalid username or password"},
+	errs.SrpAlreadyEnabled:     {codes.FailedPrecondition, "account uses SRP already, change the password instead"},
+	errs.InvalidPassword:       {codes.Unauthenticated, "invalid username or password"},
+	errs.TwoFactorEnabled:      {codes.FailedPrecondition, "two-factor authentication is already enabled"},
+	errs.TwoFactorNotEnabled:   {codes.FailedPrecondition, "two-factor authentication is not enabled"},
+	errs.TwoFactorNotEnrolled:  {codes.FailedPrecondition, "two-factor enrollment is not started"},
+	errs.InvalidTwoFactorCode:  {codes.InvalidArgument, "invalid two-factor code"},
+	errs.ChallengeNotFound:     {codes.Unauthenticated, "two-factor challenge is invalid or expired"},
+	errs.TooManyRequests:       {codes.ResourceExhausted, "too many requests, retry later"},
+	errs.AccountLocked:         {codes.ResourceExhausted, "account is temporarily locked after too many failed logins"},
+	errs.KeyCheckAlreadySet:    {codes.FailedPrecondition, "key check is already set"},
+	errs.MissingToken:          {codes.Unauthenticated, "missing bearer token in authorization metadata"},
+	errs.InvalidToken:          {codes.Unauthenticated, "invalid or expired token"},
+	errs.ServiceNA:             {codes.Unavailable, "service is not available"},
+}
+
+// GrpcInterceptorErrors creates a gRPC server interceptor that translates the errors
+// returned by unary handlers into gRPC status errors.
+func GrpcInterceptorErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatusError(info.FullMethod, err)
+		}
+
+		return resp, nil
+	}
+}
+
+// GrpcStreamInterceptorErrors creates a gRPC server interceptor that translates the errors
+// returned by streaming handlers into gRPC status errors.
+func GrpcStreamInterceptorErrors() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return toStatusError(info.FullMethod, err)
+		}
+
+		return nil
+	}
+}
+
+// toStatusError translates the error into a gRPC status error. Status errors are returned as is,
+// domain errors are mapped to their codes with the ErrorInfo details, and validation errors
+// carry the BadRequest details listing every invalid field. Any other error is logged
+// and reported as an internal error, so implementation details never leak to clients.
+func toStatusError(method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "request deadline exceeded")
+	}
+
+	var validationErr *errs.ValidationError
+	if errors.As(err, &validationErr) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(validationErr.Violations))
+		for _, v := range validationErr.Violations {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Description})
+		}
+
+		return withDetails(status.New(codes.InvalidArgument, "invalid request fields"),
+			&errdetails.ErrorInfo{Reason: string(errs.InvalidInput), Domain: errorDomain},
+			&errdetails.BadRequest{FieldViolations: violations},
+		)
+	}
+
+	var domainErr errs.Err
+	if errors.As(err, &domainErr) {
+		if mapping, ok := errorMappings[domainErr]; ok {
+			reason := domainErr
+			if domainErr == errs.InvalidPassword {
+				// wrong passwords are reported as invalid credentials, like unknown usernames
+				reason = errs.InvalidCredentials
+			}
+
+			details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(reason), Domain: errorDomain}}
+
+			var retryErr *errs.RetryError
+			if errors.As(err, &retryErr) {
+				details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryErr.RetryAfter)})
+			}
+
+			return withDetails(status.New(mapping.code, mapping.message), details...)
+		}
+	}
+
+	slog.Error("request failed", slog.String("method", method), slog.String("error", err.Error()))
+	return status.Error(codes.Internal, "internal error")
+}
+
+// withDetails attaches the details to the status and returns it as an error,
+// falling back to the status without details if they can not be attached.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
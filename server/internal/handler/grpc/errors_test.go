@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gophKeeper/server/internal/errs"
+	"testing"
+	"time"
+)
+
+func Test_toStatusError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantCode       codes.Code
+		wantReason     string
+		wantViolations int
+	}{
+		{
+			name:     "status error",
+			err:      status.Error(codes.PermissionDenied, "denied"),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:       "wrapped not found",
+			err:        fmt.Errorf("update data - %w", errs.NotFound),
+			wantCode:   codes.NotFound,
+			wantReason: string(errs.NotFound),
+		},
+		{
+			name:       "username already exists",
+			err:        errs.UsernameAlreadyExists,
+			wantCode:   codes.AlreadyExists,
+			wantReason: string(errs.UsernameAlreadyExists),
+		},
+		{
+			name:       "invalid password is reported as invalid credentials",
+			err:        errs.InvalidPassword,
+			wantCode:   codes.Unauthenticated,
+			wantReason: string(errs.InvalidCredentials),
+		},
+		{
+			name:       "retry error",
+			err:        &errs.RetryError{Reason: errs.AccountLocked, RetryAfter: time.Minute},
+			wantCode:   codes.ResourceExhausted,
+			wantReason: string(errs.AccountLocked),
+		},
+		{
+			name: "validation error",
+			err: &errs.ValidationError{Violations: []errs.FieldViolation{
+				{Field: "username", Description: "must not be empty"},
+				{Field: "password", Description: "must not be empty"},
+			}},
+			wantCode:       codes.InvalidArgument,
+			wantReason:     string(errs.InvalidInput),
+			wantViolations: 2,
+		},
+		{
+			name:     "context canceled",
+			err:      context.Canceled,
+			wantCode: codes.Canceled,
+		},
+		{
+			name:     "unknown error",
+			err:      fmt.Errorf("get data from PostgreSQL - connection refused"),
+			wantCode: codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(toStatusError("/test", tt.err))
+			if st.Code() != tt.wantCode {
+				t.Errorf("toStatusError() code = %v, want %v", st.Code(), tt.wantCode)
+			}
+
+			var (
+				gotReason     string
+				gotViolations int
+			)
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					gotReason = d.GetReason()
+				case *errdetails.BadRequest:
+					gotViolations = len(d.GetFieldViolations())
+				}
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("toStatusError() reason = %v, want %v", gotReason, tt.wantReason)
+			}
+			if gotViolations != tt.wantViolations {
+				t.Errorf("toStatusError() violations = %v, want %v", gotViolations, tt.wantViolations)
+			}
+		})
+	}
+}
@@ -4,16 +4,39 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	pb "gophKeeper/pkg/proto/gophkeeper"
+	authModel "gophKeeper/server/internal/domain/auth/model"
 	dataItemsModel "gophKeeper/server/internal/domain/dataitems/model"
+	usersModel "gophKeeper/server/internal/domain/users/model"
+	"gophKeeper/server/internal/errs"
 	dataItemsU "gophKeeper/server/internal/usecase/dataitems"
 	usersU "gophKeeper/server/internal/usecase/users"
+	"io"
+	"strings"
+	"time"
 )
 
+const (
+	// streamChunkSize is the size of the chunks sent while streaming binary content.
+	streamChunkSize = 32 * 1024
+)
+
+// sortFields maps the sort fields of the list requests to the data item fields.
+// The unspecified field maps to the service default.
+var sortFields = map[pb.ListDataSort]dataItemsModel.SortField{
+	pb.ListDataSort_LIST_DATA_SORT_UNSPECIFIED: "",
+	pb.ListDataSort_LIST_DATA_SORT_UPDATED_AT:  dataItemsModel.SortByUpdatedAt,
+	pb.ListDataSort_LIST_DATA_SORT_CREATED_AT:  dataItemsModel.SortByCreatedAt,
+	pb.ListDataSort_LIST_DATA_SORT_ID:          dataItemsModel.SortByID,
+}
+
 // St implements the GophKeeperServiceServer interface, providing gRPC handlers
 // for user management and data item operations. It uses use cases for both users
 // and data items to perform business logic.
@@ -35,7 +58,7 @@ func New(dataItemsUcs *dataItemsU.Usecase, usersUcs *usersU.Usecase) *St {
 func (s *St) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
 	err := s.usersUcs.Register(ctx, req.GetUsername(), req.GetPassword())
 	if err != nil {
-		return nil, status.Errorf(codes.Canceled, "failed to register user: %v", err)
+		return nil, err
 	}
 
 	return &pb.RegisterResponse{
@@ -43,19 +66,297 @@ func (s *St) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.Registe
 	}, nil
 }
 
-// Login handles user login requests, returning a token if the credentials are valid.
+// Login handles user login requests, starting a new session and returning its token pair
+// and the kdf salt if the credentials are valid, or the two-factor challenge of the login.
 func (s *St) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	token, err := s.usersUcs.Login(ctx, req.GetUsername(), req.GetPassword())
+	result, err := s.usersUcs.Login(ctx, req.GetUsername(), req.GetPassword(), userAgentFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return loginResultToProto(result), nil
+}
+
+// RegisterSrp handles requests to register a new user with the SRP salt and verifier.
+func (s *St) RegisterSrp(ctx context.Context, req *pb.RegisterSrpRequest) (*pb.RegisterResponse, error) {
+	err := s.usersUcs.RegisterSrp(ctx, req.GetUsername(), req.GetSalt(), req.GetVerifier())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RegisterResponse{
+		Message: "ok",
+	}, nil
+}
+
+// LoginSrpStart handles the first step of an SRP login, returning the challenge of the user.
+func (s *St) LoginSrpStart(ctx context.Context, req *pb.LoginSrpStartRequest) (*pb.LoginSrpStartResponse, error) {
+	challenge, err := s.usersUcs.LoginSrpStart(ctx, req.GetUsername(), req.GetClientPublic())
 	if err != nil {
 		return nil, err
 	}
 
-	return &pb.LoginResponse{Token: *token}, nil
+	return &pb.LoginSrpStartResponse{
+		LoginId:       challenge.LoginID,
+		Salt:          challenge.Salt,
+		ServerPublic:  challenge.ServerPublic,
+		PasswordLogin: challenge.PasswordLogin,
+	}, nil
+}
+
+// LoginSrpFinish handles the second step of an SRP login, starting a new session and returning
+// its token pair, the kdf salt and the server proof if the client proof is valid.
+// Accounts with two-factor authentication get the server proof and the two-factor challenge.
+func (s *St) LoginSrpFinish(ctx context.Context, req *pb.LoginSrpFinishRequest) (*pb.LoginResponse, error) {
+	result, err := s.usersUcs.LoginSrpFinish(ctx, req.GetLoginId(), req.GetClientProof(), userAgentFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return loginResultToProto(result), nil
+}
+
+// SetSrpVerifier handles requests to replace the password of the user with the SRP salt and verifier,
+// revoking the other sessions of the user.
+func (s *St) SetSrpVerifier(ctx context.Context, req *pb.SetSrpVerifierRequest) (*pb.SetSrpVerifierResponse, error) {
+	principal, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.usersUcs.SetSrpVerifier(ctx, principal, passwordProofFromProto(req.GetProof()), req.GetSalt(), req.GetVerifier())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetSrpVerifierResponse{
+		Message: "ok",
+	}, nil
+}
+
+// SetKeyCheck handles requests to store the key check of the logged in user.
+func (s *St) SetKeyCheck(ctx context.Context, req *pb.SetKeyCheckRequest) (*pb.SetKeyCheckResponse, error) {
+	principal, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.usersUcs.SetKeyCheck(ctx, principal, req.GetKeyCheck()); err != nil {
+		return nil, err
+	}
+
+	return &pb.SetKeyCheckResponse{
+		Message: "ok",
+	}, nil
+}
+
+// GetPasswordPolicy handles requests for the password policy of the server,
+// which the client enforces for the passwords it derives SRP verifiers from.
+func (s *St) GetPasswordPolicy(context.Context, *emptypb.Empty) (*pb.GetPasswordPolicyResponse, error) {
+	policy := s.usersUcs.PasswordPolicy()
+
+	return &pb.GetPasswordPolicyResponse{
+		MinLength:  int32(policy.MinLength),
+		MinEntropy: policy.MinEntropy,
+	}, nil
+}
+
+// VerifyLoginTwoFactor handles requests to complete a two-factor login challenge, starting
+// a new session and returning its token pair and the kdf salt if the code is valid.
+func (s *St) VerifyLoginTwoFactor(ctx context.Context, req *pb.VerifyLoginTwoFactorRequest) (*pb.LoginResponse, error) {
+	result, err := s.usersUcs.VerifyLoginTwoFactor(ctx, req.GetChallengeToken(), req.GetCode(), userAgentFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return loginResultToProto(result), nil
+}
+
+// EnrollTwoFactor handles requests to generate a new TOTP secret of the user.
+func (s *St) EnrollTwoFactor(ctx context.Context, _ *emptypb.Empty) (*pb.EnrollTwoFactorResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.usersUcs.EnrollTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.EnrollTwoFactorResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningUri: enrollment.ProvisioningURI,
+		QrCodePng:       enrollment.QRCode,
+	}, nil
+}
+
+// ConfirmTwoFactor handles requests to enable two-factor authentication with a code
+// of the enrolled secret, returning the recovery codes.
+func (s *St) ConfirmTwoFactor(ctx context.Context, req *pb.ConfirmTwoFactorRequest) (*pb.ConfirmTwoFactorResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.usersUcs.ConfirmTwoFactor(ctx, userID, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ConfirmTwoFactorResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// DisableTwoFactor handles requests to turn off two-factor authentication of the user.
+func (s *St) DisableTwoFactor(ctx context.Context, req *pb.DisableTwoFactorRequest) (*pb.DisableTwoFactorResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.usersUcs.DisableTwoFactor(ctx, userID, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DisableTwoFactorResponse{
+		Message: "ok",
+	}, nil
+}
+
+// ChangePassword handles requests to replace the password of the user, revoking all the sessions
+// of the user and returning the token pair of a new one.
+func (s *St) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.LoginResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.usersUcs.ChangePassword(ctx, userID, passwordProofFromProto(req.GetProof()), req.GetSalt(), req.GetVerifier(),
+		req.GetNewPassword(), userAgentFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return loginResultToProto(result), nil
+}
+
+// ChangeUsername handles requests to rename the user.
+func (s *St) ChangeUsername(ctx context.Context, req *pb.ChangeUsernameRequest) (*pb.ChangeUsernameResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.usersUcs.ChangeUsername(ctx, userID, req.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ChangeUsernameResponse{
+		Message: "ok",
+	}, nil
+}
+
+// DeleteAccount handles requests to delete the user with all the data items and their files.
+func (s *St) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.usersUcs.DeleteAccount(ctx, userID, passwordProofFromProto(req.GetProof()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteAccountResponse{
+		Message: "ok",
+	}, nil
+}
+
+// RefreshToken handles requests to exchange a refresh token for a new token pair.
+func (s *St) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	tokens, err := s.usersUcs.RefreshToken(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RefreshTokenResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    timestamppb.New(tokens.ExpiresAt),
+	}, nil
+}
+
+// Logout handles requests to end the current session, revoking its tokens.
+func (s *St) Logout(ctx context.Context, _ *emptypb.Empty) (*pb.LogoutResponse, error) {
+	principal, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.usersUcs.Logout(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.LogoutResponse{
+		Message: "Logout successful",
+	}, nil
+}
+
+// ListSessions handles requests to list the active sessions of the user.
+func (s *St) ListSessions(ctx context.Context, _ *emptypb.Empty) (*pb.ListSessionsResponse, error) {
+	principal, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.usersUcs.ListSessions(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListSessionsResponse{
+		Sessions: make([]*pb.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &pb.Session{
+			Id:         session.ID,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  timestamppb.New(session.CreatedAt),
+			LastUsedAt: timestamppb.New(session.LastUsedAt),
+			ExpiresAt:  timestamppb.New(session.ExpiresAt),
+			Current:    session.ID == principal.SessionID,
+		})
+	}
+
+	return resp, nil
+}
+
+// RevokeSession handles requests to revoke one of the user's sessions.
+func (s *St) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.usersUcs.RevokeSession(ctx, userID, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RevokeSessionResponse{
+		Message: "Session revoked",
+	}, nil
 }
 
 // GetData retrieves a specific data item based on user ID and other provided parameters.
 func (s *St) GetData(ctx context.Context, req *pb.GetDataRequest) (*pb.GetDataResponse, error) {
-	userID, err := s.usersUcs.GetUserIDFromContext(ctx)
+	userID, err := userIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -75,69 +376,173 @@ func (s *St) GetData(ctx context.Context, req *pb.GetDataRequest) (*pb.GetDataRe
 		}, nil
 	}
 
-	dataItem := &pb.DataItem{
-		Id:        obj.ID,
-		Type:      obj.Type,
-		Data:      obj.Data,
-		Meta:      obj.Meta,
-		CreatedAt: timestamppb.New(obj.CreatedAt),
-		UpdatedAt: timestamppb.New(obj.UpdatedAt),
+	return &pb.GetDataResponse{
+		Data: []*pb.DataItem{dataItemToProto(obj)},
+	}, nil
+}
+
+// ListData retrieves a page of data item summaries based on user ID and the provided filters.
+// The payloads are not listed, they are fetched with GetData.
+func (s *St) ListData(ctx context.Context, req *pb.ListDataRequest) (*pb.ListDataResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return &pb.GetDataResponse{
-		Data: []*pb.DataItem{dataItem},
+	sort, ok := sortFields[req.GetSortBy()]
+	if !ok {
+		// unknown fields are rejected by the service
+		sort = dataItemsModel.SortField(req.GetSortBy().String())
+	}
+
+	pars := &dataItemsModel.ListPars{
+		UserID:      &userID,
+		Sort:        sort,
+		Descending:  req.GetDescending(),
+		WithoutData: true,
+	}
+	if req.GetType() != "" {
+		dataType := req.GetType()
+		pars.Type = &dataType
+	}
+	if req.GetUpdatedSince() != nil {
+		updatedSince := req.GetUpdatedSince().AsTime()
+		pars.UpdatedAfter = &updatedSince
+	}
+
+	result, nextPageToken, err := s.dataItemsUcs.ListPage(ctx, pars, req.GetPageToken(), int(req.GetPageSize()))
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*pb.DataItemSummary, 0, len(result))
+	for _, item := range result {
+		summaries = append(summaries, dataItemSummaryToProto(item))
+	}
+
+	return &pb.ListDataResponse{
+		Items:         summaries,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
-// ListData retrieves a data items based on user ID
-func (s *St) ListData(ctx context.Context, _ *emptypb.Empty) (*pb.ListDataResponse, error) {
-	userID, err := s.usersUcs.GetUserIDFromContext(ctx)
+// SearchData retrieves the summaries of the user's data items matching the structured metadata filters.
+func (s *St) SearchData(ctx context.Context, req *pb.SearchDataRequest) (*pb.SearchDataResponse, error) {
+	userID, err := userIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	result, _, err := s.dataItemsUcs.ListAll(ctx, &dataItemsModel.ListPars{
-		UserID: &userID,
+	if req.GetLimit() < 0 {
+		return nil, &errs.ValidationError{Violations: []errs.FieldViolation{
+			{Field: "limit", Description: "must not be negative"},
+		}}
+	}
+
+	result, err := s.dataItemsUcs.SearchData(ctx, &dataItemsModel.SearchPars{
+		UserID:    userID,
+		Query:     req.GetQuery(),
+		Tags:      req.GetTags(),
+		Labels:    req.GetLabels(),
+		FolderID:  req.GetFolderId(),
+		Recursive: req.GetRecursive(),
+		Type:      req.GetType(),
+		Limit:     uint64(req.GetLimit()),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	dataItems := make([]*pb.DataItem, 0, len(result))
+	summaries := make([]*pb.DataItemSummary, 0, len(result))
 	for _, item := range result {
-		dataItem := &pb.DataItem{
-			Id:        item.ID,
-			Type:      item.Type,
-			Data:      item.Data,
-			Meta:      item.Meta,
-			CreatedAt: timestamppb.New(item.CreatedAt),
-			UpdatedAt: timestamppb.New(item.UpdatedAt),
-		}
+		summaries = append(summaries, dataItemSummaryToProto(item))
+	}
+
+	return &pb.SearchDataResponse{Items: summaries}, nil
+}
 
-		dataItems = append(dataItems, dataItem)
+// CreateFolder handles requests to create a new folder for a user.
+func (s *St) CreateFolder(ctx context.Context, req *pb.CreateFolderRequest) (*pb.CreateFolderResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return &pb.ListDataResponse{
-		Data: dataItems,
-	}, nil
+	folder, err := s.dataItemsUcs.CreateFolder(ctx, userID, req.GetParentId(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateFolderResponse{Folder: folderToProto(folder)}, nil
+}
+
+// ListFolders retrieves all the folders of the user.
+func (s *St) ListFolders(ctx context.Context, _ *emptypb.Empty) (*pb.ListFoldersResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := s.dataItemsUcs.ListFolders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListFoldersResponse{
+		Folders: make([]*pb.Folder, 0, len(folders)),
+	}
+	for _, folder := range folders {
+		resp.Folders = append(resp.Folders, folderToProto(folder))
+	}
+
+	return resp, nil
+}
+
+// DeleteFolder handles requests to remove a folder of the user with its subfolders.
+func (s *St) DeleteFolder(ctx context.Context, req *pb.DeleteFolderRequest) (*pb.DeleteFolderResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.dataItemsUcs.DeleteFolder(ctx, userID, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteFolderResponse{Message: "Folder deleted"}, nil
 }
 
 // CreateData handles requests to create new data items for a user.
 func (s *St) CreateData(ctx context.Context, req *pb.CreateDataRequest) (*pb.CreateDataResponse, error) {
-	userID, err := s.usersUcs.GetUserIDFromContext(ctx)
+	userID, err := userIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	data := req.GetData()
+	if data == nil {
+		return nil, errMissingData
+	}
 
-	err = s.dataItemsUcs.CreateData(ctx, &dataItemsModel.Edit{
+	err = checkEncrypted(data)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkItemID("data.id", data.GetId()); err != nil {
+		return nil, err
+	}
+
+	editData := &dataItemsModel.Edit{
 		ID:     data.Id,
 		UserID: &userID,
 		Type:   &data.Type,
 		Data:   &data.Data,
 		Meta:   &data.Meta,
-	})
+	}
+	setMetadataFromProto(editData, data.GetMetadata())
+
+	err = s.dataItemsUcs.CreateData(ctx, editData)
 	if err != nil {
 		return nil, err
 	}
@@ -147,12 +552,23 @@ func (s *St) CreateData(ctx context.Context, req *pb.CreateDataRequest) (*pb.Cre
 
 // UpdateData handles requests to update existing data items for a user.
 func (s *St) UpdateData(ctx context.Context, req *pb.UpdateDataRequest) (*pb.UpdateDataResponse, error) {
-	userID, err := s.usersUcs.GetUserIDFromContext(ctx)
+	userID, err := userIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	data := req.GetData()
+	if data == nil {
+		return nil, errMissingData
+	}
+
+	err = checkEncrypted(data)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkItemID("data.id", data.GetId()); err != nil {
+		return nil, err
+	}
 
 	editData := &dataItemsModel.Edit{
 		UserID: &userID,
@@ -168,6 +584,7 @@ func (s *St) UpdateData(ctx context.Context, req *pb.UpdateDataRequest) (*pb.Upd
 	if data.Meta != "" {
 		editData.Meta = &data.Meta
 	}
+	setMetadataFromProto(editData, data.GetMetadata())
 
 	err = s.dataItemsUcs.EditData(ctx, editData)
 	if err != nil {
@@ -177,9 +594,9 @@ func (s *St) UpdateData(ctx context.Context, req *pb.UpdateDataRequest) (*pb.Upd
 	return &pb.UpdateDataResponse{Message: "Update successful"}, nil
 }
 
-// DeleteData handles requests to delete data items based on user ID and item ID.
+// DeleteData handles requests to move data items to the trash based on user ID and item ID.
 func (s *St) DeleteData(ctx context.Context, req *pb.DeleteDataRequest) (*pb.DeleteDataResponse, error) {
-	userID, err := s.usersUcs.GetUserIDFromContext(ctx)
+	userID, err := userIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -192,15 +609,504 @@ func (s *St) DeleteData(ctx context.Context, req *pb.DeleteDataRequest) (*pb.Del
 		return nil, err
 	}
 
-	return &pb.DeleteDataResponse{Message: "Delete successful"}, nil
+	return &pb.DeleteDataResponse{Message: "Moved to trash"}, nil
+}
+
+// ListTrash retrieves the data items of the user that are in the trash.
+func (s *St) ListTrash(ctx context.Context, _ *emptypb.Empty) (*pb.ListTrashResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.dataItemsUcs.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataItems := make([]*pb.DataItem, 0, len(result))
+	for _, item := range result {
+		dataItems = append(dataItems, dataItemToProto(item))
+	}
+
+	return &pb.ListTrashResponse{
+		Data: dataItems,
+	}, nil
+}
+
+// RestoreData handles requests to move data items back from the trash based on user ID and item ID.
+func (s *St) RestoreData(ctx context.Context, req *pb.RestoreDataRequest) (*pb.RestoreDataResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.dataItemsUcs.RestoreData(ctx, &dataItemsModel.GetPars{
+		ID:     req.GetId(),
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RestoreDataResponse{Message: "Restore successful"}, nil
 }
 
-// SyncData handles requests to synchronize data between the client and the server (currently not implemented).
+// SyncData handles requests to synchronize data between the client and the server.
+// It applies the client's newer items, returns the server items the client is missing
+// or has stale, and reports items changed on both sides as conflicts.
 func (s *St) SyncData(ctx context.Context, req *pb.SyncDataRequest) (*pb.SyncDataResponse, error) {
-	return nil, nil
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*dataItemsModel.DataItems, 0, len(req.GetData()))
+	for _, item := range req.GetData() {
+		if err = checkEncrypted(item); err != nil {
+			return nil, err
+		}
+		if err = checkItemID("data.id", item.GetId()); err != nil {
+			return nil, err
+		}
+		items = append(items, dataItemFromProto(item))
+	}
+
+	var lastSyncedAt time.Time
+	if req.GetLastSyncedAt() != nil {
+		lastSyncedAt = req.GetLastSyncedAt().AsTime()
+	}
+
+	result, err := s.dataItemsUcs.SyncData(ctx, userID, items, lastSyncedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.SyncDataResponse{
+		Data:      make([]*pb.DataItem, 0, len(result.Updates)),
+		Conflicts: make([]*pb.SyncConflict, 0, len(result.Conflicts)),
+		SyncedAt:  timestamppb.New(result.SyncedAt),
+	}
+
+	for _, item := range result.Updates {
+		resp.Data = append(resp.Data, dataItemToProto(item))
+	}
+
+	for _, conflict := range result.Conflicts {
+		resp.Conflicts = append(resp.Conflicts, &pb.SyncConflict{
+			Client: dataItemToProto(conflict.Client),
+			Server: dataItemToProto(conflict.Server),
+		})
+	}
+
+	return resp, nil
+}
+
+// UploadBinary handles client-streaming uploads of binary items. The first message carries
+// the binary info, the received chunks are piped straight to the object storage.
+func (s *St) UploadBinary(stream pb.GophKeeperService_UploadBinaryServer) error {
+	ctx := stream.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	info := req.GetInfo()
+	if info == nil || info.GetId() == "" {
+		return &errs.ValidationError{Violations: []errs.FieldViolation{
+			{Field: "info.id", Description: "first message must contain binary info with the item ID"},
+		}}
+	}
+	if err = checkItemID("info.id", info.GetId()); err != nil {
+		return err
+	}
+
+	reader := &uploadReader{stream: stream}
+	meta := info.GetMeta()
+	size := info.GetSize()
+	if size == 0 {
+		size = -1
+	}
+
+	err = s.dataItemsUcs.UploadBinary(ctx, &dataItemsModel.Edit{
+		ID:     info.GetId(),
+		UserID: &userID,
+		Meta:   &meta,
+	}, reader, size)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&pb.UploadBinaryResponse{
+		Message: "Upload successful",
+		Size:    reader.size,
+	})
+}
+
+// DownloadBinary handles server-streaming downloads of binary items. The first message carries
+// the binary info, the content is read from the object storage and sent in chunks.
+func (s *St) DownloadBinary(req *pb.DownloadBinaryRequest, stream pb.GophKeeperService_DownloadBinaryServer) error {
+	ctx := stream.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		obj    *dataItemsModel.DataItems
+		reader io.ReadCloser
+		size   int64
+		found  bool
+	)
+	if req.GetVersion() > 0 {
+		obj, reader, size, found, err = s.dataItemsUcs.DownloadVersion(ctx, &dataItemsModel.VersionPars{
+			ItemID:  req.GetId(),
+			UserID:  userID,
+			Version: int(req.GetVersion()),
+		})
+	} else {
+		obj, reader, size, found, err = s.dataItemsUcs.DownloadBinary(ctx, &dataItemsModel.GetPars{
+			ID:     req.GetId(),
+			UserID: userID,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errs.NotFound
+	}
+	defer reader.Close()
+
+	err = stream.Send(&pb.DownloadBinaryResponse{
+		Content: &pb.DownloadBinaryResponse_Info{
+			Info: &pb.BinaryInfo{
+				Id:   obj.ID,
+				Meta: obj.Meta,
+				Size: size,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, streamChunkSize)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			sendErr := stream.Send(&pb.DownloadBinaryResponse{
+				Content: &pb.DownloadBinaryResponse_Chunk{Chunk: buf[:n]},
+			})
+			if sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ListVersions handles requests to list the versions of a data item of the user, newest first.
+func (s *St) ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.dataItemsUcs.ListVersions(ctx, &dataItemsModel.VersionPars{
+		ItemID: req.GetId(),
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListVersionsResponse{
+		Versions: make([]*pb.DataItem, 0, len(versions)),
+	}
+	for _, version := range versions {
+		resp.Versions = append(resp.Versions, dataItemToProto(version))
+	}
+
+	return resp, nil
+}
+
+// GetVersion handles requests to retrieve a single version of a data item of the user.
+func (s *St) GetVersion(ctx context.Context, req *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version, found, err := s.dataItemsUcs.GetVersion(ctx, &dataItemsModel.VersionPars{
+		ItemID:  req.GetId(),
+		UserID:  userID,
+		Version: int(req.GetVersion()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errs.NotFound
+	}
+
+	return &pb.GetVersionResponse{
+		Data: dataItemToProto(version),
+	}, nil
+}
+
+// RestoreVersion handles requests to make a previous version of a data item of the user current again.
+func (s *St) RestoreVersion(ctx context.Context, req *pb.RestoreVersionRequest) (*pb.RestoreVersionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s.dataItemsUcs.RestoreVersion(ctx, &dataItemsModel.VersionPars{
+		ItemID:  req.GetId(),
+		UserID:  userID,
+		Version: int(req.GetVersion()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RestoreVersionResponse{
+		Message: "Restore successful",
+		Data:    dataItemToProto(obj),
+	}, nil
 }
 
 // Ping handles requests to show is server available
 func (s *St) Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return &emptypb.Empty{}, nil
 }
+
+// principalFromContext returns the principal authenticated by the auth interceptor.
+func principalFromContext(ctx context.Context) (*authModel.Principal, error) {
+	principal, ok := authModel.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "request is not authenticated")
+	}
+
+	return principal, nil
+}
+
+// userIDFromContext returns the ID of the user authenticated by the auth interceptor.
+func userIDFromContext(ctx context.Context) (string, error) {
+	principal, err := principalFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return principal.UserID, nil
+}
+
+// userAgentFromContext returns the user agent of the client that made the request.
+func userAgentFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	return strings.Join(md.Get("user-agent"), " ")
+}
+
+// loginResultToProto converts the result of a login into its protobuf representation,
+// holding either the token pair or the two-factor challenge.
+func loginResultToProto(result *usersModel.LoginResult) *pb.LoginResponse {
+	resp := &pb.LoginResponse{
+		KdfSalt:     result.KdfSalt,
+		ServerProof: result.ServerProof,
+	}
+
+	if result.ChallengeToken != "" {
+		resp.TwoFactorChallenge = result.ChallengeToken
+		resp.TwoFactorExpiresAt = timestamppb.New(result.ChallengeExpiresAt)
+		return resp
+	}
+
+	resp.Token = result.Token
+	resp.RefreshToken = result.RefreshToken
+	resp.ExpiresAt = timestamppb.New(result.ExpiresAt)
+	resp.KeyCheck = result.KeyCheck
+	return resp
+}
+
+// passwordProofFromProto converts the protobuf password proof into the users model, nil if it is not set.
+func passwordProofFromProto(proof *pb.PasswordProof) *usersModel.PasswordProof {
+	if proof == nil {
+		return nil
+	}
+
+	return &usersModel.PasswordProof{
+		LoginID:     proof.GetLoginId(),
+		ClientProof: proof.GetClientProof(),
+		Password:    proof.GetPassword(),
+	}
+}
+
+// errMissingData is returned for requests to create or update an item that carry no item.
+var errMissingData = &errs.ValidationError{Violations: []errs.FieldViolation{
+	{Field: "data", Description: "must be set"},
+}}
+
+// checkItemID rejects the item ID sent by the client unless it is a UUID in its canonical form.
+// The IDs are part of the keys the binary content is stored under, so they must never hold a path.
+func checkItemID(field, id string) error {
+	if parsed, err := uuid.Parse(id); err != nil || parsed.String() != id {
+		return &errs.ValidationError{Violations: []errs.FieldViolation{
+			{Field: field, Description: "must be a UUID"},
+		}}
+	}
+
+	return nil
+}
+
+// errPlaintextPayload is returned for items carrying their typed payload in plaintext.
+var errPlaintextPayload = &errs.ValidationError{Violations: []errs.FieldViolation{
+	{Field: "payload", Description: "must be serialized into data and encrypted by the client"},
+}}
+
+// checkEncrypted rejects the item if it carries its typed payload in plaintext. The server only ever
+// stores ciphertext, the clients validate the payload and serialize it into data before encrypting it.
+func checkEncrypted(item *pb.DataItem) error {
+	if item.GetPayload() != nil {
+		return errPlaintextPayload
+	}
+
+	return nil
+}
+
+// dataItemToProto converts a data item model into its protobuf representation.
+func dataItemToProto(obj *dataItemsModel.DataItems) *pb.DataItem {
+	item := &pb.DataItem{
+		Id:        obj.ID,
+		Type:      obj.Type,
+		Data:      obj.Data,
+		Meta:      obj.Meta,
+		CreatedAt: timestamppb.New(obj.CreatedAt),
+		UpdatedAt: timestamppb.New(obj.UpdatedAt),
+		Version:   int32(obj.Version),
+		Metadata:  metadataToProto(obj),
+	}
+
+	if obj.DeletedAt != nil {
+		item.DeletedAt = timestamppb.New(*obj.DeletedAt)
+	}
+
+	return item
+}
+
+// dataItemSummaryToProto converts a data item model into its protobuf summary without the payload.
+func dataItemSummaryToProto(obj *dataItemsModel.DataItems) *pb.DataItemSummary {
+	return &pb.DataItemSummary{
+		Id:        obj.ID,
+		Type:      obj.Type,
+		Meta:      obj.Meta,
+		Size:      obj.Size,
+		Version:   int32(obj.Version),
+		CreatedAt: timestamppb.New(obj.CreatedAt),
+		UpdatedAt: timestamppb.New(obj.UpdatedAt),
+		Metadata:  metadataToProto(obj),
+	}
+}
+
+// metadataToProto converts the structured metadata of the data item model into its protobuf representation.
+func metadataToProto(obj *dataItemsModel.DataItems) *pb.DataItemMetadata {
+	return &pb.DataItemMetadata{
+		Name:     obj.Name,
+		FolderId: obj.FolderID,
+		Tags:     obj.Tags,
+		Labels:   obj.Labels,
+	}
+}
+
+// setMetadataFromProto sets the structured metadata in the Edit object, replacing all of it.
+// Nothing is set if the metadata is missing, so the metadata of the item is kept.
+func setMetadataFromProto(obj *dataItemsModel.Edit, metadata *pb.DataItemMetadata) {
+	if metadata == nil {
+		return
+	}
+
+	name, folderID := metadata.GetName(), metadata.GetFolderId()
+	tags := metadata.GetTags()
+	if tags == nil {
+		tags = []string{}
+	}
+	labels := metadata.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	obj.Name = &name
+	obj.FolderID = &folderID
+	obj.Tags = &tags
+	obj.Labels = &labels
+}
+
+// folderToProto converts the folder model into its protobuf representation.
+func folderToProto(folder *dataItemsModel.Folder) *pb.Folder {
+	return &pb.Folder{
+		Id:        folder.ID,
+		ParentId:  folder.ParentID,
+		Name:      folder.Name,
+		CreatedAt: timestamppb.New(folder.CreatedAt),
+	}
+}
+
+// dataItemFromProto converts a protobuf data item into the data item model.
+func dataItemFromProto(item *pb.DataItem) *dataItemsModel.DataItems {
+	obj := &dataItemsModel.DataItems{
+		ID:      item.GetId(),
+		Type:    item.GetType(),
+		Data:    item.GetData(),
+		Meta:    item.GetMeta(),
+		Version: int(item.GetVersion()),
+	}
+
+	if item.GetCreatedAt() != nil {
+		obj.CreatedAt = item.GetCreatedAt().AsTime()
+	}
+	if item.GetUpdatedAt() != nil {
+		obj.UpdatedAt = item.GetUpdatedAt().AsTime()
+	}
+
+	return obj
+}
+
+// uploadReader adapts the upload stream to io.Reader, reading the content chunk by chunk.
+type uploadReader struct {
+	stream pb.GophKeeperService_UploadBinaryServer
+	buf    []byte
+	size   int64
+}
+
+// Read reads the content of the received chunks, requesting the next chunk when the current one is consumed.
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = req.GetChunk()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	r.size += int64(n)
+
+	return n, nil
+}
@@ -1,15 +1,35 @@
 // Package grpc provides middleware and interceptors for gRPC services,
-// including logging and monitoring capabilities.
+// including logging, monitoring and authentication capabilities.
 package grpc
 
 import (
 	"context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/status"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	authModel "gophKeeper/server/internal/domain/auth/model"
 	"log/slog"
 	"time"
 )
 
+// publicMethods lists the methods that can be called without authentication.
+var publicMethods = map[string]bool{
+	pb.GophKeeperService_Register_FullMethodName:             true,
+	pb.GophKeeperService_Login_FullMethodName:                true,
+	pb.GophKeeperService_RegisterSrp_FullMethodName:          true,
+	pb.GophKeeperService_LoginSrpStart_FullMethodName:        true,
+	pb.GophKeeperService_LoginSrpFinish_FullMethodName:       true,
+	pb.GophKeeperService_VerifyLoginTwoFactor_FullMethodName: true,
+	pb.GophKeeperService_RefreshToken_FullMethodName:         true,
+	pb.GophKeeperService_GetPasswordPolicy_FullMethodName:    true,
+	pb.GophKeeperService_Ping_FullMethodName:                 true,
+}
+
+// Authenticator validates the credentials of an incoming request and returns the authenticated principal.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*authModel.Principal, error)
+}
+
 // GrpcInterceptorLogger creates a gRPC server interceptor that logs each call,
 // outputting information about the duration of execution and the response status.
 func GrpcInterceptorLogger() grpc.UnaryServerInterceptor {
@@ -33,3 +53,55 @@ func GrpcInterceptorLogger() grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+// GrpcInterceptorAuth creates a gRPC server interceptor that authenticates unary calls
+// to non-public methods and puts the authenticated principal in the handler's context.
+func GrpcInterceptorAuth(authenticator Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// GrpcStreamInterceptorAuth creates a gRPC server interceptor that authenticates streaming calls
+// to non-public methods and puts the authenticated principal in the stream's context.
+func GrpcStreamInterceptorAuth(authenticator Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate returns the context carrying the principal of the request,
+// or the unchanged context if the method is public.
+func authenticate(ctx context.Context, authenticator Authenticator, fullMethod string) (context.Context, error) {
+	if publicMethods[fullMethod] {
+		return ctx, nil
+	}
+
+	principal, err := authenticator.Authenticate(ctx)
+	if err != nil {
+		return nil, toStatusError(fullMethod, err)
+	}
+
+	return authModel.NewContext(ctx, principal), nil
+}
+
+// authenticatedStream wraps a server stream to replace its context with the authenticated one.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the authenticated principal.
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	authModel "gophKeeper/server/internal/domain/auth/model"
+	"gophKeeper/server/internal/errs"
+	"testing"
+)
+
+type authenticatorMock struct {
+	principal *authModel.Principal
+	err       error
+}
+
+func (m *authenticatorMock) Authenticate(context.Context) (*authModel.Principal, error) {
+	return m.principal, m.err
+}
+
+func TestGrpcInterceptorAuth(t *testing.T) {
+	principal := &authModel.Principal{UserID: "999"}
+
+	tests := []struct {
+		name          string
+		authenticator Authenticator
+		method        string
+		wantCode      codes.Code
+		wantUserID    string
+	}{
+		{
+			name:          "public method without token",
+			authenticator: &authenticatorMock{err: errs.MissingToken},
+			method:        pb.GophKeeperService_Login_FullMethodName,
+			wantCode:      codes.OK,
+		},
+		{
+			name:          "protected method without token",
+			authenticator: &authenticatorMock{err: errs.MissingToken},
+			method:        pb.GophKeeperService_GetData_FullMethodName,
+			wantCode:      codes.Unauthenticated,
+		},
+		{
+			name:          "protected method with invalid token",
+			authenticator: &authenticatorMock{err: errs.InvalidToken},
+			method:        pb.GophKeeperService_GetData_FullMethodName,
+			wantCode:      codes.Unauthenticated,
+		},
+		{
+			name:          "protected method with valid token",
+			authenticator: &authenticatorMock{principal: principal},
+			method:        pb.GophKeeperService_GetData_FullMethodName,
+			wantCode:      codes.OK,
+			wantUserID:    "999",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUserID string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if p, ok := authModel.FromContext(ctx); ok {
+					gotUserID = p.UserID
+				}
+				return nil, nil
+			}
+
+			interceptor := GrpcInterceptorAuth(tt.authenticator)
+			_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("GrpcInterceptorAuth() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if gotUserID != tt.wantUserID {
+				t.Errorf("GrpcInterceptorAuth() user ID = %v, want %v", gotUserID, tt.wantUserID)
+			}
+		})
+	}
+}
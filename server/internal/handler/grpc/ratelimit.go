@@ -0,0 +1,143 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"gophKeeper/server/internal/errs"
+	"net"
+	"time"
+)
+
+// rateLimitedMethods lists the methods throttled by the rate limit interceptor,
+// the ones guessing passwords, codes or usernames could be attempted with.
+var rateLimitedMethods = map[string]bool{
+	pb.GophKeeperService_ChangePassword_FullMethodName:       true,
+	pb.GophKeeperService_ChangeUsername_FullMethodName:       true,
+	pb.GophKeeperService_DeleteAccount_FullMethodName:        true,
+	pb.GophKeeperService_Register_FullMethodName:             true,
+	pb.GophKeeperService_Login_FullMethodName:                true,
+	pb.GophKeeperService_RegisterSrp_FullMethodName:          true,
+	pb.GophKeeperService_LoginSrpStart_FullMethodName:        true,
+	pb.GophKeeperService_LoginSrpFinish_FullMethodName:       true,
+	pb.GophKeeperService_VerifyLoginTwoFactor_FullMethodName: true,
+}
+
+// failedAttemptErrors lists the errors counted as failed attempts, delaying the next ones.
+var failedAttemptErrors = []error{
+	errs.InvalidCredentials,
+	errs.InvalidPassword,
+	errs.UsernameAlreadyExists,
+	errs.InvalidTwoFactorCode,
+	errs.ChallengeNotFound,
+}
+
+// RateLimiter throttles requests by keys and delays the keys of failed attempts.
+type RateLimiter interface {
+	Allow(keys ...string) (time.Duration, bool)
+	Fail(keys ...string)
+	Reset(keys ...string)
+}
+
+// LoginUsernames finds the username the later steps of a login belong to,
+// which are sent without it.
+type LoginUsernames interface {
+	SrpLoginUsername(ctx context.Context, loginID string) (string, bool, error)
+	ChallengeUsername(ctx context.Context, challengeToken string) (string, bool, error)
+}
+
+// GrpcInterceptorRateLimit creates a gRPC server interceptor that throttles the authentication
+// methods by the address of the client and by the username of the request, the SRP login
+// or the two-factor challenge. Failed attempts block their keys for a growing delay,
+// logins completed with a token pair lift the delay of the username.
+// Throttled calls are rejected with errs.TooManyRequests carrying the delay to retry after.
+func GrpcInterceptorRateLimit(limiter RateLimiter, usernames LoginUsernames) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !rateLimitedMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		keys := []string{"peer:" + peerHost(ctx)}
+
+		username, err := requestUsername(ctx, usernames, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var usernameKey string
+		if username != "" {
+			usernameKey = "username:" + username
+			keys = append(keys, usernameKey)
+		}
+
+		if wait, ok := limiter.Allow(keys...); !ok {
+			return nil, &errs.RetryError{Reason: errs.TooManyRequests, RetryAfter: wait}
+		}
+
+		resp, err := handler(ctx, req)
+		switch {
+		case err == nil && usernameKey != "" && isCompletedLogin(resp):
+			limiter.Reset(usernameKey)
+		case isFailedAttempt(err):
+			limiter.Fail(keys...)
+		}
+
+		return resp, err
+	}
+}
+
+// requestUsername returns the username the request is made for, the one of the pending login
+// for the later login steps. It is empty if the request has no username or the login is not pending.
+func requestUsername(ctx context.Context, usernames LoginUsernames, req interface{}) (string, error) {
+	switch r := req.(type) {
+	case *pb.LoginSrpFinishRequest:
+		if r.GetLoginId() == "" {
+			return "", nil
+		}
+		username, _, err := usernames.SrpLoginUsername(ctx, r.GetLoginId())
+		return username, err
+	case *pb.VerifyLoginTwoFactorRequest:
+		if r.GetChallengeToken() == "" {
+			return "", nil
+		}
+		username, _, err := usernames.ChallengeUsername(ctx, r.GetChallengeToken())
+		return username, err
+	case interface{ GetUsername() string }:
+		return r.GetUsername(), nil
+	}
+	return "", nil
+}
+
+// isCompletedLogin reports whether the response completes a login with a token pair, after all
+// the credentials were verified. The first step of a two-factor login is not completed yet.
+func isCompletedLogin(resp interface{}) bool {
+	r, ok := resp.(*pb.LoginResponse)
+	return ok && r.GetToken() != ""
+}
+
+// isFailedAttempt reports whether the error means that the guessed credentials or code were wrong.
+func isFailedAttempt(err error) bool {
+	for _, target := range failedAttemptErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerHost returns the address of the client without the port.
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
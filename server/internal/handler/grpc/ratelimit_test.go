@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	pb "gophKeeper/pkg/proto/gophkeeper"
+	"gophKeeper/server/internal/errs"
+	"gophKeeper/server/internal/ratelimit"
+	"net"
+	"testing"
+	"time"
+)
+
+type loginUsernamesMock struct {
+	logins     map[string]string
+	challenges map[string]string
+}
+
+func (m *loginUsernamesMock) SrpLoginUsername(_ context.Context, loginID string) (string, bool, error) {
+	username, found := m.logins[loginID]
+	return username, found, nil
+}
+
+func (m *loginUsernamesMock) ChallengeUsername(_ context.Context, challengeToken string) (string, bool, error) {
+	username, found := m.challenges[challengeToken]
+	return username, found, nil
+}
+
+func TestGrpcInterceptorRateLimit(t *testing.T) {
+	interceptor := GrpcInterceptorRateLimit(ratelimit.New(0, 0, time.Minute, time.Hour), &loginUsernamesMock{})
+
+	call := func(method, username, address string, handlerErr error) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(address), Port: 1234}})
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		}
+
+		_, err := interceptor(ctx, &pb.LoginRequest{Username: username}, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	login := pb.GophKeeperService_Login_FullMethodName
+
+	if err := call(login, "alice", "10.0.0.1", errs.InvalidCredentials); !errors.Is(err, errs.InvalidCredentials) {
+		t.Fatalf("first attempt error = %v, want %v", err, errs.InvalidCredentials)
+	}
+
+	var retryErr *errs.RetryError
+	err := call(login, "alice", "10.0.0.2", nil)
+	if !errors.As(err, &retryErr) || retryErr.Reason != errs.TooManyRequests || retryErr.RetryAfter <= 0 {
+		t.Errorf("attempt of the same username error = %v, want %v with a delay", err, errs.TooManyRequests)
+	}
+	if err = call(login, "bob", "10.0.0.1", nil); !errors.Is(err, errs.TooManyRequests) {
+		t.Errorf("attempt from the same address error = %v, want %v", err, errs.TooManyRequests)
+	}
+	if err = call(login, "bob", "10.0.0.3", nil); err != nil {
+		t.Errorf("attempt of another username and address error = %v, want nil", err)
+	}
+	if err = call(pb.GophKeeperService_GetData_FullMethodName, "alice", "10.0.0.1", nil); err != nil {
+		t.Errorf("not throttled method error = %v, want nil", err)
+	}
+}
+
+func TestGrpcInterceptorRateLimit_LoginSteps(t *testing.T) {
+	interceptor := GrpcInterceptorRateLimit(ratelimit.New(0, 0, time.Minute, time.Hour), &loginUsernamesMock{
+		logins:     map[string]string{"login-alice": "alice"},
+		challenges: map[string]string{"challenge-bob": "bob", "challenge-carol": "carol"},
+	})
+
+	call := func(method string, req interface{}, address string, resp interface{}, handlerErr error) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(address), Port: 1234}})
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return resp, handlerErr
+		}
+
+		_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	login := pb.GophKeeperService_Login_FullMethodName
+	finish := pb.GophKeeperService_LoginSrpFinish_FullMethodName
+	verify := pb.GophKeeperService_VerifyLoginTwoFactor_FullMethodName
+
+	// a wrong proof of the handshake counts against the username it was started for
+	call(finish, &pb.LoginSrpFinishRequest{LoginId: "login-alice"}, "10.0.0.1", nil, errs.InvalidCredentials)
+	if err := call(login, &pb.LoginRequest{Username: "alice"}, "10.0.0.2", nil, nil); !errors.Is(err, errs.TooManyRequests) {
+		t.Errorf("login after a failed srp finish error = %v, want %v", err, errs.TooManyRequests)
+	}
+
+	// as does a wrong code of the two-factor challenge
+	call(verify, &pb.VerifyLoginTwoFactorRequest{ChallengeToken: "challenge-bob"}, "10.0.0.3", nil, errs.InvalidTwoFactorCode)
+	if err := call(login, &pb.LoginRequest{Username: "bob"}, "10.0.0.4", nil, nil); !errors.Is(err, errs.TooManyRequests) {
+		t.Errorf("login after a failed two-factor code error = %v, want %v", err, errs.TooManyRequests)
+	}
+
+	// the first step of a two-factor login does not lift the delay, the completed login does
+	limiter := &rateLimiterMock{}
+	interceptor = GrpcInterceptorRateLimit(limiter, &loginUsernamesMock{challenges: map[string]string{"challenge-carol": "carol"}})
+	call(login, &pb.LoginRequest{Username: "carol"}, "10.0.0.5", &pb.LoginResponse{TwoFactorChallenge: "challenge-carol"}, nil)
+	if len(limiter.reset) != 0 {
+		t.Errorf("keys reset by the first login step = %v, want none", limiter.reset)
+	}
+	call(verify, &pb.VerifyLoginTwoFactorRequest{ChallengeToken: "challenge-carol"}, "10.0.0.5", &pb.LoginResponse{Token: "token"}, nil)
+	if len(limiter.reset) != 1 || limiter.reset[0] != "username:carol" {
+		t.Errorf("keys reset by the completed login = %v, want [username:carol]", limiter.reset)
+	}
+}
+
+type rateLimiterMock struct {
+	reset []string
+}
+
+func (m *rateLimiterMock) Allow(_ ...string) (time.Duration, bool) {
+	return 0, true
+}
+
+func (m *rateLimiterMock) Fail(_ ...string) {}
+
+func (m *rateLimiterMock) Reset(keys ...string) {
+	m.reset = append(m.reset, keys...)
+}
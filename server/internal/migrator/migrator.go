@@ -0,0 +1,142 @@
+// Package migrator applies the embedded database migrations with golang-migrate.
+// Every operation runs under a PostgreSQL advisory lock, so several server replicas
+// starting at the same time migrate the schema one after another.
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"gophKeeper/server/migrations"
+	"time"
+)
+
+// lockKey is the key of the advisory lock taken while migrating, shared by all the replicas.
+const lockKey int64 = 0x676f70684b6d6967
+
+// ErrLockTimeout is returned when the advisory lock is not acquired within the lock timeout.
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// Migrator applies the embedded migrations to the database.
+type Migrator struct {
+	db          *sql.DB
+	migrate     *migrate.Migrate
+	lockTimeout time.Duration
+}
+
+// New creates a Migrator for the database with the given connection string.
+// Waiting for the advisory lock fails with ErrLockTimeout after lockTimeout.
+// The caller must close the Migrator.
+func New(dsn string, lockTimeout time.Duration) (*Migrator, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create database driver: %w", err)
+	}
+
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		_ = driver.Close()
+		return nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		_ = source.Close()
+		_ = driver.Close()
+		return nil, fmt.Errorf("create migrate: %w", err)
+	}
+
+	return &Migrator{
+		db:          db,
+		migrate:     m,
+		lockTimeout: lockTimeout,
+	}, nil
+}
+
+// Up applies all the migrations that have not been applied yet.
+// It succeeds if the schema is already up to date.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		return ignoreNoChange(m.migrate.Up())
+	})
+}
+
+// Down rolls back the given number of the applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	return m.withLock(ctx, func() error {
+		return ignoreNoChange(m.migrate.Steps(-steps))
+	})
+}
+
+// Force sets the schema version without running any migration and clears the dirty flag.
+// It is used to recover after a migration failed halfway.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	return m.withLock(ctx, func() error {
+		return m.migrate.Force(version)
+	})
+}
+
+// Version returns the current schema version and whether the last migration failed halfway.
+// The version is zero if no migration has been applied yet.
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Close releases the database connections.
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// withLock runs fn holding the advisory lock on a dedicated connection.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close()
+
+	lockCtx, cancel := context.WithTimeout(ctx, m.lockTimeout)
+	defer cancel()
+
+	if _, err = conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		if lockCtx.Err() != nil && ctx.Err() == nil {
+			return ErrLockTimeout
+		}
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+		if unlockErr != nil && err == nil {
+			err = fmt.Errorf("release migration lock: %w", unlockErr)
+		}
+	}()
+
+	return fn()
+}
+
+// ignoreNoChange treats the schema being already at the requested version as a success.
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+// Package ratelimit implements the in-memory throttling of the authentication requests:
+// a token bucket per key limiting the request rate, and an exponential backoff blocking
+// the key for a growing delay after every failed attempt.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// cleanupInterval is how often the keys that returned to their initial state are forgotten.
+const cleanupInterval = time.Minute
+
+// Limiter throttles requests by keys, such as the address of the client or the username.
+// It is safe for concurrent use.
+type Limiter struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	now     func() time.Time
+
+	refill      time.Duration
+	burst       float64
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	lastCleanup time.Time
+}
+
+// entry holds the state of a key: the tokens left in its bucket and its failed attempts.
+type entry struct {
+	tokens       float64
+	updatedAt    time.Time
+	failures     int
+	blockedUntil time.Time
+}
+
+// New creates a new Limiter allowing perMinute requests per minute of every key with bursts
+// of up to burst requests. After the n-th consecutive failure a key is blocked for
+// backoffBase * 2^(n-1), at most for backoffMax. Failures are forgotten once a key
+// stays without failures for backoffMax. A zero perMinute disables the rate limit
+// and a zero backoffBase disables the backoff.
+func New(perMinute, burst int, backoffBase, backoffMax time.Duration) *Limiter {
+	l := &Limiter{
+		entries:     map[string]*entry{},
+		now:         time.Now,
+		burst:       float64(max(burst, 1)),
+		backoffBase: backoffBase,
+		backoffMax:  max(backoffMax, backoffBase),
+	}
+	if perMinute > 0 {
+		l.refill = time.Minute / time.Duration(perMinute)
+	}
+
+	return l
+}
+
+// Allow reports whether a request of all the keys is allowed, taking a token of every key
+// if it is. Otherwise, no token is taken and the delay after which the request may be
+// allowed is returned.
+func (l *Limiter) Allow(keys ...string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	var wait time.Duration
+	for _, key := range keys {
+		e := l.entry(key, now)
+
+		if now.Before(e.blockedUntil) {
+			wait = max(wait, e.blockedUntil.Sub(now))
+		}
+		if l.refill > 0 && e.tokens < 1 {
+			wait = max(wait, time.Duration((1-e.tokens)*float64(l.refill)))
+		}
+	}
+	if wait > 0 {
+		return wait, false
+	}
+
+	if l.refill > 0 {
+		for _, key := range keys {
+			l.entries[key].tokens--
+		}
+	}
+
+	return 0, true
+}
+
+// Fail records a failed attempt of the keys, blocking them for the backoff delay.
+func (l *Limiter) Fail(keys ...string) {
+	if l.backoffBase <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for _, key := range keys {
+		e := l.entry(key, now)
+		e.failures++
+		e.blockedUntil = now.Add(l.backoff(e.failures))
+	}
+}
+
+// Reset forgets the failed attempts of the keys, lifting their backoff.
+func (l *Limiter) Reset(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if e, ok := l.entries[key]; ok {
+			e.failures = 0
+			e.blockedUntil = time.Time{}
+		}
+	}
+}
+
+// entry returns the state of the key, refilling its bucket and forgetting its old failures.
+func (l *Limiter) entry(key string, now time.Time) *entry {
+	e, ok := l.entries[key]
+	if !ok {
+		e = &entry{tokens: l.burst, updatedAt: now}
+		l.entries[key] = e
+	}
+
+	if l.refill > 0 {
+		e.tokens = min(l.burst, e.tokens+float64(now.Sub(e.updatedAt))/float64(l.refill))
+	}
+	e.updatedAt = now
+
+	if e.failures > 0 && now.Sub(e.blockedUntil) > l.backoffMax {
+		e.failures = 0
+	}
+
+	return e
+}
+
+// backoff returns the delay a key is blocked for after the given number of consecutive failures.
+func (l *Limiter) backoff(failures int) time.Duration {
+	delay := l.backoffBase
+	for i := 1; i < failures && delay < l.backoffMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, l.backoffMax)
+}
+
+// cleanup periodically forgets the keys with a full bucket and no failures,
+// so the memory does not grow with the number of clients.
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < cleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+
+	for key, e := range l.entries {
+		full := l.refill == 0 || float64(now.Sub(e.updatedAt))/float64(l.refill)+e.tokens >= l.burst
+		if full && now.Sub(e.blockedUntil) > l.backoffMax {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(60, 2, 0, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, ok := l.Allow("peer"); !ok {
+			t.Fatalf("Allow() #%d = false, want true within the burst", i)
+		}
+	}
+
+	wait, ok := l.Allow("peer")
+	if ok || wait != time.Second {
+		t.Errorf("Allow() = %v, %v, want %v, false", wait, ok, time.Second)
+	}
+	if _, ok = l.Allow("other"); !ok {
+		t.Errorf("Allow() of another key = false, want true")
+	}
+	if _, ok = l.Allow("other", "peer"); ok {
+		t.Errorf("Allow() of keys with an exhausted one = true, want false")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok = l.Allow("peer"); !ok {
+		t.Errorf("Allow() after the refill = false, want true")
+	}
+}
+
+func TestLimiter_Fail(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(0, 0, time.Second, 4*time.Second)
+	l.now = func() time.Time { return now }
+
+	tests := []struct {
+		name     string
+		wantWait time.Duration
+	}{
+		{name: "first failure", wantWait: time.Second},
+		{name: "second failure", wantWait: 2 * time.Second},
+		{name: "third failure", wantWait: 4 * time.Second},
+		{name: "capped failure", wantWait: 4 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l.Fail("alice")
+
+			wait, ok := l.Allow("alice")
+			if ok || wait != tt.wantWait {
+				t.Errorf("Allow() = %v, %v, want %v, false", wait, ok, tt.wantWait)
+			}
+
+			now = now.Add(tt.wantWait)
+			if _, ok = l.Allow("alice"); !ok {
+				t.Errorf("Allow() after the backoff = false, want true")
+			}
+		})
+	}
+
+	l.Reset("alice")
+	l.Fail("alice")
+	if wait, _ := l.Allow("alice"); wait != time.Second {
+		t.Errorf("Allow() after Reset() wait = %v, want %v", wait, time.Second)
+	}
+
+	now = now.Add(time.Minute)
+	l.Fail("alice")
+	if wait, _ := l.Allow("alice"); wait != time.Second {
+		t.Errorf("Allow() after a quiet period wait = %v, want %v", wait, time.Second)
+	}
+}
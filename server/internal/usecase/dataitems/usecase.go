@@ -6,6 +6,9 @@ package dataitems
 import (
 	"context"
 	"gophKeeper/server/internal/domain/dataitems/model"
+	"gophKeeper/server/internal/errs"
+	"io"
+	"time"
 )
 
 // Usecase provides the business logic for managing data items,
@@ -26,10 +29,25 @@ func New(dataItemsService DataItemsServiceI) *Usecase {
 // retrieving, updating, and deleting operations.
 type DataItemsServiceI interface {
 	List(ctx context.Context, pars *model.ListPars) ([]*model.DataItems, int64, error)
+	ListPage(ctx context.Context, pars *model.ListPars, pageToken string, pageSize int) ([]*model.DataItems, string, error)
 	Create(ctx context.Context, obj *model.Edit) error
 	Get(ctx context.Context, pars *model.GetPars) (*model.DataItems, bool, error)
 	Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) error
 	Delete(ctx context.Context, pars *model.GetPars) error
+	Restore(ctx context.Context, pars *model.GetPars) error
+	ListTrash(ctx context.Context, userID string) ([]*model.DataItems, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+	Sync(ctx context.Context, userID string, items []*model.DataItems, lastSyncedAt time.Time) (*model.SyncResult, error)
+	Upload(ctx context.Context, obj *model.Edit, reader io.Reader, size int64) error
+	Download(ctx context.Context, pars *model.GetPars) (*model.DataItems, io.ReadCloser, int64, bool, error)
+	ListVersions(ctx context.Context, pars *model.VersionPars) ([]*model.DataItems, error)
+	GetVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, bool, error)
+	RestoreVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, error)
+	DownloadVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, io.ReadCloser, int64, bool, error)
+	Search(ctx context.Context, pars *model.SearchPars) ([]*model.DataItems, error)
+	CreateFolder(ctx context.Context, userID, parentID, name string) (*model.Folder, error)
+	ListFolders(ctx context.Context, userID string) ([]*model.Folder, error)
+	DeleteFolder(ctx context.Context, userID, id string) error
 }
 
 // GetData retrieves a data item based on the provided query parameters.
@@ -42,19 +60,103 @@ func (u *Usecase) ListAll(ctx context.Context, obj *model.ListPars) ([]*model.Da
 	return u.dataItemsService.List(ctx, obj)
 }
 
+// ListPage retrieves a page of data items based on the provided filters, returning the items
+// and the token of the next page.
+func (u *Usecase) ListPage(ctx context.Context, pars *model.ListPars, pageToken string, pageSize int) ([]*model.DataItems, string, error) {
+	return u.dataItemsService.ListPage(ctx, pars, pageToken, pageSize)
+}
+
 // CreateData creates a new data item using the provided model.Edit object.
 func (u *Usecase) CreateData(ctx context.Context, obj *model.Edit) error {
 	return u.dataItemsService.Create(ctx, obj)
 }
 
 // EditData updates an existing data item identified by the provided model.Edit object.
+// Only the item owned by the user set in the object can be updated.
 func (u *Usecase) EditData(ctx context.Context, obj *model.Edit) error {
+	if obj.UserID == nil || *obj.UserID == "" {
+		return errs.InvalidInput
+	}
+
 	return u.dataItemsService.Update(ctx, &model.GetPars{
-		ID: obj.ID,
+		ID:     obj.ID,
+		UserID: *obj.UserID,
 	}, obj)
 }
 
-// DeleteData deletes a data item based on the provided query parameters.
+// DeleteData moves a data item based on the provided query parameters to the trash.
 func (u *Usecase) DeleteData(ctx context.Context, obj *model.GetPars) error {
 	return u.dataItemsService.Delete(ctx, obj)
 }
+
+// RestoreData moves a data item based on the provided query parameters back from the trash.
+func (u *Usecase) RestoreData(ctx context.Context, obj *model.GetPars) error {
+	return u.dataItemsService.Restore(ctx, obj)
+}
+
+// ListTrash retrieves the data items of the user that are in the trash.
+func (u *Usecase) ListTrash(ctx context.Context, userID string) ([]*model.DataItems, error) {
+	return u.dataItemsService.ListTrash(ctx, userID)
+}
+
+// PurgeTrash permanently removes the data items that have been in the trash longer than the retention period.
+// It returns the number of purged items.
+func (u *Usecase) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	return u.dataItemsService.Purge(ctx, time.Now().Add(-retention))
+}
+
+// SyncData reconciles the items changed on the client with the items stored on the server
+// for the given user.
+func (u *Usecase) SyncData(ctx context.Context, userID string, items []*model.DataItems, lastSyncedAt time.Time) (*model.SyncResult, error) {
+	return u.dataItemsService.Sync(ctx, userID, items, lastSyncedAt)
+}
+
+// UploadBinary stores the binary content read from the reader, creating the item or replacing its content.
+func (u *Usecase) UploadBinary(ctx context.Context, obj *model.Edit, reader io.Reader, size int64) error {
+	return u.dataItemsService.Upload(ctx, obj, reader, size)
+}
+
+// DownloadBinary opens the binary content of the item identified by the provided query parameters.
+func (u *Usecase) DownloadBinary(ctx context.Context, pars *model.GetPars) (*model.DataItems, io.ReadCloser, int64, bool, error) {
+	return u.dataItemsService.Download(ctx, pars)
+}
+
+// ListVersions retrieves the versions of the data item, newest first.
+func (u *Usecase) ListVersions(ctx context.Context, pars *model.VersionPars) ([]*model.DataItems, error) {
+	return u.dataItemsService.ListVersions(ctx, pars)
+}
+
+// GetVersion retrieves a single version of the data item.
+func (u *Usecase) GetVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, bool, error) {
+	return u.dataItemsService.GetVersion(ctx, pars)
+}
+
+// RestoreVersion makes the content of the version of the data item current again.
+func (u *Usecase) RestoreVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, error) {
+	return u.dataItemsService.RestoreVersion(ctx, pars)
+}
+
+// DownloadVersion opens the binary content of the version of the data item.
+func (u *Usecase) DownloadVersion(ctx context.Context, pars *model.VersionPars) (*model.DataItems, io.ReadCloser, int64, bool, error) {
+	return u.dataItemsService.DownloadVersion(ctx, pars)
+}
+
+// SearchData retrieves the data items of the user matching the structured metadata filters.
+func (u *Usecase) SearchData(ctx context.Context, pars *model.SearchPars) ([]*model.DataItems, error) {
+	return u.dataItemsService.Search(ctx, pars)
+}
+
+// CreateFolder creates a new folder of the user inside the parent folder, or a top-level one.
+func (u *Usecase) CreateFolder(ctx context.Context, userID, parentID, name string) (*model.Folder, error) {
+	return u.dataItemsService.CreateFolder(ctx, userID, parentID, name)
+}
+
+// ListFolders retrieves all the folders of the user.
+func (u *Usecase) ListFolders(ctx context.Context, userID string) ([]*model.Folder, error) {
+	return u.dataItemsService.ListFolders(ctx, userID)
+}
+
+// DeleteFolder removes the folder of the user together with its subfolders.
+func (u *Usecase) DeleteFolder(ctx context.Context, userID, id string) error {
+	return u.dataItemsService.DeleteFolder(ctx, userID, id)
+}
@@ -5,22 +5,38 @@ package users
 
 import (
 	"context"
+	"gophKeeper/pkg/password"
+	authModel "gophKeeper/server/internal/domain/auth/model"
+	sessionsModel "gophKeeper/server/internal/domain/sessions/model"
+	twoFactorModel "gophKeeper/server/internal/domain/twofactor/model"
 	"gophKeeper/server/internal/domain/users/model"
 	"gophKeeper/server/internal/errs"
+	"time"
 )
 
+// dummyPasswordHash is a bcrypt hash compared against on logins with an unknown username.
+const dummyPasswordHash = "$2a$10$DDpriwrCQXbdytcNezZ7MebJddux3r2hpj.Zo9kwv2hIbSP/tQ61i"
+
 // Usecase provides the business logic for managing users and handling
 // authentication, using the user and authentication services to perform operations.
 type Usecase struct {
-	usersService UsersServiceI
-	authService  AuthServiceI
+	usersService     UsersServiceI
+	authService      AuthServiceI
+	sessionsService  SessionsServiceI
+	twoFactorService TwoFactorServiceI
+	dataItemsService DataItemsServiceI
 }
 
-// New creates a new Usecase instance with the provided user and authentication services.
-func New(usersService UsersServiceI, authService AuthServiceI) *Usecase {
+// New creates a new Usecase instance with the provided user, authentication, session,
+// two-factor authentication and data item services.
+func New(usersService UsersServiceI, authService AuthServiceI, sessionsService SessionsServiceI, twoFactorService TwoFactorServiceI,
+	dataItemsService DataItemsServiceI) *Usecase {
 	return &Usecase{
-		usersService: usersService,
-		authService:  authService,
+		usersService:     usersService,
+		authService:      authService,
+		sessionsService:  sessionsService,
+		twoFactorService: twoFactorService,
+		dataItemsService: dataItemsService,
 	}
 }
 
@@ -29,7 +45,10 @@ func New(usersService UsersServiceI, authService AuthServiceI) *Usecase {
 // checking if a username is already taken.
 type UsersServiceI interface {
 	IsValidPassword(password string, plainPassword string) bool
+	ValidatePassword(username string, plainPassword string) error
+	PasswordPolicy() password.Policy
 	HashPassword(password string) (string, error)
+	NewKdfSalt() ([]byte, error)
 	IsLoginTaken(ctx context.Context, username string) (bool, error)
 	List(ctx context.Context, pars *model.ListPars) ([]*model.User, int64, error)
 	Create(ctx context.Context, obj *model.Edit) error
@@ -37,20 +56,60 @@ type UsersServiceI interface {
 	Update(ctx context.Context, pars *model.GetPars, obj *model.Edit) error
 	Delete(ctx context.Context, pars *model.GetPars) error
 	Exists(ctx context.Context, pars *model.GetPars) (bool, error)
+	ValidateSrpVerifier(salt, verifier []byte) error
+	StartSrpLogin(ctx context.Context, user *model.User, clientPublic []byte) (*model.SrpChallenge, error)
+	FakeSrpChallenge(username string) (*model.SrpChallenge, error)
+	SrpLoginUserID(ctx context.Context, loginID string) (string, bool, error)
+	FinishSrpLogin(ctx context.Context, loginID string, clientProof []byte) (*model.User, []byte, error)
+	CheckLocked(user *model.User) error
+	RecordFailedLogin(ctx context.Context, user *model.User) error
+	ResetFailedLogins(ctx context.Context, user *model.User) error
+	SetKeyCheck(ctx context.Context, userID string, keyCheck []byte) error
 }
 
 // AuthServiceI defines the interface for authentication operations,
-// including extracting the user ID from context and creating JWT tokens.
+// including authenticating requests and creating JWT tokens.
 type AuthServiceI interface {
-	GetUserIDFromContext(ctx context.Context) (string, error)
-	CreateToken(u *model.User) (string, error)
+	Authenticate(ctx context.Context) (*authModel.Principal, error)
+	CreateToken(u *model.User, sessionID string) (string, time.Time, error)
+}
+
+// SessionsServiceI defines the interface for session management operations,
+// including starting sessions, rotating refresh tokens and revoking sessions.
+type SessionsServiceI interface {
+	Create(ctx context.Context, userID, userAgent string) (*sessionsModel.Session, string, error)
+	Rotate(ctx context.Context, refreshToken string) (*sessionsModel.Session, string, error)
+	IsActive(ctx context.Context, sessionID string) (bool, error)
+	List(ctx context.Context, userID string) ([]*sessionsModel.Session, error)
+	Revoke(ctx context.Context, pars *sessionsModel.GetPars) (bool, error)
+	RevokeAll(ctx context.Context, userID string, exceptSessionID string) error
+}
+
+// TwoFactorServiceI defines the interface for two-factor authentication operations,
+// including enrolling TOTP secrets and completing the second step of logins.
+type TwoFactorServiceI interface {
+	Enroll(ctx context.Context, userID string, account string) (*twoFactorModel.Enrollment, error)
+	Confirm(ctx context.Context, userID string, code string) ([]string, error)
+	Disable(ctx context.Context, userID string, code string) error
+	IsEnabled(ctx context.Context, userID string) (bool, error)
+	CreateChallenge(ctx context.Context, userID string) (string, time.Time, error)
+	ChallengeUserID(ctx context.Context, token string) (string, bool, error)
+	VerifyChallenge(ctx context.Context, token string, code string) (string, error)
+}
+
+// DataItemsServiceI defines the interface for the data item operations needed to delete accounts.
+type DataItemsServiceI interface {
+	DeleteUserFiles(ctx context.Context, userID string) error
 }
 
 // Register registers a new user by checking if the username is available,
 // hashing the password, and creating the user in the database.
 func (u *Usecase) Register(ctx context.Context, username string, password string) error {
-	if username == "" || password == "" {
-		return errs.InvalidInput
+	if err := validateCredentials(username, password); err != nil {
+		return err
+	}
+	if err := u.usersService.ValidatePassword(username, password); err != nil {
+		return err
 	}
 
 	taken, err := u.usersService.IsLoginTaken(ctx, username)
@@ -66,9 +125,15 @@ func (u *Usecase) Register(ctx context.Context, username string, password string
 		return err
 	}
 
+	kdfSalt, err := u.usersService.NewKdfSalt()
+	if err != nil {
+		return err
+	}
+
 	err = u.usersService.Create(ctx, &model.Edit{
 		Username:     &username,
 		PasswordHash: &passwordHash,
+		KdfSalt:      &kdfSalt,
 	})
 	if err != nil {
 		return err
@@ -88,11 +153,15 @@ func (u *Usecase) Register(ctx context.Context, username string, password string
 	return nil
 }
 
-// Login handles user login by validating the username and password,
-// and generating a JWT token if the credentials are correct.
-func (u *Usecase) Login(ctx context.Context, username string, password string) (*string, error) {
-	if username == "" || password == "" {
-		return nil, errs.InvalidInput
+// Login handles user login by validating the username and password, and starting a new session
+// with a token pair if the credentials are correct. Along with the tokens it returns
+// the user's kdf salt, generating one for accounts created before it existed.
+// Users with two-factor authentication get a challenge to complete with VerifyLoginTwoFactor instead.
+// Wrong passwords count as failed logins, errs.AccountLocked is returned for the right password
+// once the account is locked.
+func (u *Usecase) Login(ctx context.Context, username string, password string, userAgent string) (*model.LoginResult, error) {
+	if err := validateCredentials(username, password); err != nil {
+		return nil, err
 	}
 
 	user, found, err := u.usersService.Get(ctx, &model.GetPars{
@@ -102,24 +171,597 @@ func (u *Usecase) Login(ctx context.Context, username string, password string) (
 		return nil, err
 	}
 	if !found {
-		return nil, errs.UserNotFound
+		// compare against a dummy hash, so unknown usernames take as long as wrong passwords
+		u.usersService.IsValidPassword(dummyPasswordHash, password)
+		return nil, errs.InvalidCredentials
+	}
+
+	if user.PasswordHash == "" {
+		// accounts migrated to SRP can not log in with the password anymore
+		u.usersService.IsValidPassword(dummyPasswordHash, password)
+		return nil, errs.InvalidCredentials
 	}
 
 	isValidPassword := u.usersService.IsValidPassword(user.PasswordHash, password)
 	if !isValidPassword {
-		return nil, errs.InvalidPassword
+		if err = u.usersService.RecordFailedLogin(ctx, user); err != nil {
+			return nil, err
+		}
+		return nil, errs.InvalidCredentials
+	}
+
+	// the lockout is reported only to those who know the password, so it does not reveal the account
+	if err = u.usersService.CheckLocked(user); err != nil {
+		return nil, err
+	}
+
+	return u.completeLogin(ctx, user, userAgent)
+}
+
+// RegisterSrp registers a new user with the SRP salt and verifier computed by the client,
+// so the password never reaches the server.
+func (u *Usecase) RegisterSrp(ctx context.Context, username string, salt []byte, verifier []byte) error {
+	if username == "" {
+		return &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "username", Description: "must not be empty"}}}
+	}
+	if err := u.usersService.ValidateSrpVerifier(salt, verifier); err != nil {
+		return err
+	}
+
+	taken, err := u.usersService.IsLoginTaken(ctx, username)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errs.UsernameAlreadyExists
+	}
+
+	kdfSalt, err := u.usersService.NewKdfSalt()
+	if err != nil {
+		return err
+	}
+
+	return u.usersService.Create(ctx, &model.Edit{
+		Username:    &username,
+		KdfSalt:     &kdfSalt,
+		SrpSalt:     &salt,
+		SrpVerifier: &verifier,
+	})
+}
+
+// LoginSrpStart starts an SRP login with the client's public ephemeral value, returning the challenge
+// the client computes its proof from. Unknown usernames get a fake challenge, so they can not be told
+// apart from wrong passwords, and the login fails on the second step. Accounts registered with a password
+// get a challenge with PasswordLogin set, the clients then log in with the password, which sets the SRP
+// verifier of such accounts. Only an explicit answer may make the clients send the password, a failed
+// proof never does. Locked accounts are reported by LoginSrpFinish after a valid proof.
+func (u *Usecase) LoginSrpStart(ctx context.Context, username string, clientPublic []byte) (*model.SrpChallenge, error) {
+	if username == "" {
+		return nil, &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "username", Description: "must not be empty"}}}
+	}
+
+	user, found, err := u.usersService.Get(ctx, &model.GetPars{
+		Username: username,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return u.usersService.FakeSrpChallenge(username)
+	}
+	if !user.UsesSrp() {
+		return &model.SrpChallenge{PasswordLogin: true}, nil
+	}
+
+	return u.usersService.StartSrpLogin(ctx, user, clientPublic)
+}
+
+// LoginSrpFinish checks the client proof of the SRP login and starts a new session with a token pair
+// if it is valid. Along with the tokens it returns the server proof and the user's kdf salt.
+// Users with two-factor authentication get the server proof and a challenge instead of the tokens.
+func (u *Usecase) LoginSrpFinish(ctx context.Context, loginID string, clientProof []byte, userAgent string) (*model.LoginResult, error) {
+	if loginID == "" || len(clientProof) == 0 {
+		return nil, errs.InvalidInput
+	}
+
+	user, serverProof, err := u.usersService.FinishSrpLogin(ctx, loginID, clientProof)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.completeLogin(ctx, user, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	result.ServerProof = serverProof
+
+	return result, nil
+}
+
+// SetSrpVerifier replaces the password of an account registered with a password with the SRP salt
+// and verifier computed by the client, after re-verifying the password with the proof. The password
+// hash is removed, so the account can only log in with SRP afterwards, and the other sessions
+// of the user are revoked. Accounts using SRP already get errs.SrpAlreadyEnabled,
+// their password is changed with ChangePassword.
+func (u *Usecase) SetSrpVerifier(ctx context.Context, principal *authModel.Principal, proof *model.PasswordProof,
+	salt []byte, verifier []byte) error {
+	if err := u.usersService.ValidateSrpVerifier(salt, verifier); err != nil {
+		return err
+	}
+
+	user, found, err := u.usersService.Get(ctx, &model.GetPars{
+		UserID: principal.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errs.UserNotFound
+	}
+	if user.UsesSrp() {
+		return errs.SrpAlreadyEnabled
+	}
+
+	if _, _, err = u.verifyPassword(ctx, user.UserID, proof); err != nil {
+		return err
+	}
+
+	noPasswordHash := ""
+	now := time.Now()
+
+	err = u.usersService.Update(ctx, &model.GetPars{
+		UserID: user.UserID,
+	}, &model.Edit{
+		PasswordHash: &noPasswordHash,
+		SrpSalt:      &salt,
+		SrpVerifier:  &verifier,
+		UpdatedAt:    &now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.sessionsService.RevokeAll(ctx, user.UserID, principal.SessionID)
+}
+
+// SetKeyCheck stores the key check sealed by the client with the vault key of the user,
+// so the clients on other machines verify the master password on their first login.
+// The key check is set once, errs.KeyCheckAlreadySet is returned if the user has one.
+func (u *Usecase) SetKeyCheck(ctx context.Context, principal *authModel.Principal, keyCheck []byte) error {
+	return u.usersService.SetKeyCheck(ctx, principal.UserID, keyCheck)
+}
+
+// PasswordPolicy returns the requirements to new passwords, published to the clients
+// as they check the passwords of SRP accounts themselves.
+func (u *Usecase) PasswordPolicy() password.Policy {
+	return u.usersService.PasswordPolicy()
+}
+
+// SrpLoginUsername returns the username of the user the pending SRP login belongs to,
+// a boolean indicating whether the login is pending, and any error encountered.
+func (u *Usecase) SrpLoginUsername(ctx context.Context, loginID string) (string, bool, error) {
+	userID, found, err := u.usersService.SrpLoginUserID(ctx, loginID)
+	if err != nil || !found {
+		return "", false, err
+	}
+
+	return u.username(ctx, userID)
+}
+
+// ChallengeUsername returns the username of the user the pending login challenge belongs to,
+// a boolean indicating whether the challenge is pending, and any error encountered.
+func (u *Usecase) ChallengeUsername(ctx context.Context, challengeToken string) (string, bool, error) {
+	userID, found, err := u.twoFactorService.ChallengeUserID(ctx, challengeToken)
+	if err != nil || !found {
+		return "", false, err
+	}
+
+	return u.username(ctx, userID)
+}
+
+// username returns the username of the user, a boolean indicating the user's existence, and any error encountered.
+func (u *Usecase) username(ctx context.Context, userID string) (string, bool, error) {
+	user, found, err := u.usersService.Get(ctx, &model.GetPars{
+		UserID: userID,
+	})
+	if err != nil || !found {
+		return "", false, err
+	}
+
+	return user.Username, true, nil
+}
+
+// VerifyLoginTwoFactor completes the login challenge with a TOTP or recovery code and starts
+// a new session with a token pair. Along with the tokens it returns the user's kdf salt.
+func (u *Usecase) VerifyLoginTwoFactor(ctx context.Context, challengeToken string, code string, userAgent string) (*model.LoginResult, error) {
+	if challengeToken == "" {
+		return nil, errs.ChallengeNotFound
+	}
+	if code == "" {
+		return nil, &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "code", Description: "must not be empty"}}}
+	}
+
+	userID, err := u.twoFactorService.VerifyChallenge(ctx, challengeToken, code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, found, err := u.usersService.Get(ctx, &model.GetPars{
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errs.ChallengeNotFound
+	}
+
+	return u.startSession(ctx, user, userAgent)
+}
+
+// EnrollTwoFactor generates a new TOTP secret of the user, returning the provisioning URI
+// and its QR code for the authenticator app. The secret is enabled once confirmed with ConfirmTwoFactor.
+func (u *Usecase) EnrollTwoFactor(ctx context.Context, userID string) (*twoFactorModel.Enrollment, error) {
+	user, found, err := u.usersService.Get(ctx, &model.GetPars{
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errs.UserNotFound
+	}
+
+	return u.twoFactorService.Enroll(ctx, user.UserID, user.Username)
+}
+
+// ConfirmTwoFactor enables two-factor authentication of the user with a code generated
+// from the enrolled secret and returns the one-time recovery codes.
+func (u *Usecase) ConfirmTwoFactor(ctx context.Context, userID string, code string) ([]string, error) {
+	if code == "" {
+		return nil, &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "code", Description: "must not be empty"}}}
+	}
+
+	return u.twoFactorService.Confirm(ctx, userID, code)
+}
+
+// DisableTwoFactor turns off two-factor authentication of the user with a TOTP or recovery code.
+func (u *Usecase) DisableTwoFactor(ctx context.Context, userID string, code string) error {
+	if code == "" {
+		return &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "code", Description: "must not be empty"}}}
+	}
+
+	return u.twoFactorService.Disable(ctx, userID, code)
+}
+
+// ChangePassword replaces the password of the user after re-verifying the current one with the proof.
+// The new password is set as the SRP salt and verifier computed by the client, accounts registered
+// with a password may set a new password instead, which has to meet the password policy.
+// All sessions of the user are revoked and a new one is started, its token pair is returned
+// along with the server proof of SRP accounts.
+func (u *Usecase) ChangePassword(ctx context.Context, userID string, proof *model.PasswordProof, salt []byte, verifier []byte,
+	newPassword string, userAgent string) (*model.LoginResult, error) {
+	user, serverProof, err := u.verifyPassword(ctx, userID, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	edit := &model.Edit{
+		UpdatedAt: &now,
+	}
+
+	switch {
+	case len(verifier) > 0 || user.UsesSrp():
+		if err = u.usersService.ValidateSrpVerifier(salt, verifier); err != nil {
+			return nil, err
+		}
+
+		noPasswordHash := ""
+		edit.PasswordHash = &noPasswordHash
+		edit.SrpSalt = &salt
+		edit.SrpVerifier = &verifier
+	default:
+		if newPassword == "" {
+			return nil, &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "new_password", Description: "must not be empty"}}}
+		}
+		if err = u.usersService.ValidatePassword(user.Username, newPassword); err != nil {
+			return nil, err
+		}
+
+		var passwordHash string
+		passwordHash, err = u.usersService.HashPassword(newPassword)
+		if err != nil {
+			return nil, err
+		}
+		edit.PasswordHash = &passwordHash
+	}
+
+	err = u.usersService.Update(ctx, &model.GetPars{
+		UserID: user.UserID,
+	}, edit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.sessionsService.RevokeAll(ctx, user.UserID, ""); err != nil {
+		return nil, err
+	}
+
+	result, err := u.startSession(ctx, user, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	result.ServerProof = serverProof
+
+	return result, nil
+}
+
+// ChangeUsername renames the user, returning errs.UsernameAlreadyExists if the username is taken.
+// The SRP verifier does not depend on the username, so the password stays the same.
+func (u *Usecase) ChangeUsername(ctx context.Context, userID string, username string) error {
+	if username == "" {
+		return &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "username", Description: "must not be empty"}}}
+	}
+
+	user, found, err := u.usersService.Get(ctx, &model.GetPars{
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errs.UserNotFound
+	}
+	if user.Username == username {
+		return nil
+	}
+
+	taken, err := u.usersService.IsLoginTaken(ctx, username)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errs.UsernameAlreadyExists
+	}
+
+	now := time.Now()
+
+	return u.usersService.Update(ctx, &model.GetPars{
+		UserID: user.UserID,
+	}, &model.Edit{
+		Username:  &username,
+		UpdatedAt: &now,
+	})
+}
+
+// DeleteAccount deletes the user after re-verifying the password with the proof. The files
+// of the binary items are removed from the blob storage first, the items, sessions and the other
+// records of the user are removed by the database along with the account.
+func (u *Usecase) DeleteAccount(ctx context.Context, userID string, proof *model.PasswordProof) error {
+	user, _, err := u.verifyPassword(ctx, userID, proof)
+	if err != nil {
+		return err
+	}
+
+	if err = u.dataItemsService.DeleteUserFiles(ctx, user.UserID); err != nil {
+		return err
+	}
+
+	return u.usersService.Delete(ctx, &model.GetPars{
+		UserID: user.UserID,
+	})
+}
+
+// verifyPassword re-verifies the password of the logged in user with the proof, returning the user
+// and, for SRP accounts, the server proof. Wrong passwords count as failed logins and are reported
+// as errs.InvalidCredentials, as are SRP proofs of another user.
+func (u *Usecase) verifyPassword(ctx context.Context, userID string, proof *model.PasswordProof) (*model.User, []byte, error) {
+	if proof == nil {
+		return nil, nil, &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "proof", Description: "must be set"}}}
+	}
+
+	user, found, err := u.usersService.Get(ctx, &model.GetPars{
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !found {
+		return nil, nil, errs.UserNotFound
+	}
+
+	if user.UsesSrp() {
+		if proof.LoginID == "" || len(proof.ClientProof) == 0 {
+			return nil, nil, &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "proof.client_proof", Description: "must not be empty"}}}
+		}
+
+		provedUser, serverProof, err := u.usersService.FinishSrpLogin(ctx, proof.LoginID, proof.ClientProof)
+		if err != nil {
+			return nil, nil, err
+		}
+		if provedUser.UserID != user.UserID {
+			return nil, nil, errs.InvalidCredentials
+		}
+
+		return provedUser, serverProof, nil
+	}
+
+	if proof.Password == "" {
+		return nil, nil, &errs.ValidationError{Violations: []errs.FieldViolation{{Field: "proof.password", Description: "must not be empty"}}}
+	}
+	if err = u.usersService.CheckLocked(user); err != nil {
+		return nil, nil, err
+	}
+	if !u.usersService.IsValidPassword(user.PasswordHash, proof.Password) {
+		if err = u.usersService.RecordFailedLogin(ctx, user); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, errs.InvalidCredentials
+	}
+
+	return user, nil, nil
+}
+
+// completeLogin finishes the first step of a login with valid credentials, forgetting the failed
+// logins of the user. Users with two-factor authentication get a login challenge, a session
+// is started for the others.
+func (u *Usecase) completeLogin(ctx context.Context, user *model.User, userAgent string) (*model.LoginResult, error) {
+	if err := u.usersService.ResetFailedLogins(ctx, user); err != nil {
+		return nil, err
+	}
+
+	enabled, err := u.twoFactorService.IsEnabled(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return u.startSession(ctx, user, userAgent)
+	}
+
+	token, expiresAt, err := u.twoFactorService.CreateChallenge(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResult{
+		ChallengeToken:     token,
+		ChallengeExpiresAt: expiresAt,
+	}, nil
+}
+
+// startSession starts a new session of the logged in user with a token pair. Along with the tokens
+// it returns the user's key check and kdf salt, generating one for accounts created before it existed.
+func (u *Usecase) startSession(ctx context.Context, user *model.User, userAgent string) (*model.LoginResult, error) {
+	if len(user.KdfSalt) == 0 {
+		kdfSalt, err := u.usersService.NewKdfSalt()
+		if err != nil {
+			return nil, err
+		}
+
+		err = u.usersService.Update(ctx, &model.GetPars{
+			UserID: user.UserID,
+		}, &model.Edit{
+			KdfSalt: &kdfSalt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		user.KdfSalt = kdfSalt
+	}
+
+	session, refreshToken, err := u.sessionsService.Create(ctx, user.UserID, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := u.issueTokens(user.UserID, session.ID, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResult{
+		Tokens:   *tokens,
+		KdfSalt:  user.KdfSalt,
+		KeyCheck: user.KeyCheck,
+	}, nil
+}
+
+// RefreshToken exchanges the refresh token for a new token pair of the same session.
+func (u *Usecase) RefreshToken(ctx context.Context, refreshToken string) (*model.Tokens, error) {
+	if refreshToken == "" {
+		return nil, errs.InvalidInput
+	}
+
+	session, newRefreshToken, err := u.sessionsService.Rotate(ctx, refreshToken)
+	if err != nil {
+		return nil, err
 	}
 
-	token, err := u.authService.CreateToken(user)
+	return u.issueTokens(session.UserID, session.ID, newRefreshToken)
+}
+
+// Authenticate validates the credentials of the incoming request and returns
+// the authenticated principal. Tokens of revoked or expired sessions are rejected.
+func (u *Usecase) Authenticate(ctx context.Context) (*authModel.Principal, error) {
+	principal, err := u.authService.Authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := u.sessionsService.IsActive(ctx, principal.SessionID)
 	if err != nil {
 		return nil, err
 	}
+	if !active {
+		return nil, errs.InvalidToken
+	}
 
-	return &token, nil
+	return principal, nil
+}
+
+// Logout revokes the session the principal is authenticated with.
+func (u *Usecase) Logout(ctx context.Context, principal *authModel.Principal) error {
+	_, err := u.sessionsService.Revoke(ctx, &sessionsModel.GetPars{
+		ID:     principal.SessionID,
+		UserID: principal.UserID,
+	})
+	return err
 }
 
-// GetUserIDFromContext extracts the user ID from the context,
-// using the authentication service.
-func (u *Usecase) GetUserIDFromContext(ctx context.Context) (string, error) {
-	return u.authService.GetUserIDFromContext(ctx)
+// ListSessions retrieves the active sessions of the user.
+func (u *Usecase) ListSessions(ctx context.Context, userID string) ([]*sessionsModel.Session, error) {
+	return u.sessionsService.List(ctx, userID)
+}
+
+// RevokeSession revokes the session of the user with the given ID.
+// It returns errs.SessionNotFound if the user has no such session.
+func (u *Usecase) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	if sessionID == "" {
+		return errs.InvalidInput
+	}
+
+	found, err := u.sessionsService.Revoke(ctx, &sessionsModel.GetPars{
+		ID:     sessionID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errs.SessionNotFound
+	}
+
+	return nil
+}
+
+// validateCredentials checks that both the username and the password are set.
+func validateCredentials(username string, password string) error {
+	var violations []errs.FieldViolation
+
+	if username == "" {
+		violations = append(violations, errs.FieldViolation{Field: "username", Description: "must not be empty"})
+	}
+	if password == "" {
+		violations = append(violations, errs.FieldViolation{Field: "password", Description: "must not be empty"})
+	}
+
+	if len(violations) > 0 {
+		return &errs.ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// issueTokens creates the access token of the session and pairs it with the refresh token.
+func (u *Usecase) issueTokens(userID string, sessionID string, refreshToken string) (*model.Tokens, error) {
+	token, expiresAt, err := u.authService.CreateToken(&model.User{UserID: userID}, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Tokens{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
 }
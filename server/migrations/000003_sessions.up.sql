@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS sessions (
+                          id TEXT NOT NULL PRIMARY KEY,
+                          user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                          refresh_token_hash VARCHAR(64) NOT NULL,
+                          user_agent TEXT NOT NULL DEFAULT '',
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          last_used_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                          revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
@@ -0,0 +1,3 @@
+drop table if exists data_item_versions;
+ALTER TABLE data_items DROP COLUMN IF EXISTS object_key;
+ALTER TABLE data_items DROP COLUMN IF EXISTS version;
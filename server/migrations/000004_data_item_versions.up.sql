@@ -0,0 +1,24 @@
+ALTER TABLE data_items ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE data_items ADD COLUMN IF NOT EXISTS object_key TEXT NOT NULL DEFAULT '';
+
+UPDATE data_items SET object_key = id WHERE type = 'binary' AND object_key = '';
+
+CREATE TABLE IF NOT EXISTS data_item_versions (
+                                    item_id TEXT NOT NULL REFERENCES data_items(id) ON DELETE CASCADE,
+                                    version INT NOT NULL,
+                                    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+                                    type data_type NOT NULL,
+                                    data BYTEA NOT NULL,
+                                    meta TEXT DEFAULT '',
+                                    url VARCHAR(255) NOT NULL DEFAULT '',
+                                    object_key TEXT NOT NULL DEFAULT '',
+                                    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                    PRIMARY KEY (item_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_item_versions_user_id ON data_item_versions(user_id);
+
+INSERT INTO data_item_versions (item_id, version, user_id, type, data, meta, url, object_key, created_at, updated_at)
+SELECT id, version, user_id, type, data, meta, url, object_key, created_at, updated_at FROM data_items
+ON CONFLICT DO NOTHING;
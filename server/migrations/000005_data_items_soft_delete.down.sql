@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_data_items_deleted_at;
+ALTER TABLE data_items DROP COLUMN IF EXISTS deleted_at;
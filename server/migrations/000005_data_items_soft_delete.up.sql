@@ -0,0 +1,3 @@
+ALTER TABLE data_items ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_data_items_deleted_at ON data_items(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_data_items_user_id_id;
+DROP INDEX IF EXISTS idx_data_items_user_id_created_at;
+DROP INDEX IF EXISTS idx_data_items_user_id_updated_at;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_data_items_user_id_updated_at ON data_items(user_id, updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_data_items_user_id_created_at ON data_items(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_data_items_user_id_id ON data_items(user_id, id);
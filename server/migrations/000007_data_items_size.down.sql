@@ -0,0 +1,2 @@
+ALTER TABLE data_item_versions DROP COLUMN IF EXISTS size;
+ALTER TABLE data_items DROP COLUMN IF EXISTS size;
@@ -0,0 +1,5 @@
+ALTER TABLE data_items ADD COLUMN IF NOT EXISTS size BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE data_item_versions ADD COLUMN IF NOT EXISTS size BIGINT NOT NULL DEFAULT 0;
+
+UPDATE data_items SET size = octet_length(data);
+UPDATE data_item_versions SET size = octet_length(data);
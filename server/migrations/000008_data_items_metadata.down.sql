@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS data_item_labels;
+DROP TABLE IF EXISTS data_item_tags;
+
+DROP INDEX IF EXISTS idx_data_items_name_search;
+DROP INDEX IF EXISTS idx_data_items_folder_id;
+
+ALTER TABLE data_item_versions DROP COLUMN IF EXISTS folder_id;
+ALTER TABLE data_item_versions DROP COLUMN IF EXISTS name;
+ALTER TABLE data_items DROP COLUMN IF EXISTS folder_id;
+ALTER TABLE data_items DROP COLUMN IF EXISTS name;
+
+DROP TABLE IF EXISTS folders;
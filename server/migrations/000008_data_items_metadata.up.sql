@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS folders (
+                         id TEXT NOT NULL PRIMARY KEY,
+                         user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                         parent_id TEXT REFERENCES folders(id) ON DELETE CASCADE,
+                         name TEXT NOT NULL,
+                         created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_folders_user_id_parent_id_name ON folders(user_id, COALESCE(parent_id, ''), name);
+CREATE INDEX IF NOT EXISTS idx_folders_parent_id ON folders(parent_id);
+
+ALTER TABLE data_items ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '';
+ALTER TABLE data_items ADD COLUMN IF NOT EXISTS folder_id TEXT REFERENCES folders(id) ON DELETE SET NULL;
+ALTER TABLE data_item_versions ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '';
+ALTER TABLE data_item_versions ADD COLUMN IF NOT EXISTS folder_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_data_items_folder_id ON data_items(folder_id);
+CREATE INDEX IF NOT EXISTS idx_data_items_name_search ON data_items USING GIN (to_tsvector('simple', name));
+
+CREATE TABLE IF NOT EXISTS data_item_tags (
+                                item_id TEXT NOT NULL REFERENCES data_items(id) ON DELETE CASCADE,
+                                tag TEXT NOT NULL,
+                                PRIMARY KEY (item_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_item_tags_tag ON data_item_tags(tag);
+
+CREATE TABLE IF NOT EXISTS data_item_labels (
+                                  item_id TEXT NOT NULL REFERENCES data_items(id) ON DELETE CASCADE,
+                                  key TEXT NOT NULL,
+                                  value TEXT NOT NULL,
+                                  PRIMARY KEY (item_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_item_labels_key_value ON data_item_labels(key, value);
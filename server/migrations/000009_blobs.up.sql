@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS blobs (
+    object_key TEXT PRIMARY KEY,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
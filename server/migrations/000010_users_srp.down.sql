@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS srp_handshakes;
+
+-- accounts migrated to SRP can not log in with a password anymore, but their data is kept
+UPDATE users SET password_hash = '' WHERE password_hash IS NULL;
+ALTER TABLE users ALTER COLUMN password_hash SET NOT NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS srp_verifier;
+ALTER TABLE users DROP COLUMN IF EXISTS srp_salt;
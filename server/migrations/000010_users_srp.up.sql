@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS srp_salt BYTEA;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS srp_verifier BYTEA;
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS srp_handshakes (
+                          id TEXT NOT NULL PRIMARY KEY,
+                          user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                          server_secret BYTEA NOT NULL,
+                          client_public BYTEA NOT NULL,
+                          expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_srp_handshakes_expires_at ON srp_handshakes(expires_at);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS user_totp (
+                          user_id INT NOT NULL PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                          secret BYTEA NOT NULL,
+                          enabled BOOLEAN NOT NULL DEFAULT FALSE,
+                          last_step BIGINT NOT NULL DEFAULT 0,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          confirmed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+                          user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                          code_hash VARCHAR(64) NOT NULL,
+                          used_at TIMESTAMP WITH TIME ZONE,
+                          PRIMARY KEY (user_id, code_hash)
+);
+
+CREATE TABLE IF NOT EXISTS login_challenges (
+                          id VARCHAR(64) NOT NULL PRIMARY KEY,
+                          user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                          attempts INT NOT NULL DEFAULT 0,
+                          expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_challenges_expires_at ON login_challenges(expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE data_item_versions DROP COLUMN IF EXISTS labels;
+ALTER TABLE data_item_versions DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,8 @@
+ALTER TABLE data_item_versions ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE data_item_versions ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';
+
+UPDATE data_item_versions v
+SET tags   = ARRAY(SELECT tag FROM data_item_tags WHERE item_id = v.item_id ORDER BY tag),
+    labels = COALESCE((SELECT jsonb_object_agg(key, value) FROM data_item_labels WHERE item_id = v.item_id), '{}'::jsonb)
+FROM data_items i
+WHERE i.id = v.item_id AND i.version = v.version;
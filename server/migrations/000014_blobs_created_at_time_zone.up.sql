@@ -0,0 +1 @@
+ALTER TABLE blobs ALTER COLUMN created_at TYPE TIMESTAMP WITH TIME ZONE;
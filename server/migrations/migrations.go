@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations of the database schema, so the server binary
+// can apply them without the migration files being deployed next to it.
+package migrations
+
+import "embed"
+
+// FS holds the up and down migrations named as golang-migrate expects them.
+//
+//go:embed *.sql
+var FS embed.FS